@@ -21,6 +21,9 @@ K8S_API_DIR = $(shell go list -f '{{ .Dir }}' -m k8s.io/api)
 # set the gogo protobuf package dir
 GOGO_PROTOBUF_DIR = $(shell go list -f '{{ .Dir }}' -m github.com/gogo/protobuf)
 
+# set the driver protobuf package dir
+DRIVER_PB_DIR = $(ROOT_DIR)/pkg/lbcfcontroller/webhooks/driverpb
+
 .PHONY: gen.run
 gen.run: gen.clean gen.generator gen.api
 
@@ -54,3 +57,23 @@ gen.clean:
 	@find . -type f -name 'zz_generated*.go' -delete
 	@find . -type f -name 'types_swagger_doc_generated.go' -delete
 
+# ==============================================================================
+# Protobuf
+
+# gen.proto is not part of gen.run because it requires protoc to be installed,
+# run `make gen.proto` explicitly after driver.proto is modified.
+# protoc-gen-go is built from github.com/golang/protobuf pinned in go.mod (v1.2.0),
+# gRPC stubs are generated into driver.pb.go by its grpc plugin
+.PHONY: gen.proto.tools
+gen.proto.tools:
+	@command -v protoc >/dev/null 2>&1 || { echo "protoc is required, see https://github.com/protocolbuffers/protobuf/releases"; exit 1; }
+	@echo "===========> Building protoc-gen-go $(shell go list -f '{{ .Version }}' -m github.com/golang/protobuf)"
+	@GO111MODULE=on $(GO) build -o $(TOOLS_DIR)/protoc-gen-go github.com/golang/protobuf/protoc-gen-go
+
+.PHONY: gen.proto
+gen.proto: gen.proto.tools
+	@echo "===========> Generating driver protobuf and gRPC code"
+	@protoc --plugin=protoc-gen-go=$(TOOLS_DIR)/protoc-gen-go \
+		--go_out=plugins=grpc:$(DRIVER_PB_DIR) \
+		-I $(DRIVER_PB_DIR) \
+		$(DRIVER_PB_DIR)/driver.proto
@@ -0,0 +1,63 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package config
+
+import (
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+type Config struct {
+	DriverURL string
+	Timeout   time.Duration
+
+	LBSpec            map[string]string
+	LBAttributes      map[string]string
+	BackendParameters map[string]string
+	PodIP             string
+	Port              int32
+	Protocol          string
+
+	MaxCalls      int
+	RetryInterval time.Duration
+	DrainBackend  bool
+
+	BearerToken string
+	HMACKey     string
+}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func (o *Config) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.DriverURL, "driver-url", "", "url of the driver to be tested, webhooks are called at {driver-url}/{webhook name}")
+	fs.DurationVar(&o.Timeout, "timeout", 10*time.Second, "timeout of each webhook call")
+	fs.StringToStringVar(&o.LBSpec, "lb-spec", nil, "lbSpec used to create the load balancer, e.g. --lb-spec=vpcID=vpc-1,lbID=lb-1")
+	fs.StringToStringVar(&o.LBAttributes, "lb-attributes", nil, "attributes of the load balancer")
+	fs.StringToStringVar(&o.BackendParameters, "backend-parameters", nil, "parameters used to register the backend")
+	fs.StringVar(&o.PodIP, "pod-ip", "127.0.0.1", "IP of the pod registered as backend")
+	fs.Int32Var(&o.Port, "port", 80, "port of the pod registered as backend")
+	fs.StringVar(&o.Protocol, "protocol", "TCP", "protocol of the port")
+	fs.IntVar(&o.MaxCalls, "max-calls", 10, "maximum number of calls of a webhook until it returns a status other than Running")
+	fs.DurationVar(&o.RetryInterval, "retry-interval", time.Second, "interval between calls of a webhook that returns Running")
+	fs.BoolVar(&o.DrainBackend, "drain-backend", false, "test drainBackend, enable it if drainBackend is configured in the LoadBalancerDriver")
+	fs.StringVar(&o.BearerToken, "bearer-token", "", "bearer token sent to the driver, required if the driver is configured with BearerToken auth")
+	fs.StringVar(&o.HMACKey, "hmac-key", "", "key used to sign requests, required if the driver is configured with HMAC auth")
+}
@@ -0,0 +1,89 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package app
+
+import (
+	goflag "flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"tkestack.io/lb-controlling-framework/cmd/lbcf-conformance/app/config"
+	"tkestack.io/lb-controlling-framework/pkg/conformance"
+	"tkestack.io/lb-controlling-framework/pkg/version"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog"
+)
+
+func NewServer() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use: "lbcf-conformance",
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+	rootCmd.AddCommand(newCmdRun())
+	rootCmd.AddCommand(version.NewCmdVersion())
+
+	fs := goflag.NewFlagSet(os.Args[0], goflag.ExitOnError)
+	klog.InitFlags(fs)
+	rootCmd.PersistentFlags().AddGoFlagSet(fs)
+
+	return rootCmd
+}
+
+func newCmdRun() *cobra.Command {
+	cfg := config.NewConfig()
+
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "run conformance scenarios against a driver",
+		Run: func(cmd *cobra.Command, args []string) {
+			if cfg.DriverURL == "" {
+				fmt.Fprintln(os.Stderr, "--driver-url is required")
+				os.Exit(2)
+			}
+			if cfg.BearerToken != "" && cfg.HMACKey != "" {
+				fmt.Fprintln(os.Stderr, "at most one of --bearer-token and --hmac-key can be set")
+				os.Exit(2)
+			}
+			report := conformance.Run(conformance.Config{
+				DriverURL:         cfg.DriverURL,
+				LBSpec:            cfg.LBSpec,
+				LBAttributes:      cfg.LBAttributes,
+				BackendParameters: cfg.BackendParameters,
+				PodIP:             cfg.PodIP,
+				Port:              cfg.Port,
+				Protocol:          cfg.Protocol,
+				MaxCalls:          cfg.MaxCalls,
+				RetryInterval:     cfg.RetryInterval,
+				DrainBackend:      cfg.DrainBackend,
+				HTTPClient:        &http.Client{Timeout: cfg.Timeout},
+				BearerToken:       cfg.BearerToken,
+				HMACKey:           []byte(cfg.HMACKey),
+			})
+			report.Print(os.Stdout)
+			if !report.Passed() {
+				os.Exit(1)
+			}
+		},
+	}
+	cfg.AddFlags(cmd.LocalFlags())
+	return cmd
+}
@@ -31,6 +31,26 @@ type Config struct {
 	KubeConfig           string
 	ServerCrt            string
 	ServerKey            string
+
+	LeaderElect          bool
+	LeaderElectNamespace string
+	LeaseDuration        time.Duration
+	RenewDeadline        time.Duration
+	RetryPeriod          time.Duration
+
+	ShutdownTimeout time.Duration
+
+	DriverWorkers               int
+	LoadBalancerWorkers         int
+	BackendGroupWorkers         int
+	BackendRecordWorkers        int
+	MaxConcurrentCallsPerDriver int
+
+	CircuitBreakerFailureThreshold int
+	CircuitBreakerOpenDuration     time.Duration
+
+	MaxExecOutputBytes int
+	ExecDriverDir      string
 }
 
 func NewConfig() *Config {
@@ -45,4 +65,19 @@ func (o *Config) AddFlags(fs *flag.FlagSet) {
 	fs.StringVar(&o.KubeConfig, "kubeconfig", "", "Path to kubeconfig file with authorization information")
 	fs.StringVar(&o.ServerCrt, "server-crt", "/etc/lbcf/server.crt", "Path to crt file for admit webhook server")
 	fs.StringVar(&o.ServerKey, "server-key", "/etc/lbcf/server.key", "Path to key file for admit webhook server")
+	fs.BoolVar(&o.LeaderElect, "leader-elect", true, "run controller workers only in the elected leader, admission webhooks are served by all replicas")
+	fs.StringVar(&o.LeaderElectNamespace, "leader-elect-namespace", "kube-system", "namespace of the configmap used as leader election lock")
+	fs.DurationVar(&o.LeaseDuration, "leader-elect-lease-duration", 15*time.Second, "duration that non-leader candidates will wait before trying to acquire leadership")
+	fs.DurationVar(&o.RenewDeadline, "leader-elect-renew-deadline", 10*time.Second, "duration that the leader retries refreshing leadership before giving up")
+	fs.DurationVar(&o.RetryPeriod, "leader-elect-retry-period", 2*time.Second, "duration between attempts of acquiring or renewing leadership")
+	fs.DurationVar(&o.ShutdownTimeout, "shutdown-timeout", 30*time.Second, "maximum duration to wait for in-flight webhook calls and admission requests when shutting down")
+	fs.IntVar(&o.DriverWorkers, "driver-workers", 2, "number of workers that process LoadBalancerDrivers concurrently")
+	fs.IntVar(&o.LoadBalancerWorkers, "loadbalancer-workers", 10, "number of workers that process LoadBalancers concurrently")
+	fs.IntVar(&o.BackendGroupWorkers, "backendgroup-workers", 10, "number of workers that process BackendGroups concurrently")
+	fs.IntVar(&o.BackendRecordWorkers, "backendrecord-workers", 20, "number of workers that process BackendRecords concurrently")
+	fs.IntVar(&o.MaxConcurrentCallsPerDriver, "max-concurrent-calls-per-driver", 0, "maximum number of in-flight webhook calls to each driver, 0 means no limit")
+	fs.IntVar(&o.CircuitBreakerFailureThreshold, "circuit-breaker-failure-threshold", 5, "number of consecutive transport errors or 5xx responses after which webhook calls to a driver are short-circuited, 0 disables the circuit breaker")
+	fs.DurationVar(&o.CircuitBreakerOpenDuration, "circuit-breaker-open-duration", 30*time.Second, "duration that the circuit breaker of a driver stays open before a trial webhook call is allowed")
+	fs.IntVar(&o.MaxExecOutputBytes, "max-exec-output-bytes", 1<<20, "maximum size of stdout and stderr of Exec drivers for each webhook call")
+	fs.StringVar(&o.ExecDriverDir, "exec-driver-dir", "", "directory that executables of Exec drivers must be in after resolving symlinks, Exec drivers are disabled if it is empty")
 }
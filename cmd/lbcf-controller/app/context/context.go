@@ -24,14 +24,16 @@ import (
 	lbcfclientset "tkestack.io/lb-controlling-framework/pkg/client-go/clientset/versioned"
 	"tkestack.io/lb-controlling-framework/pkg/client-go/informers/externalversions"
 	"tkestack.io/lb-controlling-framework/pkg/client-go/informers/externalversions/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/util"
 
 	apicorev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	corelister "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/record"
@@ -53,11 +55,15 @@ func NewContext(cfg *config.Config) *Context {
 	c.PodInformer = c.K8sFactory.Core().V1().Pods()
 	c.SvcInformer = c.K8sFactory.Core().V1().Services()
 	c.NodeInformer = c.K8sFactory.Core().V1().Nodes()
+	c.EndpointsInformer = c.K8sFactory.Core().V1().Endpoints()
 	c.LBInformer = c.LbcfFactory.Lbcf().V1beta1().LoadBalancers()
 	c.LBDriverInformer = c.LbcfFactory.Lbcf().V1beta1().LoadBalancerDrivers()
 	c.BGInformer = c.LbcfFactory.Lbcf().V1beta1().BackendGroups()
 	c.BRInformer = c.LbcfFactory.Lbcf().V1beta1().BackendRecords()
 
+	// Secrets are got on demand, there may be a large number of Secrets in the cluster that are not used by drivers
+	c.SecretLister = util.NewSecretLister(c.K8sClient, util.DefaultSecretCacheTTL)
+
 	c.EventBroadCaster = record.NewBroadcaster()
 	scheme := runtime.NewScheme()
 	if err := lbcfv1beta.SchemeBuilder.AddToScheme(scheme); err != nil {
@@ -78,27 +84,51 @@ type Context struct {
 	K8sFactory  informers.SharedInformerFactory
 	LbcfFactory externalversions.SharedInformerFactory
 
-	PodInformer      v1.PodInformer
-	SvcInformer      v1.ServiceInformer
-	NodeInformer     v1.NodeInformer
-	LBInformer       v1beta1.LoadBalancerInformer
-	LBDriverInformer v1beta1.LoadBalancerDriverInformer
-	BGInformer       v1beta1.BackendGroupInformer
-	BRInformer       v1beta1.BackendRecordInformer
+	PodInformer       v1.PodInformer
+	SvcInformer       v1.ServiceInformer
+	NodeInformer      v1.NodeInformer
+	EndpointsInformer v1.EndpointsInformer
+	LBInformer        v1beta1.LoadBalancerInformer
+	LBDriverInformer  v1beta1.LoadBalancerDriverInformer
+	BGInformer        v1beta1.BackendGroupInformer
+	BRInformer        v1beta1.BackendRecordInformer
+
+	SecretLister corelister.SecretLister
 
 	EventBroadCaster record.EventBroadcaster
 	EventRecorder    record.EventRecorder
+
+	eventWatcher watch.Interface
+}
+
+func (c *Context) Start(stopCh <-chan struct{}) {
+	c.K8sFactory.Start(stopCh)
+	c.LbcfFactory.Start(stopCh)
+	c.eventWatcher = c.EventBroadCaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: c.K8sClient.CoreV1().Events("")})
 }
 
-func (c *Context) Start() {
-	c.K8sFactory.Start(wait.NeverStop)
-	c.LbcfFactory.Start(wait.NeverStop)
-	c.EventBroadCaster.StartRecordingToSink(&corev1.EventSinkImpl{Interface: c.K8sClient.CoreV1().Events("")})
+// StopEventRecording stops sending events to the apiserver
+func (c *Context) StopEventRecording() {
+	if c.eventWatcher != nil {
+		c.eventWatcher.Stop()
+	}
 }
 
-func (c *Context) WaitForCacheSync() {
-	c.K8sFactory.WaitForCacheSync(wait.NeverStop)
-	c.LbcfFactory.WaitForCacheSync(wait.NeverStop)
+// WaitForCacheSync blocks until all started informers' caches were synced, it returns false if stopCh is closed before that
+func (c *Context) WaitForCacheSync(stopCh <-chan struct{}) bool {
+	for informerType, synced := range c.K8sFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			klog.Errorf("cache of %v not synced", informerType)
+			return false
+		}
+	}
+	for informerType, synced := range c.LbcfFactory.WaitForCacheSync(stopCh) {
+		if !synced {
+			klog.Errorf("cache of %v not synced", informerType)
+			return false
+		}
+	}
+	return true
 }
 
 func getClientConfigOrDie(kubeConfig string) *rest.Config {
@@ -0,0 +1,95 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package app
+
+import (
+	gocontext "context"
+	"os"
+
+	"tkestack.io/lb-controlling-framework/cmd/lbcf-controller/app/context"
+
+	apicorev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+	"k8s.io/klog"
+)
+
+const (
+	leaderElectionLockName = "lbcf-controller"
+)
+
+// runWithLeaderElection blocks until leadership is acquired, and then calls run.
+// The process exits once leadership is lost, so that another replica can take over with a clean state.
+// Closing stopCh stops the election and makes runWithLeaderElection return.
+func runWithLeaderElection(ctx *context.Context, stopCh <-chan struct{}, run func()) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		klog.Fatalf("get hostname failed: %v", err)
+	}
+	id := hostname + "_" + string(uuid.NewUUID())
+
+	lock, err := resourcelock.New(
+		resourcelock.ConfigMapsResourceLock,
+		ctx.Cfg.LeaderElectNamespace,
+		leaderElectionLockName,
+		ctx.K8sClient.CoreV1(),
+		resourcelock.ResourceLockConfig{
+			Identity: id,
+			EventRecorder: ctx.EventBroadCaster.NewRecorder(scheme.Scheme, apicorev1.EventSource{
+				Component: "lbcf-controller",
+			}),
+		})
+	if err != nil {
+		klog.Fatalf("create leader election lock failed: %v", err)
+	}
+
+	electionCtx, cancel := gocontext.WithCancel(gocontext.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	leaderelection.RunOrDie(electionCtx, leaderelection.LeaderElectionConfig{
+		Lock:          lock,
+		LeaseDuration: ctx.Cfg.LeaseDuration,
+		RenewDeadline: ctx.Cfg.RenewDeadline,
+		RetryPeriod:   ctx.Cfg.RetryPeriod,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(gocontext.Context) {
+				klog.Infof("%s became leader", id)
+				run()
+			},
+			OnStoppedLeading: func() {
+				select {
+				case <-stopCh:
+					klog.Infof("%s stopped leading because of shutting down", id)
+				default:
+					klog.Fatalf("%s lost leadership", id)
+				}
+			},
+			OnNewLeader: func(identity string) {
+				if identity != id {
+					klog.Infof("current leader is %s", identity)
+				}
+			},
+		},
+	})
+}
@@ -23,15 +23,18 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"tkestack.io/lb-controlling-framework/cmd/lbcf-controller/app/config"
 	"tkestack.io/lb-controlling-framework/cmd/lbcf-controller/app/context"
 	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller"
 	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/admission"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/metrics"
 	"tkestack.io/lb-controlling-framework/pkg/version"
 
 	"github.com/spf13/cobra"
-	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 func NewServer() *cobra.Command {
@@ -51,6 +54,21 @@ func NewServer() *cobra.Command {
 	return rootCmd
 }
 
+// setupSignalHandler returns a channel that is closed on SIGTERM or SIGINT.
+// The process exits immediately if a second signal is caught.
+func setupSignalHandler() <-chan struct{} {
+	stopCh := make(chan struct{})
+	sigCh := make(chan os.Signal, 2)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+	go func() {
+		<-sigCh
+		close(stopCh)
+		<-sigCh
+		os.Exit(1)
+	}()
+	return stopCh
+}
+
 func newCmdStart() *cobra.Command {
 	cfg := config.NewConfig()
 
@@ -61,17 +79,36 @@ func newCmdStart() *cobra.Command {
 			admissionWebhookServer := admission.NewWebhookServer(ctx, cfg.ServerCrt, cfg.ServerKey)
 			lbcf := lbcfcontroller.NewController(ctx)
 
-			ctx.Start()
-			admissionWebhookServer.Start()
-			lbcf.Start()
+			stopCh := setupSignalHandler()
+			ctx.Start(stopCh)
+			admissionWebhookServer.Start(stopCh)
 
 			mux := http.NewServeMux()
 			mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 				w.Write([]byte("ok"))
 			})
+			mux.Handle("/metrics", metrics.Handler())
 			go http.ListenAndServe(":11029", mux)
 
-			<-wait.NeverStop
+			if cfg.LeaderElect {
+				go runWithLeaderElection(ctx, stopCh, func() {
+					lbcf.Start(stopCh)
+				})
+			} else {
+				lbcf.Start(stopCh)
+			}
+
+			<-stopCh
+			klog.Infof("shutting down, waiting at most %s", cfg.ShutdownTimeout.String())
+			deadline := time.Now().Add(cfg.ShutdownTimeout)
+			if !lbcf.ShutDown(cfg.ShutdownTimeout) {
+				klog.Warningf("timeout waiting for in-flight items")
+			}
+			if err := admissionWebhookServer.ShutDown(time.Until(deadline)); err != nil {
+				klog.Warningf("shutdown admission webhook server failed: %v", err)
+			}
+			ctx.StopEventRecording()
+			klog.Flush()
 		},
 	}
 	cfg.AddFlags(cmd.LocalFlags())
@@ -0,0 +1,51 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package config
+
+import (
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+type Config struct {
+	ListenAddr string
+	ServerCrt  string
+	ServerKey  string
+
+	Latency            time.Duration
+	RunningPhases      int
+	DrainRunningPhases int
+	FailRate           float64
+	FailWebhooks       []string
+}
+
+func NewConfig() *Config {
+	return &Config{}
+}
+
+func (o *Config) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&o.ListenAddr, "listen-addr", ":11030", "address the fake driver listens on")
+	fs.StringVar(&o.ServerCrt, "server-crt", "", "Path to crt file, HTTPS is served if both --server-crt and --server-key are set")
+	fs.StringVar(&o.ServerKey, "server-key", "", "Path to key file, HTTPS is served if both --server-crt and --server-key are set")
+	fs.DurationVar(&o.Latency, "latency", 0, "latency added before responding to each webhook call")
+	fs.IntVar(&o.RunningPhases, "running-phases", 0, "number of Running responses returned for each operation before it is executed")
+	fs.IntVar(&o.DrainRunningPhases, "drain-running-phases", 0, "number of Running responses returned by drainBackend before the backend is drained")
+	fs.Float64Var(&o.FailRate, "fail-rate", 0, "probability in range [0, 1] that a webhook call fails")
+	fs.StringSliceVar(&o.FailWebhooks, "fail-webhooks", nil, "comma-separated webhook names that failures are injected into, all webhooks if empty")
+}
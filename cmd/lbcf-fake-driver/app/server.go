@@ -0,0 +1,80 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package app
+
+import (
+	goflag "flag"
+	"net/http"
+	"os"
+
+	"tkestack.io/lb-controlling-framework/cmd/lbcf-fake-driver/app/config"
+	"tkestack.io/lb-controlling-framework/pkg/fakedriver"
+	"tkestack.io/lb-controlling-framework/pkg/version"
+
+	"github.com/spf13/cobra"
+	"k8s.io/klog"
+)
+
+func NewServer() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use: "lbcf-fake-driver",
+		Run: func(cmd *cobra.Command, args []string) {
+			_ = cmd.Help()
+		},
+	}
+	rootCmd.AddCommand(newCmdStart())
+	rootCmd.AddCommand(version.NewCmdVersion())
+
+	fs := goflag.NewFlagSet(os.Args[0], goflag.ExitOnError)
+	klog.InitFlags(fs)
+	rootCmd.PersistentFlags().AddGoFlagSet(fs)
+
+	return rootCmd
+}
+
+func newCmdStart() *cobra.Command {
+	cfg := config.NewConfig()
+
+	cmd := &cobra.Command{
+		Use: "run",
+		Run: func(cmd *cobra.Command, args []string) {
+			driver := fakedriver.NewDriver(fakedriver.Behavior{
+				Latency:            cfg.Latency,
+				RunningPhases:      cfg.RunningPhases,
+				DrainRunningPhases: cfg.DrainRunningPhases,
+				FailRate:           cfg.FailRate,
+				FailWebhooks:       cfg.FailWebhooks,
+			})
+			server := &http.Server{
+				Addr:    cfg.ListenAddr,
+				Handler: driver.Handler(),
+			}
+
+			var err error
+			klog.Infof("fake driver listening on %s", cfg.ListenAddr)
+			if cfg.ServerCrt != "" && cfg.ServerKey != "" {
+				err = server.ListenAndServeTLS(cfg.ServerCrt, cfg.ServerKey)
+			} else {
+				err = server.ListenAndServe()
+			}
+			klog.Fatalf("fake driver stopped: %v", err)
+		},
+	}
+	cfg.AddFlags(cmd.LocalFlags())
+	return cmd
+}
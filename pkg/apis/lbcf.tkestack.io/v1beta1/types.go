@@ -44,6 +44,9 @@ const (
 	FinalizerDeleteLB               = "lbcf.tkestack.io/delete-load-loadbalancer"
 	FinalizerDeregisterBackend      = "lbcf.tkestack.io/deregister-backend"
 	FinalizerDeregisterBackendGroup = "lbcf.tkestack.io/deregister-backend-group"
+
+	// pod condition used as readiness gate
+	PodConditionBackendsRegistered = "lbcf.tkestack.io/backends-registered"
 )
 
 // +genclient
@@ -131,31 +134,82 @@ type BackendGroupSpec struct {
 	Parameters map[string]string `json:"parameters,omitempty"`
 	// +optional
 	EnsurePolicy *EnsurePolicyConfig `json:"ensurePolicy,omitempty"`
+	// +optional
+	DrainPolicy *DrainPolicyConfig `json:"drainPolicy,omitempty"`
 }
 
 type ServiceBackend struct {
-	Name string       `json:"name"`
+	Name string `json:"name"`
+	// +optional
 	Port PortSelector `json:"port,omitempty"`
 	// +optional
+	Ports []BackendPort `json:"ports,omitempty"`
+	// +optional
 	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// +optional
+	Mode ServiceBackendMode `json:"mode,omitempty"`
 }
 
+// ServiceBackendMode determines which addresses of a service are registered
+type ServiceBackendMode string
+
+const (
+	// ServiceModeNodePort registers the NodePort on selected nodes, it is the default mode
+	ServiceModeNodePort ServiceBackendMode = "NodePort"
+	// ServiceModeEndpoints registers the ready endpoints of the service, i.e. pod IPs and target ports
+	ServiceModeEndpoints ServiceBackendMode = "Endpoints"
+)
+
 type PodBackend struct {
-	Port PortSelector `json:"port"`
+	// +optional
+	Port PortSelector `json:"port,omitempty"`
+	// +optional
+	Ports []BackendPort `json:"ports,omitempty"`
 	// +optional
 	ByLabel *SelectPodByLabel `json:"byLabel,omitempty"`
 	// +optional
 	ByName []string `json:"byName,omitempty"`
+	// +optional
+	ReadinessGate bool `json:"readinessGate,omitempty"`
+	// +optional
+	DeregisterPolicy DeregisterPolicy `json:"deregisterPolicy,omitempty"`
 }
 
+// DeregisterPolicy determines when the registered backend of a pod is deregistered
+type DeregisterPolicy string
+
+const (
+	// DeregisterIfNotReady deregisters pods that are not Ready, it is the default policy
+	DeregisterIfNotReady DeregisterPolicy = "IfNotReady"
+	// DeregisterIfNotRunning deregisters pods that are not in phase Running
+	DeregisterIfNotRunning DeregisterPolicy = "IfNotRunning"
+	// DeregisterOnDelete deregisters pods only when they are deleted
+	DeregisterOnDelete DeregisterPolicy = "OnDelete"
+)
+
 type PortSelector struct {
-	PortNumber int32 `json:"portNumber"`
+	// +optional
+	PortNumber int32 `json:"portNumber,omitempty"`
+	// PortName is the name of container port, it is resolved for each pod and only supported in PodBackend
+	// +optional
+	PortName string `json:"portName,omitempty"`
 	// +optional
 	Protocol string `json:"protocol,omitempty"`
 }
 
+// BackendPort is one of the ports in PodBackend or ServiceBackend
+type BackendPort struct {
+	PortSelector `json:",inline"`
+	// Parameters overrides BackendGroupSpec.Parameters when registering this port
+	// +optional
+	Parameters map[string]string `json:"parameters,omitempty"`
+}
+
 type SelectPodByLabel struct {
-	Selector map[string]string `json:"selector"`
+	// +optional
+	Selector map[string]string `json:"selector,omitempty"`
+	// +optional
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
 	// +optional
 	Except []string `json:"except,omitempty"`
 }
@@ -192,25 +246,153 @@ type DriverType string
 
 const (
 	WebhookDriver DriverType = "Webhook"
+	GRPCDriver    DriverType = "GRPC"
+	// ExecDriver runs a local executable for each webhook call
+	ExecDriver DriverType = "Exec"
 )
 
 type LoadBalancerDriverSpec struct {
 	DriverType string `json:"driverType"`
-	Url        string `json:"url"`
+	// Url is the address of the driver, either Url or Service must be specified
+	// +optional
+	Url string `json:"url,omitempty"`
+	// Service references the in-cluster Service of the driver, either Url or Service must be specified
+	// +optional
+	Service *DriverServiceReference `json:"service,omitempty"`
+	// Exec configures the executable of Exec drivers, it must be specified if and only if driverType is Exec
+	// +optional
+	Exec *DriverExecConfig `json:"exec,omitempty"`
 	// +optional
 	Webhooks []WebhookConfig `json:"webhooks,omitempty"`
+	// MaxConcurrentCalls is the maximum number of in-flight webhook calls to the driver
+	// +optional
+	MaxConcurrentCalls *int32 `json:"maxConcurrentCalls,omitempty"`
+	// ProtocolVersion is the version of webhook protocol implemented by the driver, v1 is used if not specified
+	// +optional
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+	// TLS configures the TLS connection used to call the driver
+	// +optional
+	TLS *DriverTLSConfig `json:"tls,omitempty"`
+	// Auth configures how webhook requests to the driver are authenticated
+	// +optional
+	Auth *DriverAuthConfig `json:"auth,omitempty"`
+	// HealthCheck configures periodic probing of the driver, the driver is always considered ready if not specified
+	// +optional
+	HealthCheck *DriverHealthCheckConfig `json:"healthCheck,omitempty"`
+}
+
+// DriverServiceReference references a Service that serves the driver
+type DriverServiceReference struct {
+	// Namespace of the Service
+	Namespace string `json:"namespace"`
+	// Name of the Service
+	Name string `json:"name"`
+	// Port is a port of the Service
+	Port int32 `json:"port"`
+	// Path is prepended to the webhook name, e.g. webhooks are called at /lbcf/{webhookName} if Path is /lbcf
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// DriverExecConfig configures the executable run by Exec drivers.
+//
+// For each webhook call, the executable is run with Args followed by the webhook name,
+// the request is written to stdin in JSON, and the response is read from stdout in JSON
+type DriverExecConfig struct {
+	// Command is the absolute path of the executable in the controller container
+	Command string `json:"command"`
+	// Args are passed to the executable before the webhook name
+	// +optional
+	Args []string `json:"args,omitempty"`
+}
+
+// DriverTLSConfig configures the TLS connection used to call the driver
+type DriverTLSConfig struct {
+	// CABundle is a PEM encoded CA bundle used to verify the serving certificate of the driver.
+	// System roots are used if not specified
+	// +optional
+	CABundle []byte `json:"caBundle,omitempty"`
+	// ServerName is used to verify the hostname of the serving certificate, hostname in url is used if not specified
+	// +optional
+	ServerName string `json:"serverName,omitempty"`
+	// ClientCertSecretName is the name of a Secret in the same namespace as the driver,
+	// tls.crt and tls.key in the Secret are used as client certificate
+	// +optional
+	ClientCertSecretName string `json:"clientCertSecretName,omitempty"`
+}
+
+// DriverHealthCheckConfig configures periodic probing of the driver
+type DriverHealthCheckConfig struct {
+	// Path of the health endpoint of Webhook drivers, defaults to /healthz.
+	// GRPC drivers are probed with the standard gRPC health checking protocol
+	// +optional
+	Path string `json:"path,omitempty"`
+	// Period is the interval between probes, defaults to 10s
+	// +optional
+	Period *Duration `json:"period,omitempty"`
+	// Timeout of each probe, defaults to 3s
+	// +optional
+	Timeout *Duration `json:"timeout,omitempty"`
+	// FailureThreshold is the number of consecutive failures for the driver to be considered not ready, defaults to 3
+	// +optional
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+}
+
+type DriverAuthType string
+
+const (
+	// BearerTokenAuth sends the token stored in Secret as a bearer token
+	BearerTokenAuth DriverAuthType = "BearerToken"
+	// HMACAuth signs the request body with the key stored in Secret
+	HMACAuth DriverAuthType = "HMAC"
+)
+
+const (
+	// AuthTokenKey is the key of the token in Secret used by BearerToken auth
+	AuthTokenKey = "token"
+	// AuthHMACKey is the key of the signing key in Secret used by HMAC auth
+	AuthHMACKey = "key"
+)
+
+// DriverAuthConfig configures how webhook requests to the driver are authenticated
+type DriverAuthConfig struct {
+	// Type is either BearerToken or HMAC
+	Type DriverAuthType `json:"type"`
+	// SecretName is the name of a Secret in the same namespace as the driver,
+	// the Secret stores the token under key "token" for BearerToken, or the signing key under key "key" for HMAC
+	SecretName string `json:"secretName"`
 }
 
 type WebhookConfig struct {
 	Name string `json:"name"`
 	// +optional
 	Timeout Duration `json:"timeout,omitempty"`
+	// +optional
+	RateLimit *RateLimitConfig `json:"rateLimit,omitempty"`
+	// Optional means the driver may not implement the webhook, calling it is treated as success
+	// if the driver responds with http status 501, gRPC code Unimplemented or Exec exit code 3.
+	// createLoadBalancer and deleteLoadBalancer can not be optional
+	// +optional
+	Optional bool `json:"optional,omitempty"`
+}
+
+// RateLimitConfig limits how often a webhook can be called
+type RateLimitConfig struct {
+	// QPS is the maximum number of calls per second
+	QPS int32 `json:"qps"`
+	// Burst is the maximum number of calls in a burst, QPS is used if not set
+	// +optional
+	Burst int32 `json:"burst,omitempty"`
 }
 
 type LoadBalancerDriverConditionType string
 
 const (
 	DriverAccepted LoadBalancerDriverConditionType = "Accepted"
+	// DriverReady indicates the driver passes health checks
+	DriverReady LoadBalancerDriverConditionType = "Ready"
+	// DriverCircuitBreakerOpen indicates webhook calls to the driver are short-circuited because of consecutive failures
+	DriverCircuitBreakerOpen LoadBalancerDriverConditionType = "CircuitBreakerOpen"
 )
 
 type LoadBalancerDriverCondition struct {
@@ -219,6 +401,9 @@ type LoadBalancerDriverCondition struct {
 	// Status is the status of the condition.
 	// Can be True, False, Unknown.
 	Status ConditionStatus `json:"status"`
+	// Last time the condition was probed.
+	// +optional
+	LastProbeTime metav1.Time `json:"lastProbeTime,omitempty"`
 	// Last time the condition transitioned from one status to another.
 	// +optional
 	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
@@ -271,6 +456,8 @@ type BackendRecordSpec struct {
 	StaticAddr *string `json:"staticAddr,omitempty"`
 	// +optional
 	EnsurePolicy *EnsurePolicyConfig `json:"ensurePolicy,omitempty"`
+	// +optional
+	DrainPolicy *DrainPolicyConfig `json:"drainPolicy,omitempty"`
 }
 
 type PodBackendRecord struct {
@@ -394,6 +581,7 @@ type BackendRecordConditionType string
 
 const (
 	BackendRegistered BackendRecordConditionType = "Registered"
+	BackendDraining   BackendRecordConditionType = "Draining"
 )
 
 type BackendRecordCondition struct {
@@ -461,6 +649,9 @@ const (
 	ReasonOperationInProgress ConditionReason = "OperationInProgres"
 	ReasonOperationFailed     ConditionReason = "OperationFailed"
 	ReasonInvalidResponse     ConditionReason = "InvalidResponse"
+	ReasonHealthCheckFailed   ConditionReason = "HealthCheckFailed"
+	ReasonCircuitOpen         ConditionReason = "CircuitOpen"
+	ReasonCircuitHalfOpen     ConditionReason = "CircuitHalfOpen"
 )
 
 func (c ConditionReason) String() string {
@@ -479,3 +670,7 @@ type EnsurePolicyConfig struct {
 	// +optional
 	MinPeriod *Duration `json:"minPeriod,omitempty"`
 }
+
+type DrainPolicyConfig struct {
+	Period Duration `json:"period"`
+}
@@ -23,6 +23,7 @@
 package v1beta1
 
 import (
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -117,6 +118,11 @@ func (in *BackendGroupSpec) DeepCopyInto(out *BackendGroupSpec) {
 		*out = new(EnsurePolicyConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DrainPolicy != nil {
+		in, out := &in.DrainPolicy, &out.DrainPolicy
+		*out = new(DrainPolicyConfig)
+		**out = **in
+	}
 	return
 }
 
@@ -146,6 +152,30 @@ func (in *BackendGroupStatus) DeepCopy() *BackendGroupStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackendPort) DeepCopyInto(out *BackendPort) {
+	*out = *in
+	out.PortSelector = in.PortSelector
+	if in.Parameters != nil {
+		in, out := &in.Parameters, &out.Parameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackendPort.
+func (in *BackendPort) DeepCopy() *BackendPort {
+	if in == nil {
+		return nil
+	}
+	out := new(BackendPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackendRecord) DeepCopyInto(out *BackendRecord) {
 	*out = *in
@@ -268,6 +298,11 @@ func (in *BackendRecordSpec) DeepCopyInto(out *BackendRecordSpec) {
 		*out = new(EnsurePolicyConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DrainPolicy != nil {
+		in, out := &in.DrainPolicy, &out.DrainPolicy
+		*out = new(DrainPolicyConfig)
+		**out = **in
+	}
 	return
 }
 
@@ -311,6 +346,123 @@ func (in *BackendRecordStatus) DeepCopy() *BackendRecordStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriverAuthConfig) DeepCopyInto(out *DriverAuthConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverAuthConfig.
+func (in *DriverAuthConfig) DeepCopy() *DriverAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DriverAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DrainPolicyConfig) DeepCopyInto(out *DrainPolicyConfig) {
+	*out = *in
+	out.Period = in.Period
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DrainPolicyConfig.
+func (in *DrainPolicyConfig) DeepCopy() *DrainPolicyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DrainPolicyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriverExecConfig) DeepCopyInto(out *DriverExecConfig) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverExecConfig.
+func (in *DriverExecConfig) DeepCopy() *DriverExecConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DriverExecConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriverHealthCheckConfig) DeepCopyInto(out *DriverHealthCheckConfig) {
+	*out = *in
+	if in.Period != nil {
+		in, out := &in.Period, &out.Period
+		*out = new(Duration)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(Duration)
+		**out = **in
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverHealthCheckConfig.
+func (in *DriverHealthCheckConfig) DeepCopy() *DriverHealthCheckConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DriverHealthCheckConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriverServiceReference) DeepCopyInto(out *DriverServiceReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverServiceReference.
+func (in *DriverServiceReference) DeepCopy() *DriverServiceReference {
+	if in == nil {
+		return nil
+	}
+	out := new(DriverServiceReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DriverTLSConfig) DeepCopyInto(out *DriverTLSConfig) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriverTLSConfig.
+func (in *DriverTLSConfig) DeepCopy() *DriverTLSConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DriverTLSConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Duration) DeepCopyInto(out *Duration) {
 	*out = *in
@@ -440,6 +592,7 @@ func (in *LoadBalancerDriver) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadBalancerDriverCondition) DeepCopyInto(out *LoadBalancerDriverCondition) {
 	*out = *in
+	in.LastProbeTime.DeepCopyInto(&out.LastProbeTime)
 	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
 	return
 }
@@ -490,10 +643,42 @@ func (in *LoadBalancerDriverList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LoadBalancerDriverSpec) DeepCopyInto(out *LoadBalancerDriverSpec) {
 	*out = *in
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(DriverServiceReference)
+		**out = **in
+	}
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(DriverExecConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Webhooks != nil {
 		in, out := &in.Webhooks, &out.Webhooks
 		*out = make([]WebhookConfig, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MaxConcurrentCalls != nil {
+		in, out := &in.MaxConcurrentCalls, &out.MaxConcurrentCalls
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(DriverTLSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(DriverAuthConfig)
+		**out = **in
+	}
+	if in.HealthCheck != nil {
+		in, out := &in.HealthCheck, &out.HealthCheck
+		*out = new(DriverHealthCheckConfig)
+		(*in).DeepCopyInto(*out)
 	}
 	return
 }
@@ -649,6 +834,13 @@ func (in *NodeBackendRecord) DeepCopy() *NodeBackendRecord {
 func (in *PodBackend) DeepCopyInto(out *PodBackend) {
 	*out = *in
 	out.Port = in.Port
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]BackendPort, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.ByLabel != nil {
 		in, out := &in.ByLabel, &out.ByLabel
 		*out = new(SelectPodByLabel)
@@ -705,6 +897,22 @@ func (in *PortSelector) DeepCopy() *PortSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RateLimitConfig) DeepCopyInto(out *RateLimitConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RateLimitConfig.
+func (in *RateLimitConfig) DeepCopy() *RateLimitConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RateLimitConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SelectPodByLabel) DeepCopyInto(out *SelectPodByLabel) {
 	*out = *in
@@ -715,6 +923,11 @@ func (in *SelectPodByLabel) DeepCopyInto(out *SelectPodByLabel) {
 			(*out)[key] = val
 		}
 	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Except != nil {
 		in, out := &in.Except, &out.Except
 		*out = make([]string, len(*in))
@@ -737,6 +950,13 @@ func (in *SelectPodByLabel) DeepCopy() *SelectPodByLabel {
 func (in *ServiceBackend) DeepCopyInto(out *ServiceBackend) {
 	*out = *in
 	out.Port = in.Port
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]BackendPort, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.NodeSelector != nil {
 		in, out := &in.NodeSelector, &out.NodeSelector
 		*out = make(map[string]string, len(*in))
@@ -795,6 +1015,11 @@ func (in *ServicePort) DeepCopy() *ServicePort {
 func (in *WebhookConfig) DeepCopyInto(out *WebhookConfig) {
 	*out = *in
 	out.Timeout = in.Timeout
+	if in.RateLimit != nil {
+		in, out := &in.RateLimit, &out.RateLimit
+		*out = new(RateLimitConfig)
+		**out = **in
+	}
 	return
 }
 
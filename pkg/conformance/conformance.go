@@ -0,0 +1,581 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package conformance runs scripted scenarios against a driver and checks its responses
+// against the LBCF webhook specification.
+package conformance
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/driversdk"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks/auth"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+)
+
+// Config is the configuration of a conformance run
+type Config struct {
+	// DriverURL is the url of the driver, webhooks are called at DriverURL/{webhook name}
+	DriverURL string
+
+	// LBSpec and LBAttributes are used to create the load balancer
+	LBSpec       map[string]string
+	LBAttributes map[string]string
+
+	// BackendParameters, PodIP and Port are used to register a pod backend
+	BackendParameters map[string]string
+	PodIP             string
+	Port              int32
+	Protocol          string
+
+	// DrainBackend enables the drainBackend scenario, it is skipped by default since drainBackend is
+	// called only if it is configured in LoadBalancerDriverSpec
+	DrainBackend bool
+
+	// MaxCalls is the maximum number of calls of a webhook until it returns a status other than Running
+	MaxCalls int
+
+	// RetryInterval is the interval between calls of a webhook that returns Running
+	RetryInterval time.Duration
+
+	// HTTPClient is used to call the driver, http.DefaultClient is used if it is nil
+	HTTPClient *http.Client
+
+	// BearerToken or HMACKey is used to authenticate requests in the same way as LBCF does,
+	// if the driver is configured with spec.auth. At most one of them can be set
+	BearerToken string
+	HMACKey     []byte
+}
+
+// Result is the result of a scenario
+type Result struct {
+	Name     string
+	Passed   bool
+	Skipped  bool
+	Msg      string
+	Duration time.Duration
+}
+
+// Report contains results of all scenarios in the order they run
+type Report struct {
+	Results []Result
+}
+
+// Passed returns true if no scenario failed
+func (r *Report) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed && !result.Skipped {
+			return false
+		}
+	}
+	return true
+}
+
+// Print writes a human readable report to w
+func (r *Report) Print(w io.Writer) {
+	var passed, failed, skipped int
+	for _, result := range r.Results {
+		var state string
+		switch {
+		case result.Skipped:
+			state = "SKIP"
+			skipped++
+		case result.Passed:
+			state = "PASS"
+			passed++
+		default:
+			state = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(w, "[%s] %s (%s)", state, result.Name, result.Duration.Round(time.Millisecond))
+		if result.Msg != "" {
+			fmt.Fprintf(w, ": %s", result.Msg)
+		}
+		fmt.Fprintln(w)
+	}
+	fmt.Fprintf(w, "%d passed, %d failed, %d skipped\n", passed, failed, skipped)
+}
+
+// skipError indicates the scenario is skipped
+type skipError struct {
+	msg string
+}
+
+func (e skipError) Error() string {
+	return e.msg
+}
+
+// scenario returns a message to be shown in report when it passes
+type scenario struct {
+	name string
+	run  func(r *runner) (string, error)
+}
+
+var scenarios = []scenario{
+	{name: "healthCheck", run: (*runner).healthCheck},
+	{name: webhooks.ValidateLoadBalancer, run: (*runner).validateLoadBalancer},
+	{name: webhooks.CreateLoadBalancer, run: (*runner).createLoadBalancer},
+	{name: webhooks.CreateLoadBalancer + "-retry", run: (*runner).createLoadBalancerRetry},
+	{name: webhooks.CreateLoadBalancer + "-replay", run: (*runner).createLoadBalancerReplay},
+	{name: webhooks.EnsureLoadBalancer, run: (*runner).ensureLoadBalancer},
+	{name: webhooks.ValidateBackend, run: (*runner).validateBackend},
+	{name: webhooks.GenerateBackendAddr, run: (*runner).generateBackendAddr},
+	{name: webhooks.EnsureBackend, run: (*runner).ensureBackend},
+	{name: webhooks.DrainBackend, run: (*runner).drainBackend},
+	{name: webhooks.DeregBackend, run: (*runner).deregisterBackend},
+	{name: webhooks.DeleteLoadBalancer, run: (*runner).deleteLoadBalancer},
+}
+
+// Run runs all scenarios in order, scenarios that depend on a failed one are skipped
+func Run(cfg Config) *Report {
+	if cfg.MaxCalls <= 0 {
+		cfg.MaxCalls = 1
+	}
+	r := &runner{
+		cfg:          cfg,
+		client:       driversdk.NewClient(cfg.DriverURL, authHTTPClient(cfg)),
+		lbRecordID:   string(uuid.NewUUID()),
+		beRecordID:   string(uuid.NewUUID()),
+		addrRecordID: string(uuid.NewUUID()),
+	}
+	report := &Report{}
+	for _, s := range scenarios {
+		start := time.Now()
+		msg, err := s.run(r)
+		result := Result{
+			Name:     s.name,
+			Passed:   err == nil,
+			Msg:      msg,
+			Duration: time.Since(start),
+		}
+		if err != nil {
+			result.Msg = err.Error()
+			_, result.Skipped = err.(skipError)
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report
+}
+
+type runner struct {
+	cfg    Config
+	client *driversdk.Client
+
+	lbRecordID   string
+	beRecordID   string
+	addrRecordID string
+
+	// lbInfo and lbCreateRequest are set after the load balancer is created,
+	// lbCreateRequest is the request that returned Succ
+	lbInfo          map[string]string
+	lbCreateRequest webhooks.RequestForRetryHooks
+	// backendAddr is set after the backend address is generated
+	backendAddr string
+}
+
+func (r *runner) healthCheck() (string, error) {
+	return "", r.client.HealthCheck()
+}
+
+func (r *runner) validateLoadBalancer() (string, error) {
+	rsp, err := r.client.ValidateLoadBalancer(&webhooks.ValidateLoadBalancerRequest{
+		LBSpec:     r.cfg.LBSpec,
+		Operation:  webhooks.OperationCreate,
+		Attributes: r.cfg.LBAttributes,
+	})
+	return validateNoRetryResponse(webhooks.ValidateLoadBalancer, &rsp.ResponseForNoRetryHooks, err)
+}
+
+func (r *runner) createLoadBalancer() (string, error) {
+	var lbInfo map[string]string
+	var lastReq webhooks.RequestForRetryHooks
+	msg, err := r.callUntilDone(webhooks.CreateLoadBalancer, r.lbRecordID, func(req webhooks.RequestForRetryHooks) (*webhooks.ResponseForFailRetryHooks, error) {
+		lastReq = req
+		rsp, err := r.client.CreateLoadBalancer(&webhooks.CreateLoadBalancerRequest{
+			RequestForRetryHooks: req,
+			LBSpec:               r.cfg.LBSpec,
+			Attributes:           r.cfg.LBAttributes,
+		})
+		lbInfo = rsp.LBInfo
+		return &rsp.ResponseForFailRetryHooks, err
+	})
+	if err != nil {
+		return "", err
+	}
+	// LBCF uses lbSpec as lbInfo if lbInfo is not returned
+	if len(lbInfo) == 0 {
+		lbInfo = r.cfg.LBSpec
+	}
+	r.lbInfo = lbInfo
+	r.lbCreateRequest = lastReq
+	return msg, nil
+}
+
+func (r *runner) createLoadBalancerRetry() (string, error) {
+	if r.lbInfo == nil {
+		return "", skipError{msg: "depends on " + webhooks.CreateLoadBalancer}
+	}
+	var lbInfo map[string]string
+	msg, err := r.callUntilDone(webhooks.CreateLoadBalancer, r.lbRecordID, func(req webhooks.RequestForRetryHooks) (*webhooks.ResponseForFailRetryHooks, error) {
+		rsp, err := r.client.CreateLoadBalancer(&webhooks.CreateLoadBalancerRequest{
+			RequestForRetryHooks: req,
+			LBSpec:               r.cfg.LBSpec,
+			Attributes:           r.cfg.LBAttributes,
+		})
+		lbInfo = rsp.LBInfo
+		return &rsp.ResponseForFailRetryHooks, err
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(lbInfo) == 0 {
+		lbInfo = r.cfg.LBSpec
+	}
+	if !equalMap(lbInfo, r.lbInfo) {
+		return "", fmt.Errorf("retry with the same recordID returned a different lbInfo, expect %v, get %v", r.lbInfo, lbInfo)
+	}
+	return msg, nil
+}
+
+// createLoadBalancerReplay resends the request that returned Succ with the same recordID and retryID,
+// as LBCF does if the response is lost, e.g. the call times out. The driver must return the same result
+func (r *runner) createLoadBalancerReplay() (string, error) {
+	if r.lbInfo == nil {
+		return "", skipError{msg: "depends on " + webhooks.CreateLoadBalancer}
+	}
+	rsp, err := r.client.CreateLoadBalancer(&webhooks.CreateLoadBalancerRequest{
+		RequestForRetryHooks: r.lbCreateRequest,
+		LBSpec:               r.cfg.LBSpec,
+		Attributes:           r.cfg.LBAttributes,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := validateRetryResponse(&rsp.ResponseForFailRetryHooks); err != nil {
+		return "", err
+	}
+	if rsp.Status != webhooks.StatusSucc {
+		return "", fmt.Errorf("replay with the same recordID and retryID returned %s, expect %s, msg: %s", rsp.Status, webhooks.StatusSucc, rsp.Msg)
+	}
+	lbInfo := rsp.LBInfo
+	if len(lbInfo) == 0 {
+		lbInfo = r.cfg.LBSpec
+	}
+	if !equalMap(lbInfo, r.lbInfo) {
+		return "", fmt.Errorf("replay with the same recordID and retryID returned a different lbInfo, expect %v, get %v", r.lbInfo, lbInfo)
+	}
+	return "", nil
+}
+
+func (r *runner) ensureLoadBalancer() (string, error) {
+	if r.lbInfo == nil {
+		return "", skipError{msg: "depends on " + webhooks.CreateLoadBalancer}
+	}
+	// ensureLoadBalancer may be called periodically with the same recordID
+	var msgs []string
+	for i := 0; i < 2; i++ {
+		msg, err := r.callUntilDone(webhooks.EnsureLoadBalancer, r.lbRecordID, func(req webhooks.RequestForRetryHooks) (*webhooks.ResponseForFailRetryHooks, error) {
+			rsp, err := r.client.EnsureLoadBalancer(&webhooks.EnsureLoadBalancerRequest{
+				RequestForRetryHooks: req,
+				LBInfo:               r.lbInfo,
+				Attributes:           r.cfg.LBAttributes,
+			})
+			return &rsp.ResponseForFailRetryHooks, err
+		})
+		if err != nil {
+			return "", err
+		}
+		msgs = append(msgs, msg)
+	}
+	return joinMsgs(msgs), nil
+}
+
+func (r *runner) validateBackend() (string, error) {
+	if r.lbInfo == nil {
+		return "", skipError{msg: "depends on " + webhooks.CreateLoadBalancer}
+	}
+	rsp, err := r.client.ValidateBackend(&webhooks.ValidateBackendRequest{
+		BackendType: "Pod",
+		LBInfo:      r.lbInfo,
+		Operation:   webhooks.OperationCreate,
+		Parameters:  r.cfg.BackendParameters,
+	})
+	return validateNoRetryResponse(webhooks.ValidateBackend, &rsp.ResponseForNoRetryHooks, err)
+}
+
+func (r *runner) generateBackendAddr() (string, error) {
+	if r.lbInfo == nil {
+		return "", skipError{msg: "depends on " + webhooks.CreateLoadBalancer}
+	}
+	var addrs []string
+	var msgs []string
+	// the same address must be generated for the same backend
+	for i := 0; i < 2; i++ {
+		var addr string
+		msg, err := r.callUntilDone(webhooks.GenerateBackendAddr, r.addrRecordID, func(req webhooks.RequestForRetryHooks) (*webhooks.ResponseForFailRetryHooks, error) {
+			rsp, err := r.client.GenerateBackendAddr(&webhooks.GenerateBackendAddrRequest{
+				RequestForRetryHooks: req,
+				LBInfo:               r.lbInfo,
+				LBAttributes:         r.cfg.LBAttributes,
+				Parameters:           r.cfg.BackendParameters,
+				PodBackend: &webhooks.PodBackendInGenerateAddrRequest{
+					Pod:  r.pod(),
+					Port: v1beta1.PortSelector{PortNumber: r.cfg.Port, Protocol: r.cfg.Protocol},
+				},
+			})
+			addr = rsp.BackendAddr
+			return &rsp.ResponseForFailRetryHooks, err
+		})
+		if err != nil {
+			return "", err
+		}
+		if addr == "" {
+			return "", fmt.Errorf("empty backendAddr returned with status %s", webhooks.StatusSucc)
+		}
+		addrs = append(addrs, addr)
+		msgs = append(msgs, msg)
+	}
+	if addrs[0] != addrs[1] {
+		return "", fmt.Errorf("different backendAddr generated for the same backend: %s, %s", addrs[0], addrs[1])
+	}
+	r.backendAddr = addrs[0]
+	return joinMsgs(append(msgs, "backendAddr: "+r.backendAddr)), nil
+}
+
+func (r *runner) ensureBackend() (string, error) {
+	if r.backendAddr == "" {
+		return "", skipError{msg: "depends on " + webhooks.GenerateBackendAddr}
+	}
+	// ensureBackend may be called periodically with the same recordID
+	var msgs []string
+	for i := 0; i < 2; i++ {
+		msg, err := r.callUntilDone(webhooks.EnsureBackend, r.beRecordID, r.backendOperation(r.client.EnsureBackend))
+		if err != nil {
+			return "", err
+		}
+		msgs = append(msgs, msg)
+	}
+	return joinMsgs(msgs), nil
+}
+
+func (r *runner) drainBackend() (string, error) {
+	if !r.cfg.DrainBackend {
+		return "", skipError{msg: "not enabled"}
+	}
+	if r.backendAddr == "" {
+		return "", skipError{msg: "depends on " + webhooks.GenerateBackendAddr}
+	}
+	// drainBackend may be called again after the backend is drained, until deregisterBackend is called
+	var msgs []string
+	for i := 0; i < 2; i++ {
+		msg, err := r.callUntilDone(webhooks.DrainBackend, r.beRecordID, r.backendOperation(r.client.DrainBackend))
+		if err != nil {
+			return "", err
+		}
+		msgs = append(msgs, msg)
+	}
+	return joinMsgs(msgs), nil
+}
+
+func (r *runner) deregisterBackend() (string, error) {
+	if r.backendAddr == "" {
+		return "", skipError{msg: "depends on " + webhooks.GenerateBackendAddr}
+	}
+	// deregistering a backend that is already deregistered must succeed
+	var msgs []string
+	for i := 0; i < 2; i++ {
+		msg, err := r.callUntilDone(webhooks.DeregBackend, r.beRecordID, r.backendOperation(r.client.DeregisterBackend))
+		if err != nil {
+			return "", err
+		}
+		msgs = append(msgs, msg)
+	}
+	return joinMsgs(msgs), nil
+}
+
+func (r *runner) deleteLoadBalancer() (string, error) {
+	if r.lbInfo == nil {
+		return "", skipError{msg: "depends on " + webhooks.CreateLoadBalancer}
+	}
+	// deleting a load balancer that is already deleted must succeed
+	var msgs []string
+	for i := 0; i < 2; i++ {
+		msg, err := r.callUntilDone(webhooks.DeleteLoadBalancer, r.lbRecordID, func(req webhooks.RequestForRetryHooks) (*webhooks.ResponseForFailRetryHooks, error) {
+			rsp, err := r.client.DeleteLoadBalancer(&webhooks.DeleteLoadBalancerRequest{
+				RequestForRetryHooks: req,
+				LBInfo:               r.lbInfo,
+				Attributes:           r.cfg.LBAttributes,
+			})
+			return &rsp.ResponseForFailRetryHooks, err
+		})
+		if err != nil {
+			return "", err
+		}
+		msgs = append(msgs, msg)
+	}
+	return joinMsgs(msgs), nil
+}
+
+func (r *runner) backendOperation(call func(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error)) func(req webhooks.RequestForRetryHooks) (*webhooks.ResponseForFailRetryHooks, error) {
+	return func(req webhooks.RequestForRetryHooks) (*webhooks.ResponseForFailRetryHooks, error) {
+		rsp, err := call(&webhooks.BackendOperationRequest{
+			RequestForRetryHooks: req,
+			LBInfo:               r.lbInfo,
+			BackendAddr:          r.backendAddr,
+			Parameters:           r.cfg.BackendParameters,
+		})
+		return &rsp.ResponseForFailRetryHooks, err
+	}
+}
+
+func (r *runner) pod() v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "lbcf-conformance",
+			Namespace: metav1.NamespaceDefault,
+			UID:       uuid.NewUUID(),
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "conformance",
+					Ports: []v1.ContainerPort{
+						{ContainerPort: r.cfg.Port, Protocol: v1.Protocol(r.cfg.Protocol)},
+					},
+				},
+			},
+		},
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+			PodIP: r.cfg.PodIP,
+			Conditions: []v1.PodCondition{
+				{Type: v1.PodReady, Status: v1.ConditionTrue},
+			},
+		},
+	}
+}
+
+// callUntilDone calls a webhook that can be retried with the same recordID and a new retryID each time,
+// until it returns a status other than Running. An error is returned if the final status is not Succ
+func (r *runner) callUntilDone(webhook string, recordID string, call func(req webhooks.RequestForRetryHooks) (*webhooks.ResponseForFailRetryHooks, error)) (string, error) {
+	for i := 0; i < r.cfg.MaxCalls; i++ {
+		if i > 0 {
+			time.Sleep(r.cfg.RetryInterval)
+		}
+		rsp, err := call(webhooks.RequestForRetryHooks{
+			RecordID: recordID,
+			RetryID:  string(uuid.NewUUID()),
+		})
+		if err != nil {
+			if driversdk.IsNotImplemented(err) && webhooks.OptionalWebhooks.Has(webhook) {
+				return "not implemented, treated as " + webhooks.StatusSucc, nil
+			}
+			return "", err
+		}
+		if err := validateRetryResponse(rsp); err != nil {
+			return "", err
+		}
+		switch rsp.Status {
+		case webhooks.StatusSucc:
+			if i > 0 {
+				return fmt.Sprintf("%s after %d %s", webhooks.StatusSucc, i, webhooks.StatusRunning), nil
+			}
+			return "", nil
+		case webhooks.StatusFail:
+			return "", fmt.Errorf("returned %s, msg: %s", webhooks.StatusFail, rsp.Msg)
+		}
+	}
+	return "", fmt.Errorf("still %s after %d calls", webhooks.StatusRunning, r.cfg.MaxCalls)
+}
+
+// authHTTPClient returns a copy of cfg.HTTPClient that authenticates requests with cfg.BearerToken or cfg.HMACKey
+func authHTTPClient(cfg Config) *http.Client {
+	client := http.DefaultClient
+	if cfg.HTTPClient != nil {
+		client = cfg.HTTPClient
+	}
+	if cfg.BearerToken == "" && len(cfg.HMACKey) == 0 {
+		return client
+	}
+	copied := *client
+	if cfg.BearerToken != "" {
+		copied.Transport = auth.BearerTokenRoundTripper(cfg.BearerToken, client.Transport)
+	} else {
+		copied.Transport = auth.HMACRoundTripper(cfg.HMACKey, client.Transport)
+	}
+	return &copied
+}
+
+// validateRetryResponse checks the fields LBCF relies on
+func validateRetryResponse(rsp *webhooks.ResponseForFailRetryHooks) error {
+	switch rsp.Status {
+	case webhooks.StatusSucc, webhooks.StatusFail, webhooks.StatusRunning:
+	default:
+		return fmt.Errorf("invalid status %q, must be one of %s, %s, %s", rsp.Status, webhooks.StatusSucc, webhooks.StatusFail, webhooks.StatusRunning)
+	}
+	if rsp.MinRetryDelayInSeconds < 0 {
+		return fmt.Errorf("invalid minRetryDelayInSeconds %d, must not be negative", rsp.MinRetryDelayInSeconds)
+	}
+	return nil
+}
+
+func validateNoRetryResponse(webhook string, rsp *webhooks.ResponseForNoRetryHooks, err error) (string, error) {
+	if err != nil {
+		if driversdk.IsNotImplemented(err) && webhooks.OptionalWebhooks.Has(webhook) {
+			return "not implemented, treated as accepted", nil
+		}
+		return "", err
+	}
+	if !rsp.Succ {
+		return "", fmt.Errorf("rejected the request, check the conformance configuration, msg: %s", rsp.Msg)
+	}
+	return "", nil
+}
+
+func equalMap(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if bv, ok := b[k]; !ok || bv != v {
+			return false
+		}
+	}
+	return true
+}
+
+func joinMsgs(msgs []string) string {
+	var ret string
+	for _, msg := range msgs {
+		if msg == "" {
+			continue
+		}
+		if ret != "" {
+			ret += ", "
+		}
+		ret += msg
+	}
+	return ret
+}
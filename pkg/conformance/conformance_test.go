@@ -0,0 +1,185 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package conformance
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"tkestack.io/lb-controlling-framework/pkg/fakedriver"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks/auth"
+)
+
+func newConfig(url string) Config {
+	return Config{
+		DriverURL:         url,
+		LBSpec:            map[string]string{"vip": "1.1.1.1"},
+		LBAttributes:      map[string]string{"a": "b"},
+		BackendParameters: map[string]string{"weight": "10"},
+		PodIP:             "10.0.0.1",
+		Port:              80,
+		Protocol:          "TCP",
+		MaxCalls:          5,
+	}
+}
+
+func TestRunFakeDriver(t *testing.T) {
+	server := httptest.NewServer(fakedriver.NewDriver(fakedriver.Behavior{RunningPhases: 2, DrainRunningPhases: 2}).Handler())
+	defer server.Close()
+
+	for _, drain := range []bool{false, true} {
+		cfg := newConfig(server.URL)
+		cfg.DrainBackend = drain
+		report := Run(cfg)
+		if !report.Passed() {
+			buf := &bytes.Buffer{}
+			report.Print(buf)
+			t.Fatalf("expect passed, get report:\n%s", buf.String())
+		}
+		if len(report.Results) != len(scenarios) {
+			t.Fatalf("expect %d results, get %d", len(scenarios), len(report.Results))
+		}
+		for _, result := range report.Results {
+			if result.Name == webhooks.DrainBackend && result.Skipped == drain {
+				t.Fatalf("drainBackend enabled: %v, get skipped: %v", drain, result.Skipped)
+			}
+		}
+	}
+}
+
+func TestRunFailedDriver(t *testing.T) {
+	server := httptest.NewServer(fakedriver.NewDriver(fakedriver.Behavior{
+		FailRate:     1,
+		FailWebhooks: []string{webhooks.CreateLoadBalancer},
+	}).Handler())
+	defer server.Close()
+
+	report := Run(newConfig(server.URL))
+	if report.Passed() {
+		t.Fatalf("expect failed")
+	}
+	for _, result := range report.Results {
+		switch result.Name {
+		case "healthCheck", webhooks.ValidateLoadBalancer:
+			if !result.Passed {
+				t.Errorf("expect %s passed, get %s", result.Name, result.Msg)
+			}
+		case webhooks.CreateLoadBalancer:
+			if result.Passed || result.Skipped {
+				t.Errorf("expect %s failed", result.Name)
+			}
+		default:
+			if !result.Skipped {
+				t.Errorf("expect %s skipped", result.Name)
+			}
+		}
+	}
+}
+
+func TestRunInvalidResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":"Done"}`))
+	}))
+	defer server.Close()
+
+	report := Run(newConfig(server.URL))
+	for _, result := range report.Results {
+		if result.Name == webhooks.CreateLoadBalancer {
+			if result.Passed || !strings.Contains(result.Msg, "invalid status") {
+				t.Fatalf("expect invalid status, get %s", result.Msg)
+			}
+			return
+		}
+	}
+	t.Fatalf("result of %s not found", webhooks.CreateLoadBalancer)
+}
+
+func TestRunReplay(t *testing.T) {
+	// the driver fails if a request is replayed with the same retryID
+	retryIDs := make(map[string]bool)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := &webhooks.RequestForRetryHooks{}
+		json.NewDecoder(r.Body).Decode(req)
+		if r.URL.Path == "/"+webhooks.CreateLoadBalancer && retryIDs[req.RetryID] {
+			w.Write([]byte(`{"status":"Fail","msg":"duplicate retryID"}`))
+			return
+		}
+		retryIDs[req.RetryID] = true
+		w.Write([]byte(`{"succ":true,"status":"Succ","backendAddr":"10.0.0.1:80"}`))
+	}))
+	defer server.Close()
+
+	report := Run(newConfig(server.URL))
+	for _, result := range report.Results {
+		switch result.Name {
+		case webhooks.CreateLoadBalancer, webhooks.CreateLoadBalancer + "-retry":
+			if !result.Passed {
+				t.Errorf("expect %s passed, get %s", result.Name, result.Msg)
+			}
+		case webhooks.CreateLoadBalancer + "-replay":
+			if result.Passed || !strings.Contains(result.Msg, "duplicate retryID") {
+				t.Errorf("expect %s failed, get %s", result.Name, result.Msg)
+			}
+		}
+	}
+}
+
+func TestRunAuth(t *testing.T) {
+	handler := fakedriver.NewDriver(fakedriver.Behavior{}).Handler()
+	cases := []struct {
+		name    string
+		handler http.Handler
+		modify  func(cfg *Config)
+		expect  bool
+	}{
+		{
+			name:    "bearer-token",
+			handler: auth.BearerTokenHandler("token", handler),
+			modify:  func(cfg *Config) { cfg.BearerToken = "token" },
+			expect:  true,
+		},
+		{
+			name:    "hmac",
+			handler: auth.HMACHandler([]byte("key"), handler),
+			modify:  func(cfg *Config) { cfg.HMACKey = []byte("key") },
+			expect:  true,
+		},
+		{
+			name:    "missing-credential",
+			handler: auth.HMACHandler([]byte("key"), handler),
+			modify:  func(cfg *Config) {},
+			expect:  false,
+		},
+	}
+	for _, c := range cases {
+		server := httptest.NewServer(c.handler)
+		cfg := newConfig(server.URL)
+		c.modify(&cfg)
+		report := Run(cfg)
+		server.Close()
+		if report.Passed() != c.expect {
+			buf := &bytes.Buffer{}
+			report.Print(buf)
+			t.Fatalf("case %s, expect passed: %v, get report:\n%s", c.name, c.expect, buf.String())
+		}
+	}
+}
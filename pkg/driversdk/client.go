@@ -0,0 +1,154 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package driversdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+)
+
+// StatusError is returned by Client if the driver responds with a http status other than 200
+type StatusError struct {
+	Webhook    string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("webhook %s, http status code: %d, body: %s", e.Webhook, e.StatusCode, e.Body)
+}
+
+// IsNotImplemented returns true if the driver does not implement the webhook
+func IsNotImplemented(err error) bool {
+	statusErr, ok := err.(*StatusError)
+	return ok && statusErr.StatusCode == http.StatusNotImplemented
+}
+
+// Client calls webhooks of a driver in the same way as LBCF does, it is mainly used in tests
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient creates a Client that calls webhooks at baseURL/{webhook name}, http.DefaultClient is used if httpClient is nil
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// ValidateLoadBalancer calls webhook validateLoadBalancer
+func (c *Client) ValidateLoadBalancer(req *webhooks.ValidateLoadBalancerRequest) (*webhooks.ValidateLoadBalancerResponse, error) {
+	rsp := &webhooks.ValidateLoadBalancerResponse{}
+	return rsp, c.call(webhooks.ValidateLoadBalancer, req, rsp)
+}
+
+// CreateLoadBalancer calls webhook createLoadBalancer
+func (c *Client) CreateLoadBalancer(req *webhooks.CreateLoadBalancerRequest) (*webhooks.CreateLoadBalancerResponse, error) {
+	rsp := &webhooks.CreateLoadBalancerResponse{}
+	return rsp, c.call(webhooks.CreateLoadBalancer, req, rsp)
+}
+
+// EnsureLoadBalancer calls webhook ensureLoadBalancer
+func (c *Client) EnsureLoadBalancer(req *webhooks.EnsureLoadBalancerRequest) (*webhooks.EnsureLoadBalancerResponse, error) {
+	rsp := &webhooks.EnsureLoadBalancerResponse{}
+	return rsp, c.call(webhooks.EnsureLoadBalancer, req, rsp)
+}
+
+// DeleteLoadBalancer calls webhook deleteLoadBalancer
+func (c *Client) DeleteLoadBalancer(req *webhooks.DeleteLoadBalancerRequest) (*webhooks.DeleteLoadBalancerResponse, error) {
+	rsp := &webhooks.DeleteLoadBalancerResponse{}
+	return rsp, c.call(webhooks.DeleteLoadBalancer, req, rsp)
+}
+
+// ValidateBackend calls webhook validateBackend
+func (c *Client) ValidateBackend(req *webhooks.ValidateBackendRequest) (*webhooks.ValidateBackendResponse, error) {
+	rsp := &webhooks.ValidateBackendResponse{}
+	return rsp, c.call(webhooks.ValidateBackend, req, rsp)
+}
+
+// GenerateBackendAddr calls webhook generateBackendAddr
+func (c *Client) GenerateBackendAddr(req *webhooks.GenerateBackendAddrRequest) (*webhooks.GenerateBackendAddrResponse, error) {
+	rsp := &webhooks.GenerateBackendAddrResponse{}
+	return rsp, c.call(webhooks.GenerateBackendAddr, req, rsp)
+}
+
+// EnsureBackend calls webhook ensureBackend
+func (c *Client) EnsureBackend(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	rsp := &webhooks.BackendOperationResponse{}
+	return rsp, c.call(webhooks.EnsureBackend, req, rsp)
+}
+
+// DeregisterBackend calls webhook deregisterBackend
+func (c *Client) DeregisterBackend(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	rsp := &webhooks.BackendOperationResponse{}
+	return rsp, c.call(webhooks.DeregBackend, req, rsp)
+}
+
+// DrainBackend calls webhook drainBackend
+func (c *Client) DrainBackend(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	rsp := &webhooks.BackendOperationResponse{}
+	return rsp, c.call(webhooks.DrainBackend, req, rsp)
+}
+
+// HealthCheck gets HealthzPath of the driver
+func (c *Client) HealthCheck() error {
+	httpRsp, err := c.httpClient.Get(c.baseURL + HealthzPath)
+	if err != nil {
+		return err
+	}
+	defer httpRsp.Body.Close()
+	if httpRsp.StatusCode < http.StatusOK || httpRsp.StatusCode >= http.StatusMultipleChoices {
+		body, _ := ioutil.ReadAll(httpRsp.Body)
+		return &StatusError{Webhook: "healthz", StatusCode: httpRsp.StatusCode, Body: string(body)}
+	}
+	return nil
+}
+
+func (c *Client) call(webhook string, req interface{}, rsp interface{}) error {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	httpRsp, err := c.httpClient.Post(c.baseURL+"/"+webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer httpRsp.Body.Close()
+	rspBody, err := ioutil.ReadAll(httpRsp.Body)
+	if err != nil {
+		return err
+	}
+	if httpRsp.StatusCode != http.StatusOK {
+		return &StatusError{Webhook: webhook, StatusCode: httpRsp.StatusCode, Body: string(rspBody)}
+	}
+	if err := json.Unmarshal(rspBody, rsp); err != nil {
+		return fmt.Errorf("webhook %s, decode response failed: %v", webhook, err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package driversdk helps writing LBCF drivers in Go.
+//
+// A driver implements the Driver interface, one method per webhook, and serves it with NewHandler,
+// which takes care of routing, decoding, validating and encoding according to the LBCF webhook specification.
+package driversdk
+
+import (
+	"errors"
+
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+)
+
+// Driver is implemented by LBCF drivers.
+//
+// For webhooks that can be retried, returning a non-nil error is equivalent to returning a response with status Fail,
+// and a response with empty status is treated as Succ.
+// For validateLoadBalancer and validateBackend, returning a non-nil error rejects the object.
+type Driver interface {
+	ValidateLoadBalancer(req *webhooks.ValidateLoadBalancerRequest) (*webhooks.ValidateLoadBalancerResponse, error)
+	CreateLoadBalancer(req *webhooks.CreateLoadBalancerRequest) (*webhooks.CreateLoadBalancerResponse, error)
+	EnsureLoadBalancer(req *webhooks.EnsureLoadBalancerRequest) (*webhooks.EnsureLoadBalancerResponse, error)
+	DeleteLoadBalancer(req *webhooks.DeleteLoadBalancerRequest) (*webhooks.DeleteLoadBalancerResponse, error)
+	ValidateBackend(req *webhooks.ValidateBackendRequest) (*webhooks.ValidateBackendResponse, error)
+	GenerateBackendAddr(req *webhooks.GenerateBackendAddrRequest) (*webhooks.GenerateBackendAddrResponse, error)
+	EnsureBackend(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error)
+	DeregisterBackend(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error)
+}
+
+// HealthChecker may be implemented by a Driver to report its health on /healthz
+type HealthChecker interface {
+	HealthCheck() error
+}
+
+// Drainer may be implemented by a Driver to serve the opt-in webhook drainBackend,
+// a response with status Running tells LBCF that the backend is still draining
+type Drainer interface {
+	DrainBackend(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error)
+}
+
+// ErrNotImplemented is returned by drivers for webhooks they do not implement.
+// It is responded with http status 501, which is treated as success by LBCF if the webhook is marked optional
+var ErrNotImplemented = errors.New("webhook not implemented")
+
+// UnimplementedDriver returns ErrNotImplemented for all webhooks, it can be embedded in drivers that
+// implement only part of the webhooks
+type UnimplementedDriver struct{}
+
+var _ Driver = UnimplementedDriver{}
+
+// ValidateLoadBalancer implements Driver
+func (UnimplementedDriver) ValidateLoadBalancer(req *webhooks.ValidateLoadBalancerRequest) (*webhooks.ValidateLoadBalancerResponse, error) {
+	return nil, ErrNotImplemented
+}
+
+// CreateLoadBalancer implements Driver
+func (UnimplementedDriver) CreateLoadBalancer(req *webhooks.CreateLoadBalancerRequest) (*webhooks.CreateLoadBalancerResponse, error) {
+	return nil, ErrNotImplemented
+}
+
+// EnsureLoadBalancer implements Driver
+func (UnimplementedDriver) EnsureLoadBalancer(req *webhooks.EnsureLoadBalancerRequest) (*webhooks.EnsureLoadBalancerResponse, error) {
+	return nil, ErrNotImplemented
+}
+
+// DeleteLoadBalancer implements Driver
+func (UnimplementedDriver) DeleteLoadBalancer(req *webhooks.DeleteLoadBalancerRequest) (*webhooks.DeleteLoadBalancerResponse, error) {
+	return nil, ErrNotImplemented
+}
+
+// ValidateBackend implements Driver
+func (UnimplementedDriver) ValidateBackend(req *webhooks.ValidateBackendRequest) (*webhooks.ValidateBackendResponse, error) {
+	return nil, ErrNotImplemented
+}
+
+// GenerateBackendAddr implements Driver
+func (UnimplementedDriver) GenerateBackendAddr(req *webhooks.GenerateBackendAddrRequest) (*webhooks.GenerateBackendAddrResponse, error) {
+	return nil, ErrNotImplemented
+}
+
+// EnsureBackend implements Driver
+func (UnimplementedDriver) EnsureBackend(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	return nil, ErrNotImplemented
+}
+
+// DeregisterBackend implements Driver
+func (UnimplementedDriver) DeregisterBackend(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	return nil, ErrNotImplemented
+}
@@ -0,0 +1,63 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package drivertest provides utilities for testing drivers written with driversdk.
+package drivertest
+
+import (
+	"fmt"
+	"net/http/httptest"
+
+	"tkestack.io/lb-controlling-framework/pkg/driversdk"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+)
+
+// Harness serves a driver with driversdk.NewHandler on a local httptest.Server,
+// webhooks are called through the HTTP server by Client, just like LBCF does
+type Harness struct {
+	Server *httptest.Server
+	Client *driversdk.Client
+}
+
+// NewHarness starts serving driver, Close must be called when the test is finished
+func NewHarness(driver driversdk.Driver) *Harness {
+	server := httptest.NewServer(driversdk.NewHandler(driver))
+	return &Harness{
+		Server: server,
+		Client: driversdk.NewClient(server.URL, server.Client()),
+	}
+}
+
+// Close shuts down the server
+func (h *Harness) Close() {
+	h.Server.Close()
+}
+
+// WaitDone calls fn until it returns a status other than Running, at most maxCalls times.
+// fn is expected to call a webhook that can be retried with the same recordID, as LBCF does
+func WaitDone(maxCalls int, fn func() (webhooks.StatusGetter, error)) (webhooks.StatusGetter, error) {
+	for i := 0; i < maxCalls; i++ {
+		rsp, err := fn()
+		if err != nil {
+			return nil, err
+		}
+		if rsp.GetStatus() != webhooks.StatusRunning {
+			return rsp, nil
+		}
+	}
+	return nil, fmt.Errorf("still %s after %d calls", webhooks.StatusRunning, maxCalls)
+}
@@ -0,0 +1,108 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package driversdk
+
+import (
+	"sync"
+	"time"
+
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+)
+
+// OperationCache makes non-idempotent operations, e.g. creating a load balancer, safe to be retried.
+//
+// LBCF calls webhooks that can be retried with the same recordID until status Succ is returned,
+// OperationCache keys operations by webhook name and recordID, concurrent calls of the same operation
+// share one execution, and the result of a succeeded operation is returned to later calls until it expires.
+//
+// Note that ensureLoadBalancer and ensureBackend may be called periodically with the same recordID,
+// use Forget or a short expiration if the operation must be re-executed.
+type OperationCache struct {
+	expiration time.Duration
+
+	lock       sync.Mutex
+	operations map[operationKey]*operation
+}
+
+type operationKey struct {
+	webhook  string
+	recordID string
+}
+
+type operation struct {
+	done     chan struct{}
+	result   webhooks.StatusGetter
+	err      error
+	finishAt time.Time
+}
+
+// NewOperationCache creates an OperationCache, results of succeeded operations are kept for expiration
+func NewOperationCache(expiration time.Duration) *OperationCache {
+	return &OperationCache{
+		expiration: expiration,
+		operations: make(map[operationKey]*operation),
+	}
+}
+
+// Do executes fn if the operation identified by webhook and recordID has not succeeded, or its result has expired.
+// If the operation is being executed by another call, Do waits for it and returns the same result.
+// Only responses in status Succ are cached, the operation is executed again if fn returns a non-nil error,
+// or a response in status Running or Fail.
+func (c *OperationCache) Do(webhook string, recordID string, fn func() (webhooks.StatusGetter, error)) (webhooks.StatusGetter, error) {
+	key := operationKey{webhook: webhook, recordID: recordID}
+	c.lock.Lock()
+	c.gc()
+	if op, ok := c.operations[key]; ok {
+		c.lock.Unlock()
+		<-op.done
+		return op.result, op.err
+	}
+	op := &operation{done: make(chan struct{})}
+	c.operations[key] = op
+	c.lock.Unlock()
+
+	op.result, op.err = fn()
+	c.lock.Lock()
+	op.finishAt = time.Now()
+	if op.err != nil || op.result == nil || op.result.GetStatus() != webhooks.StatusSucc {
+		delete(c.operations, key)
+	}
+	c.lock.Unlock()
+	close(op.done)
+	return op.result, op.err
+}
+
+// Forget removes the cached result of the operation identified by webhook and recordID
+func (c *OperationCache) Forget(webhook string, recordID string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := operationKey{webhook: webhook, recordID: recordID}
+	if op, ok := c.operations[key]; ok && !op.finishAt.IsZero() {
+		delete(c.operations, key)
+	}
+}
+
+// gc removes expired results, it must be called with c.lock held
+func (c *OperationCache) gc() {
+	now := time.Now()
+	for key, op := range c.operations {
+		if !op.finishAt.IsZero() && now.Sub(op.finishAt) >= c.expiration {
+			delete(c.operations, key)
+		}
+	}
+}
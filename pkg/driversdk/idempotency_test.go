@@ -0,0 +1,158 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package driversdk
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+)
+
+func newCreateLBResponse(status string, lbID string) *webhooks.CreateLoadBalancerResponse {
+	return &webhooks.CreateLoadBalancerResponse{
+		ResponseForFailRetryHooks: webhooks.ResponseForFailRetryHooks{
+			Status: status,
+		},
+		LBInfo: map[string]string{"lbID": lbID},
+	}
+}
+
+func lbIDOf(result webhooks.StatusGetter) string {
+	if rsp, ok := result.(*webhooks.CreateLoadBalancerResponse); ok {
+		return rsp.LBInfo["lbID"]
+	}
+	return ""
+}
+
+func TestOperationCache(t *testing.T) {
+	cache := NewOperationCache(time.Minute)
+	calls := 0
+	create := func() (webhooks.StatusGetter, error) {
+		calls++
+		return newCreateLBResponse(webhooks.StatusSucc, fmt.Sprintf("lb-%d", calls)), nil
+	}
+
+	result, err := cache.Do(webhooks.CreateLoadBalancer, "record", create)
+	if err != nil || lbIDOf(result) != "lb-1" {
+		t.Fatalf("expect lb-1, get %v, err: %v", result, err)
+	}
+	result, err = cache.Do(webhooks.CreateLoadBalancer, "record", create)
+	if err != nil || lbIDOf(result) != "lb-1" {
+		t.Fatalf("expect cached lb-1, get %v, err: %v", result, err)
+	}
+	result, _ = cache.Do(webhooks.CreateLoadBalancer, "another-record", create)
+	if lbIDOf(result) != "lb-2" {
+		t.Fatalf("expect lb-2, get %v", result)
+	}
+
+	cache.Forget(webhooks.CreateLoadBalancer, "record")
+	result, _ = cache.Do(webhooks.CreateLoadBalancer, "record", create)
+	if lbIDOf(result) != "lb-3" {
+		t.Fatalf("expect lb-3, get %v", result)
+	}
+
+	// failed operations are not cached
+	_, err = cache.Do(webhooks.EnsureBackend, "record", func() (webhooks.StatusGetter, error) {
+		return nil, fmt.Errorf("fake error")
+	})
+	if err == nil {
+		t.Fatalf("expect err")
+	}
+	result, err = cache.Do(webhooks.EnsureBackend, "record", func() (webhooks.StatusGetter, error) {
+		return &webhooks.BackendOperationResponse{
+			ResponseForFailRetryHooks: webhooks.ResponseForFailRetryHooks{Status: webhooks.StatusSucc},
+		}, nil
+	})
+	if err != nil || result.GetStatus() != webhooks.StatusSucc {
+		t.Fatalf("expect succ, get %v, err: %v", result, err)
+	}
+}
+
+func TestOperationCacheNotSucc(t *testing.T) {
+	cache := NewOperationCache(time.Minute)
+	statuses := []string{webhooks.StatusRunning, webhooks.StatusFail, webhooks.StatusSucc}
+	calls := 0
+	create := func() (webhooks.StatusGetter, error) {
+		rsp := newCreateLBResponse(statuses[calls], fmt.Sprintf("lb-%d", calls))
+		calls++
+		return rsp, nil
+	}
+
+	for i, expect := range statuses {
+		result, err := cache.Do(webhooks.CreateLoadBalancer, "record", create)
+		if err != nil {
+			t.Fatalf("call %d: unexpected err: %v", i, err)
+		}
+		if result.GetStatus() != expect {
+			t.Fatalf("call %d: expect status %s, get %s", i, expect, result.GetStatus())
+		}
+	}
+	if calls != 3 {
+		t.Fatalf("expect 3 calls, get %d", calls)
+	}
+
+	// the Succ response is cached
+	result, _ := cache.Do(webhooks.CreateLoadBalancer, "record", create)
+	if result.GetStatus() != webhooks.StatusSucc || lbIDOf(result) != "lb-2" {
+		t.Fatalf("expect cached succ response of lb-2, get %#v", result)
+	}
+	if calls != 3 {
+		t.Fatalf("expect 3 calls, get %d", calls)
+	}
+}
+
+func TestOperationCacheConcurrent(t *testing.T) {
+	cache := NewOperationCache(time.Minute)
+	var lock sync.Mutex
+	calls := 0
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cache.Do(webhooks.CreateLoadBalancer, "record", func() (webhooks.StatusGetter, error) {
+				lock.Lock()
+				calls++
+				lock.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				return newCreateLBResponse(webhooks.StatusSucc, "lb"), nil
+			})
+		}()
+	}
+	wg.Wait()
+	if calls != 1 {
+		t.Fatalf("expect 1 call, get %d", calls)
+	}
+}
+
+func TestOperationCacheExpiration(t *testing.T) {
+	cache := NewOperationCache(10 * time.Millisecond)
+	calls := 0
+	fn := func() (webhooks.StatusGetter, error) {
+		calls++
+		return newCreateLBResponse(webhooks.StatusSucc, "lb"), nil
+	}
+	cache.Do(webhooks.CreateLoadBalancer, "record", fn)
+	time.Sleep(20 * time.Millisecond)
+	cache.Do(webhooks.CreateLoadBalancer, "record", fn)
+	if calls != 2 {
+		t.Fatalf("expect 2 calls, get %d", calls)
+	}
+}
@@ -0,0 +1,287 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package driversdk
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+
+	"k8s.io/klog"
+)
+
+// HealthzPath is the default health check path of LoadBalancerDriver
+const HealthzPath = "/healthz"
+
+// NewHandler returns an http.Handler that serves driver at /{webhook name}, as well as HealthzPath.
+//
+// Requests are validated before passed to driver, invalid requests are responded with http status 400.
+// Responses are normalized so that LBCF always receives a valid status:
+//
+// For webhooks that can be retried, an error returned by driver results in status Fail, and an empty status
+// is treated as Succ.
+//
+// For validateLoadBalancer and validateBackend, an error returned by driver rejects the object,
+// and a nil response with nil error accepts it.
+//
+// Authentication is not handled, wrap the handler with auth.BearerTokenHandler or auth.HMACHandler if required.
+func NewHandler(driver Driver) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+webhooks.ValidateLoadBalancer, serveWebhook(webhooks.ValidateLoadBalancer, func(body []byte) (interface{}, error) {
+		req := &webhooks.ValidateLoadBalancerRequest{}
+		if err := decodeRequest(body, req, &req.RequestMeta); err != nil {
+			return nil, err
+		}
+		if err := validateOperation(req.Operation); err != nil {
+			return nil, err
+		}
+		rsp, err := driver.ValidateLoadBalancer(req)
+		if rsp == nil {
+			rsp = &webhooks.ValidateLoadBalancerResponse{Succ: err == nil}
+		}
+		return rsp, normalizeNoRetryResponse(&rsp.ResponseForNoRetryHooks, err)
+	}))
+	mux.HandleFunc("/"+webhooks.CreateLoadBalancer, serveWebhook(webhooks.CreateLoadBalancer, func(body []byte) (interface{}, error) {
+		req := &webhooks.CreateLoadBalancerRequest{}
+		if err := decodeRetryRequest(body, req, &req.RequestMeta, &req.RequestForRetryHooks); err != nil {
+			return nil, err
+		}
+		rsp, err := driver.CreateLoadBalancer(req)
+		if rsp == nil {
+			rsp = &webhooks.CreateLoadBalancerResponse{}
+		}
+		return rsp, normalizeRetryResponse(&rsp.ResponseForFailRetryHooks, err)
+	}))
+	mux.HandleFunc("/"+webhooks.EnsureLoadBalancer, serveWebhook(webhooks.EnsureLoadBalancer, func(body []byte) (interface{}, error) {
+		req := &webhooks.EnsureLoadBalancerRequest{}
+		if err := decodeRetryRequest(body, req, &req.RequestMeta, &req.RequestForRetryHooks); err != nil {
+			return nil, err
+		}
+		rsp, err := driver.EnsureLoadBalancer(req)
+		if rsp == nil {
+			rsp = &webhooks.EnsureLoadBalancerResponse{}
+		}
+		return rsp, normalizeRetryResponse(&rsp.ResponseForFailRetryHooks, err)
+	}))
+	mux.HandleFunc("/"+webhooks.DeleteLoadBalancer, serveWebhook(webhooks.DeleteLoadBalancer, func(body []byte) (interface{}, error) {
+		req := &webhooks.DeleteLoadBalancerRequest{}
+		if err := decodeRetryRequest(body, req, &req.RequestMeta, &req.RequestForRetryHooks); err != nil {
+			return nil, err
+		}
+		rsp, err := driver.DeleteLoadBalancer(req)
+		if rsp == nil {
+			rsp = &webhooks.DeleteLoadBalancerResponse{}
+		}
+		return rsp, normalizeRetryResponse(&rsp.ResponseForFailRetryHooks, err)
+	}))
+	mux.HandleFunc("/"+webhooks.ValidateBackend, serveWebhook(webhooks.ValidateBackend, func(body []byte) (interface{}, error) {
+		req := &webhooks.ValidateBackendRequest{}
+		if err := decodeRequest(body, req, &req.RequestMeta); err != nil {
+			return nil, err
+		}
+		if err := validateOperation(req.Operation); err != nil {
+			return nil, err
+		}
+		rsp, err := driver.ValidateBackend(req)
+		if rsp == nil {
+			rsp = &webhooks.ValidateBackendResponse{Succ: err == nil}
+		}
+		return rsp, normalizeNoRetryResponse(&rsp.ResponseForNoRetryHooks, err)
+	}))
+	mux.HandleFunc("/"+webhooks.GenerateBackendAddr, serveWebhook(webhooks.GenerateBackendAddr, func(body []byte) (interface{}, error) {
+		req := &webhooks.GenerateBackendAddrRequest{}
+		if err := decodeRetryRequest(body, req, &req.RequestMeta, &req.RequestForRetryHooks); err != nil {
+			return nil, err
+		}
+		if (req.PodBackend == nil) == (req.ServiceBackend == nil) {
+			return nil, badRequestError{fmt.Errorf("exactly one of podBackend and serviceBackend must be specified")}
+		}
+		rsp, err := driver.GenerateBackendAddr(req)
+		if rsp == nil {
+			rsp = &webhooks.GenerateBackendAddrResponse{}
+		}
+		if err := normalizeRetryResponse(&rsp.ResponseForFailRetryHooks, err); err != nil {
+			return nil, err
+		}
+		if rsp.Status == webhooks.StatusSucc && rsp.BackendAddr == "" {
+			rsp.Status = webhooks.StatusFail
+			rsp.Msg = "driver returned empty backendAddr"
+		}
+		return rsp, nil
+	}))
+	mux.HandleFunc("/"+webhooks.EnsureBackend, serveWebhook(webhooks.EnsureBackend, func(body []byte) (interface{}, error) {
+		req := &webhooks.BackendOperationRequest{}
+		if err := decodeBackendOperationRequest(body, req); err != nil {
+			return nil, err
+		}
+		rsp, err := driver.EnsureBackend(req)
+		if rsp == nil {
+			rsp = &webhooks.BackendOperationResponse{}
+		}
+		return rsp, normalizeRetryResponse(&rsp.ResponseForFailRetryHooks, err)
+	}))
+	mux.HandleFunc("/"+webhooks.DeregBackend, serveWebhook(webhooks.DeregBackend, func(body []byte) (interface{}, error) {
+		req := &webhooks.BackendOperationRequest{}
+		if err := decodeBackendOperationRequest(body, req); err != nil {
+			return nil, err
+		}
+		rsp, err := driver.DeregisterBackend(req)
+		if rsp == nil {
+			rsp = &webhooks.BackendOperationResponse{}
+		}
+		return rsp, normalizeRetryResponse(&rsp.ResponseForFailRetryHooks, err)
+	}))
+	if drainer, ok := driver.(Drainer); ok {
+		mux.HandleFunc("/"+webhooks.DrainBackend, serveWebhook(webhooks.DrainBackend, func(body []byte) (interface{}, error) {
+			req := &webhooks.BackendOperationRequest{}
+			if err := decodeBackendOperationRequest(body, req); err != nil {
+				return nil, err
+			}
+			rsp, err := drainer.DrainBackend(req)
+			if rsp == nil {
+				rsp = &webhooks.BackendOperationResponse{}
+			}
+			return rsp, normalizeRetryResponse(&rsp.ResponseForFailRetryHooks, err)
+		}))
+	}
+	mux.HandleFunc(HealthzPath, func(w http.ResponseWriter, r *http.Request) {
+		if checker, ok := driver.(HealthChecker); ok {
+			if err := checker.HealthCheck(); err != nil {
+				http.Error(w, err.Error(), http.StatusServiceUnavailable)
+				return
+			}
+		}
+		w.Write([]byte("ok"))
+	})
+	return mux
+}
+
+// badRequestError indicates the request is invalid, it is responded with http status 400
+type badRequestError struct {
+	error
+}
+
+func serveWebhook(name string, handle func(body []byte) (interface{}, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		rsp, err := handle(body)
+		if err != nil {
+			switch err.(type) {
+			case badRequestError:
+				klog.Warningf("webhook %s, invalid request: %v", name, err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				if err == ErrNotImplemented {
+					http.Error(w, err.Error(), http.StatusNotImplemented)
+					return
+				}
+				klog.Errorf("webhook %s, err: %v", name, err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(rsp); err != nil {
+			klog.Errorf("webhook %s, write response failed: %v", name, err)
+		}
+	}
+}
+
+func decodeRequest(body []byte, req interface{}, meta *webhooks.RequestMeta) error {
+	if err := json.Unmarshal(body, req); err != nil {
+		return badRequestError{fmt.Errorf("decode request failed: %v", err)}
+	}
+	if !webhooks.IsProtocolVersionSupported(meta.ProtocolVersion) {
+		return badRequestError{fmt.Errorf("unsupported protocol version %q", meta.ProtocolVersion)}
+	}
+	return nil
+}
+
+func decodeRetryRequest(body []byte, req interface{}, meta *webhooks.RequestMeta, retry *webhooks.RequestForRetryHooks) error {
+	if err := decodeRequest(body, req, meta); err != nil {
+		return err
+	}
+	if retry.RecordID == "" {
+		return badRequestError{fmt.Errorf("recordID is required")}
+	}
+	return nil
+}
+
+func decodeBackendOperationRequest(body []byte, req *webhooks.BackendOperationRequest) error {
+	if err := decodeRetryRequest(body, req, &req.RequestMeta, &req.RequestForRetryHooks); err != nil {
+		return err
+	}
+	if req.BackendAddr == "" {
+		return badRequestError{fmt.Errorf("backendAddr is required")}
+	}
+	return nil
+}
+
+func validateOperation(op webhooks.OperationType) error {
+	if op != webhooks.OperationCreate && op != webhooks.OperationUpdate {
+		return badRequestError{fmt.Errorf("unknown operation %q", op)}
+	}
+	return nil
+}
+
+// normalizeNoRetryResponse converts err returned by driver into rsp, ErrNotImplemented is returned as it is
+func normalizeNoRetryResponse(rsp *webhooks.ResponseForNoRetryHooks, err error) error {
+	if err == ErrNotImplemented {
+		return err
+	}
+	if err != nil {
+		rsp.Succ = false
+		rsp.Msg = err.Error()
+	}
+	return nil
+}
+
+// normalizeRetryResponse converts err returned by driver into rsp, ErrNotImplemented is returned as it is
+func normalizeRetryResponse(rsp *webhooks.ResponseForFailRetryHooks, err error) error {
+	if err == ErrNotImplemented {
+		return err
+	}
+	if err != nil {
+		rsp.Status = webhooks.StatusFail
+		rsp.Msg = err.Error()
+		return nil
+	}
+	switch rsp.Status {
+	case "":
+		rsp.Status = webhooks.StatusSucc
+	case webhooks.StatusSucc, webhooks.StatusFail, webhooks.StatusRunning:
+	default:
+		rsp.Msg = fmt.Sprintf("driver returned unknown status %q, msg: %s", rsp.Status, rsp.Msg)
+		rsp.Status = webhooks.StatusFail
+	}
+	if rsp.MinRetryDelayInSeconds < 0 {
+		rsp.MinRetryDelayInSeconds = 0
+	}
+	return nil
+}
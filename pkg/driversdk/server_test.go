@@ -0,0 +1,239 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package driversdk
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+)
+
+type testDriver struct {
+	UnimplementedDriver
+	createErr    error
+	createStatus string
+}
+
+func (d *testDriver) CreateLoadBalancer(req *webhooks.CreateLoadBalancerRequest) (*webhooks.CreateLoadBalancerResponse, error) {
+	if d.createErr != nil {
+		return nil, d.createErr
+	}
+	rsp := &webhooks.CreateLoadBalancerResponse{LBInfo: map[string]string{"id": req.RecordID}}
+	rsp.Status = d.createStatus
+	return rsp, nil
+}
+
+func (d *testDriver) ValidateBackend(req *webhooks.ValidateBackendRequest) (*webhooks.ValidateBackendResponse, error) {
+	if req.Parameters["invalid"] != "" {
+		return nil, fmt.Errorf("invalid parameters")
+	}
+	return nil, nil
+}
+
+func (d *testDriver) GenerateBackendAddr(req *webhooks.GenerateBackendAddrRequest) (*webhooks.GenerateBackendAddrResponse, error) {
+	return nil, nil
+}
+
+func TestHandlerRetryHooks(t *testing.T) {
+	driver := &testDriver{}
+	server := httptest.NewServer(NewHandler(driver))
+	defer server.Close()
+	client := NewClient(server.URL, nil)
+
+	req := &webhooks.CreateLoadBalancerRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "record"},
+	}
+	rsp, err := client.CreateLoadBalancer(req)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if rsp.Status != webhooks.StatusSucc {
+		t.Fatalf("expect status %s, get %s", webhooks.StatusSucc, rsp.Status)
+	}
+	if rsp.LBInfo["id"] != "record" {
+		t.Fatalf("expect lbInfo id record, get %v", rsp.LBInfo)
+	}
+
+	driver.createStatus = "Unknown"
+	rsp, err = client.CreateLoadBalancer(req)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if rsp.Status != webhooks.StatusFail {
+		t.Fatalf("expect status %s, get %s", webhooks.StatusFail, rsp.Status)
+	}
+
+	driver.createErr = fmt.Errorf("fake error")
+	rsp, err = client.CreateLoadBalancer(req)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if rsp.Status != webhooks.StatusFail || rsp.Msg != "fake error" {
+		t.Fatalf("expect status %s with msg, get %s, msg: %s", webhooks.StatusFail, rsp.Status, rsp.Msg)
+	}
+
+	// succeeded generateBackendAddr must return backendAddr
+	addrRsp, err := client.GenerateBackendAddr(&webhooks.GenerateBackendAddrRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "record"},
+		PodBackend:           &webhooks.PodBackendInGenerateAddrRequest{},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if addrRsp.Status != webhooks.StatusFail {
+		t.Fatalf("expect status %s, get %s", webhooks.StatusFail, addrRsp.Status)
+	}
+}
+
+func TestHandlerValidateHooks(t *testing.T) {
+	server := httptest.NewServer(NewHandler(&testDriver{}))
+	defer server.Close()
+	client := NewClient(server.URL, nil)
+
+	rsp, err := client.ValidateBackend(&webhooks.ValidateBackendRequest{Operation: webhooks.OperationCreate})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if !rsp.Succ {
+		t.Fatalf("expect succ, get msg: %s", rsp.Msg)
+	}
+
+	rsp, err = client.ValidateBackend(&webhooks.ValidateBackendRequest{
+		Operation:  webhooks.OperationCreate,
+		Parameters: map[string]string{"invalid": "true"},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if rsp.Succ || rsp.Msg != "invalid parameters" {
+		t.Fatalf("expect fail with msg, get succ: %v, msg: %s", rsp.Succ, rsp.Msg)
+	}
+}
+
+func TestHandlerInvalidRequest(t *testing.T) {
+	server := httptest.NewServer(NewHandler(&testDriver{}))
+	defer server.Close()
+	client := NewClient(server.URL, nil)
+
+	cases := []struct {
+		name string
+		call func() error
+	}{
+		{
+			name: "missing-record-id",
+			call: func() error {
+				_, err := client.CreateLoadBalancer(&webhooks.CreateLoadBalancerRequest{})
+				return err
+			},
+		},
+		{
+			name: "unsupported-protocol-version",
+			call: func() error {
+				req := &webhooks.CreateLoadBalancerRequest{
+					RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "record"},
+				}
+				req.SetProtocolVersion("v100")
+				_, err := client.CreateLoadBalancer(req)
+				return err
+			},
+		},
+		{
+			name: "unknown-operation",
+			call: func() error {
+				_, err := client.ValidateBackend(&webhooks.ValidateBackendRequest{})
+				return err
+			},
+		},
+		{
+			name: "no-backend",
+			call: func() error {
+				_, err := client.GenerateBackendAddr(&webhooks.GenerateBackendAddrRequest{
+					RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "record"},
+				})
+				return err
+			},
+		},
+		{
+			name: "missing-backend-addr",
+			call: func() error {
+				_, err := client.EnsureBackend(&webhooks.BackendOperationRequest{
+					RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "record"},
+				})
+				return err
+			},
+		},
+	}
+	for _, c := range cases {
+		err := c.call()
+		statusErr, ok := err.(*StatusError)
+		if !ok || statusErr.StatusCode != http.StatusBadRequest {
+			t.Errorf("case %s, expect http status 400, get err: %v", c.name, err)
+		}
+	}
+}
+
+func TestHandlerNotImplemented(t *testing.T) {
+	server := httptest.NewServer(NewHandler(&testDriver{}))
+	defer server.Close()
+	client := NewClient(server.URL, nil)
+
+	_, err := client.EnsureLoadBalancer(&webhooks.EnsureLoadBalancerRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "record"},
+	})
+	if !IsNotImplemented(err) {
+		t.Fatalf("expect not implemented, get err: %v", err)
+	}
+	if err := client.HealthCheck(); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+}
+
+type drainingDriver struct {
+	testDriver
+}
+
+func (d *drainingDriver) DrainBackend(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	rsp := &webhooks.BackendOperationResponse{}
+	rsp.Status = webhooks.StatusRunning
+	return rsp, nil
+}
+
+func TestHandlerDrainBackend(t *testing.T) {
+	req := &webhooks.BackendOperationRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "record"},
+		BackendAddr:          "1.1.1.1:80",
+	}
+
+	server := httptest.NewServer(NewHandler(&testDriver{}))
+	defer server.Close()
+	if _, err := NewClient(server.URL, nil).DrainBackend(req); err == nil {
+		t.Fatalf("expect err for driver not implementing Drainer")
+	}
+
+	drainServer := httptest.NewServer(NewHandler(&drainingDriver{}))
+	defer drainServer.Close()
+	rsp, err := NewClient(drainServer.URL, nil).DrainBackend(req)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if rsp.Status != webhooks.StatusRunning {
+		t.Fatalf("expect status %s, get %s", webhooks.StatusRunning, rsp.Status)
+	}
+}
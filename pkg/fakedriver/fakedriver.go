@@ -0,0 +1,468 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package fakedriver implements all LBCF webhooks against an in-memory load balancer model.
+// It is intended for local development and e2e tests, no real load balancer is operated.
+package fakedriver
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"tkestack.io/lb-controlling-framework/pkg/driversdk"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/klog"
+)
+
+const (
+	// LBIDKey is the key of load balancer ID in lbInfo.
+	// If it is specified in lbSpec, createLoadBalancer uses it instead of generating a new one
+	LBIDKey = "lbID"
+
+	// InspectPath returns all load balancers and their backends in JSON
+	InspectPath = "/fake/loadbalancers"
+
+	// BehaviorPath returns the current Behavior on GET, and replaces it on PUT
+	BehaviorPath = "/fake/behavior"
+
+	// HealthzPath is the default health check path of LoadBalancerDriver
+	HealthzPath = driversdk.HealthzPath
+)
+
+// Behavior controls how the fake driver responds
+type Behavior struct {
+	// Latency is added before responding to each webhook call
+	Latency time.Duration `json:"latency"`
+
+	// RunningPhases is the number of Running responses returned for an operation before it is executed,
+	// operations are identified by recordID, so that retries of the same operation share the count
+	RunningPhases int `json:"runningPhases"`
+
+	// DrainRunningPhases is the number of Running responses returned by drainBackend before the backend is drained,
+	// it is counted separately from RunningPhases, and retries of the same backend share the count
+	DrainRunningPhases int `json:"drainRunningPhases"`
+
+	// FailRate is the probability that a webhook call fails, in range [0, 1]
+	FailRate float64 `json:"failRate"`
+
+	// FailWebhooks limits failure injection to the named webhooks, all webhooks are affected if empty
+	FailWebhooks []string `json:"failWebhooks,omitempty"`
+}
+
+// LoadBalancer is a load balancer in the in-memory model
+type LoadBalancer struct {
+	ID         string              `json:"id"`
+	LBSpec     map[string]string   `json:"lbSpec"`
+	Attributes map[string]string   `json:"attributes"`
+	Backends   map[string]*Backend `json:"backends"`
+}
+
+// Backend is a backend registered to a LoadBalancer
+type Backend struct {
+	Addr       string            `json:"addr"`
+	Parameters map[string]string `json:"parameters"`
+	// Draining is set by drainBackend, and cleared by ensureBackend
+	Draining bool `json:"draining"`
+}
+
+// Driver is a fake driver, it is safe for concurrent use
+type Driver struct {
+	lock          sync.Mutex
+	behavior      Behavior
+	loadBalancers map[string]*LoadBalancer
+	// runningPhases is the number of Running responses returned for each recordID
+	runningPhases map[string]int
+	// drainPhases is the number of Running responses returned by drainBackend for each recordID
+	drainPhases map[string]int
+	// createdBy maps recordID of createLoadBalancer to the created load balancer, so that retries are idempotent
+	createdBy map[string]string
+	nextID    int
+}
+
+// NewDriver creates a fake driver with no load balancers
+func NewDriver(behavior Behavior) *Driver {
+	return &Driver{
+		behavior:      behavior,
+		loadBalancers: make(map[string]*LoadBalancer),
+		runningPhases: make(map[string]int),
+		drainPhases:   make(map[string]int),
+		createdBy:     make(map[string]string),
+	}
+}
+
+var _ driversdk.Driver = &Driver{}
+var _ driversdk.Drainer = &Driver{}
+
+// Handler returns the http.Handler that serves webhooks with driversdk.NewHandler, as well as InspectPath and BehaviorPath.
+// Behavior.Latency is added before each webhook call, health checks are not delayed
+func (d *Driver) Handler() http.Handler {
+	webhookHandler := driversdk.NewHandler(d)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if latency := d.GetBehavior().Latency; latency > 0 && r.URL.Path != HealthzPath {
+			time.Sleep(latency)
+		}
+		webhookHandler.ServeHTTP(w, r)
+	})
+	mux.HandleFunc(InspectPath, d.serveInspect)
+	mux.HandleFunc(BehaviorPath, d.serveBehavior)
+	return mux
+}
+
+func (d *Driver) serveInspect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, d.ListLoadBalancers())
+}
+
+func (d *Driver) serveBehavior(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, d.GetBehavior())
+	case http.MethodPut:
+		behavior := Behavior{}
+		if err := json.NewDecoder(r.Body).Decode(&behavior); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		d.SetBehavior(behavior)
+		writeJSON(w, behavior)
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		klog.Errorf("write response failed: %v", err)
+	}
+}
+
+// GetBehavior returns the current Behavior
+func (d *Driver) GetBehavior() Behavior {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.behavior
+}
+
+// SetBehavior replaces the current Behavior, it takes effect on subsequent webhook calls
+func (d *Driver) SetBehavior(behavior Behavior) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.behavior = behavior
+}
+
+// ListLoadBalancers returns a copy of all load balancers sorted by ID
+func (d *Driver) ListLoadBalancers() []LoadBalancer {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var ret []LoadBalancer
+	for _, lb := range d.loadBalancers {
+		cpy := LoadBalancer{
+			ID:         lb.ID,
+			LBSpec:     copyMap(lb.LBSpec),
+			Attributes: copyMap(lb.Attributes),
+			Backends:   make(map[string]*Backend),
+		}
+		for addr, be := range lb.Backends {
+			cpy.Backends[addr] = &Backend{Addr: be.Addr, Parameters: copyMap(be.Parameters), Draining: be.Draining}
+		}
+		ret = append(ret, cpy)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].ID < ret[j].ID
+	})
+	return ret
+}
+
+// ValidateLoadBalancer accepts all LoadBalancers unless the failure is injected
+func (d *Driver) ValidateLoadBalancer(req *webhooks.ValidateLoadBalancerRequest) (*webhooks.ValidateLoadBalancerResponse, error) {
+	rsp := &webhooks.ValidateLoadBalancerResponse{}
+	if d.injectFailure(webhooks.ValidateLoadBalancer) {
+		rsp.Msg = "injected failure"
+		return rsp, nil
+	}
+	rsp.Succ = true
+	return rsp, nil
+}
+
+// CreateLoadBalancer creates a load balancer, the ID is taken from lbSpec if specified
+func (d *Driver) CreateLoadBalancer(req *webhooks.CreateLoadBalancerRequest) (*webhooks.CreateLoadBalancerResponse, error) {
+	rsp := &webhooks.CreateLoadBalancerResponse{}
+	if d.precheck(webhooks.CreateLoadBalancer, req.RecordID, &rsp.ResponseForFailRetryHooks) {
+		return rsp, nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	id := req.LBSpec[LBIDKey]
+	if id == "" {
+		// the same recordID is used when createLoadBalancer is retried
+		id = d.createdBy[req.RecordID]
+	}
+	if id == "" {
+		d.nextID++
+		id = "lb-" + strconv.Itoa(d.nextID)
+	}
+	if _, ok := d.loadBalancers[id]; !ok {
+		d.createdBy[req.RecordID] = id
+		d.loadBalancers[id] = &LoadBalancer{
+			ID:         id,
+			LBSpec:     copyMap(req.LBSpec),
+			Attributes: copyMap(req.Attributes),
+			Backends:   make(map[string]*Backend),
+		}
+	}
+	rsp.Status = webhooks.StatusSucc
+	rsp.LBInfo = map[string]string{LBIDKey: id}
+	return rsp, nil
+}
+
+// EnsureLoadBalancer updates attributes of the load balancer
+func (d *Driver) EnsureLoadBalancer(req *webhooks.EnsureLoadBalancerRequest) (*webhooks.EnsureLoadBalancerResponse, error) {
+	rsp := &webhooks.EnsureLoadBalancerResponse{}
+	if d.precheck(webhooks.EnsureLoadBalancer, req.RecordID, &rsp.ResponseForFailRetryHooks) {
+		return rsp, nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	lb, ok := d.loadBalancers[req.LBInfo[LBIDKey]]
+	if !ok {
+		rsp.Status = webhooks.StatusFail
+		rsp.Msg = fmt.Sprintf("load balancer %q not found", req.LBInfo[LBIDKey])
+		return rsp, nil
+	}
+	lb.Attributes = copyMap(req.Attributes)
+	rsp.Status = webhooks.StatusSucc
+	return rsp, nil
+}
+
+// DeleteLoadBalancer deletes the load balancer and all its backends, it succeeds if the load balancer does not exist
+func (d *Driver) DeleteLoadBalancer(req *webhooks.DeleteLoadBalancerRequest) (*webhooks.DeleteLoadBalancerResponse, error) {
+	rsp := &webhooks.DeleteLoadBalancerResponse{}
+	if d.precheck(webhooks.DeleteLoadBalancer, req.RecordID, &rsp.ResponseForFailRetryHooks) {
+		return rsp, nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	delete(d.loadBalancers, req.LBInfo[LBIDKey])
+	rsp.Status = webhooks.StatusSucc
+	return rsp, nil
+}
+
+// ValidateBackend accepts all BackendGroups unless the failure is injected
+func (d *Driver) ValidateBackend(req *webhooks.ValidateBackendRequest) (*webhooks.ValidateBackendResponse, error) {
+	rsp := &webhooks.ValidateBackendResponse{}
+	if d.injectFailure(webhooks.ValidateBackend) {
+		rsp.Msg = "injected failure"
+		return rsp, nil
+	}
+	rsp.Succ = true
+	return rsp, nil
+}
+
+// GenerateBackendAddr returns podIP:port for pods, and nodeIP:nodePort for services
+func (d *Driver) GenerateBackendAddr(req *webhooks.GenerateBackendAddrRequest) (*webhooks.GenerateBackendAddrResponse, error) {
+	rsp := &webhooks.GenerateBackendAddrResponse{}
+	if d.precheck(webhooks.GenerateBackendAddr, req.RecordID, &rsp.ResponseForFailRetryHooks) {
+		return rsp, nil
+	}
+
+	var addr string
+	var err error
+	if req.PodBackend != nil {
+		addr, err = podAddr(req.PodBackend)
+	} else if req.ServiceBackend != nil {
+		addr, err = serviceAddr(req.ServiceBackend)
+	} else {
+		err = fmt.Errorf("unknown backend type")
+	}
+	if err != nil {
+		rsp.Status = webhooks.StatusFail
+		rsp.Msg = err.Error()
+		return rsp, nil
+	}
+	rsp.Status = webhooks.StatusSucc
+	rsp.BackendAddr = addr
+	return rsp, nil
+}
+
+func podAddr(backend *webhooks.PodBackendInGenerateAddrRequest) (string, error) {
+	if backend.Pod.Status.PodIP == "" {
+		return "", fmt.Errorf("pod %s/%s has no IP", backend.Pod.Namespace, backend.Pod.Name)
+	}
+	return net.JoinHostPort(backend.Pod.Status.PodIP, strconv.Itoa(int(backend.Port.PortNumber))), nil
+}
+
+func serviceAddr(backend *webhooks.ServiceBackendInGenerateAddrRequest) (string, error) {
+	var nodePort int32
+	for _, p := range backend.Service.Spec.Ports {
+		if p.Port == backend.Port.PortNumber && (backend.Port.Protocol == "" || string(p.Protocol) == backend.Port.Protocol) {
+			nodePort = p.NodePort
+			break
+		}
+	}
+	if nodePort == 0 {
+		return "", fmt.Errorf("no NodePort found for port %d of service %s/%s", backend.Port.PortNumber, backend.Service.Namespace, backend.Service.Name)
+	}
+	for _, addr := range backend.NodeAddresses {
+		if addr.Type == v1.NodeInternalIP {
+			return net.JoinHostPort(addr.Address, strconv.Itoa(int(nodePort))), nil
+		}
+	}
+	return "", fmt.Errorf("node %s has no InternalIP", backend.NodeName)
+}
+
+// EnsureBackend registers the backend to the load balancer, parameters are updated if it is already registered
+func (d *Driver) EnsureBackend(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	rsp := &webhooks.BackendOperationResponse{}
+	if d.precheck(webhooks.EnsureBackend, req.RecordID, &rsp.ResponseForFailRetryHooks) {
+		return rsp, nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	lb, ok := d.loadBalancers[req.LBInfo[LBIDKey]]
+	if !ok {
+		rsp.Status = webhooks.StatusFail
+		rsp.Msg = fmt.Sprintf("load balancer %q not found", req.LBInfo[LBIDKey])
+		return rsp, nil
+	}
+	lb.Backends[req.BackendAddr] = &Backend{
+		Addr:       req.BackendAddr,
+		Parameters: copyMap(req.Parameters),
+	}
+	rsp.Status = webhooks.StatusSucc
+	return rsp, nil
+}
+
+// DeregisterBackend removes the backend from the load balancer, it succeeds if either of them does not exist
+func (d *Driver) DeregisterBackend(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	rsp := &webhooks.BackendOperationResponse{}
+	if d.precheck(webhooks.DeregBackend, req.RecordID, &rsp.ResponseForFailRetryHooks) {
+		return rsp, nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if lb, ok := d.loadBalancers[req.LBInfo[LBIDKey]]; ok {
+		delete(lb.Backends, req.BackendAddr)
+	}
+	delete(d.drainPhases, req.RecordID)
+	rsp.Status = webhooks.StatusSucc
+	return rsp, nil
+}
+
+// DrainBackend sets the backend draining, and returns Running for DrainRunningPhases times before Succ.
+// It succeeds if either the load balancer or the backend does not exist
+func (d *Driver) DrainBackend(req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	rsp := &webhooks.BackendOperationResponse{}
+	if d.injectFailure(webhooks.DrainBackend) {
+		rsp.Status = webhooks.StatusFail
+		rsp.Msg = "injected failure"
+		return rsp, nil
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	lb, ok := d.loadBalancers[req.LBInfo[LBIDKey]]
+	if !ok {
+		rsp.Status = webhooks.StatusSucc
+		return rsp, nil
+	}
+	be, ok := lb.Backends[req.BackendAddr]
+	if !ok {
+		rsp.Status = webhooks.StatusSucc
+		return rsp, nil
+	}
+	// the backend stops receiving new connections once draining starts
+	be.Draining = true
+	if d.countRunningPhase(d.drainPhases, req.RecordID, d.behavior.DrainRunningPhases, &rsp.ResponseForFailRetryHooks) {
+		return rsp, nil
+	}
+	rsp.Status = webhooks.StatusSucc
+	return rsp, nil
+}
+
+// precheck returns true and fills rsp if the operation should not be executed, either because of
+// an injected failure, or because the operation is still in one of its Running phases
+func (d *Driver) precheck(name string, recordID string, rsp *webhooks.ResponseForFailRetryHooks) bool {
+	if d.injectFailure(name) {
+		rsp.Status = webhooks.StatusFail
+		rsp.Msg = "injected failure"
+		return true
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.countRunningPhase(d.runningPhases, recordID, d.behavior.RunningPhases, rsp)
+}
+
+// countRunningPhase returns true and fills rsp with Running if recordID has not gone through all phases.
+// The count is reset once the phases are done, d.lock must be held
+func (d *Driver) countRunningPhase(counts map[string]int, recordID string, phases int, rsp *webhooks.ResponseForFailRetryHooks) bool {
+	if counts[recordID] < phases {
+		counts[recordID]++
+		rsp.Status = webhooks.StatusRunning
+		rsp.Msg = fmt.Sprintf("running phase %d/%d", counts[recordID], phases)
+		return true
+	}
+	delete(counts, recordID)
+	return false
+}
+
+func (d *Driver) injectFailure(name string) bool {
+	behavior := d.GetBehavior()
+	if behavior.FailRate <= 0 {
+		return false
+	}
+	if len(behavior.FailWebhooks) > 0 {
+		found := false
+		for _, w := range behavior.FailWebhooks {
+			if w == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return rand.Float64() < behavior.FailRate
+}
+
+func copyMap(m map[string]string) map[string]string {
+	ret := make(map[string]string, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	return ret
+}
@@ -0,0 +1,238 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package fakedriver
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+
+	"k8s.io/api/core/v1"
+)
+
+func post(t *testing.T, server *httptest.Server, webhook string, req interface{}, rsp interface{}) {
+	body, _ := json.Marshal(req)
+	httpRsp, err := http.Post(server.URL+"/"+webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("call %s failed: %v", webhook, err)
+	}
+	defer httpRsp.Body.Close()
+	if httpRsp.StatusCode != http.StatusOK {
+		t.Fatalf("call %s, expect status 200, get %d", webhook, httpRsp.StatusCode)
+	}
+	if err := json.NewDecoder(httpRsp.Body).Decode(rsp); err != nil {
+		t.Fatalf("decode %s response failed: %v", webhook, err)
+	}
+}
+
+func TestDriverLifecycle(t *testing.T) {
+	driver := NewDriver(Behavior{})
+	server := httptest.NewServer(driver.Handler())
+	defer server.Close()
+
+	createRsp := &webhooks.CreateLoadBalancerResponse{}
+	createReq := &webhooks.CreateLoadBalancerRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "lb-record"},
+		LBSpec:               map[string]string{"vip": "1.1.1.1"},
+	}
+	post(t, server, webhooks.CreateLoadBalancer, createReq, createRsp)
+	if createRsp.Status != webhooks.StatusSucc {
+		t.Fatalf("expect status %s, get %s, msg: %s", webhooks.StatusSucc, createRsp.Status, createRsp.Msg)
+	}
+	lbID := createRsp.LBInfo[LBIDKey]
+	if lbID == "" {
+		t.Fatalf("expect lbID in lbInfo, get %v", createRsp.LBInfo)
+	}
+
+	// retry of the same operation must not create another load balancer
+	retryRsp := &webhooks.CreateLoadBalancerResponse{}
+	post(t, server, webhooks.CreateLoadBalancer, createReq, retryRsp)
+	if retryRsp.LBInfo[LBIDKey] != lbID {
+		t.Fatalf("expect lbID %s, get %s", lbID, retryRsp.LBInfo[LBIDKey])
+	}
+
+	addrRsp := &webhooks.GenerateBackendAddrResponse{}
+	post(t, server, webhooks.GenerateBackendAddr, &webhooks.GenerateBackendAddrRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "addr-record"},
+		PodBackend: &webhooks.PodBackendInGenerateAddrRequest{
+			Pod: v1.Pod{
+				Status: v1.PodStatus{PodIP: "10.0.0.1"},
+			},
+			Port: v1beta1.PortSelector{PortNumber: 80},
+		},
+	}, addrRsp)
+	if addrRsp.BackendAddr != "10.0.0.1:80" {
+		t.Fatalf("expect addr 10.0.0.1:80, get %s", addrRsp.BackendAddr)
+	}
+
+	ensureRsp := &webhooks.BackendOperationResponse{}
+	post(t, server, webhooks.EnsureBackend, &webhooks.BackendOperationRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "be-record"},
+		LBInfo:               createRsp.LBInfo,
+		BackendAddr:          addrRsp.BackendAddr,
+		Parameters:           map[string]string{"weight": "10"},
+	}, ensureRsp)
+	if ensureRsp.Status != webhooks.StatusSucc {
+		t.Fatalf("expect status %s, get %s, msg: %s", webhooks.StatusSucc, ensureRsp.Status, ensureRsp.Msg)
+	}
+
+	httpRsp, err := http.Get(server.URL + InspectPath)
+	if err != nil {
+		t.Fatalf("inspect failed: %v", err)
+	}
+	var lbs []LoadBalancer
+	if err := json.NewDecoder(httpRsp.Body).Decode(&lbs); err != nil {
+		t.Fatalf("decode inspect response failed: %v", err)
+	}
+	httpRsp.Body.Close()
+	if len(lbs) != 1 || lbs[0].ID != lbID {
+		t.Fatalf("expect only load balancer %s, get %+v", lbID, lbs)
+	}
+	if be, ok := lbs[0].Backends["10.0.0.1:80"]; !ok || be.Parameters["weight"] != "10" {
+		t.Fatalf("expect backend 10.0.0.1:80 registered, get %+v", lbs[0].Backends)
+	}
+
+	deregRsp := &webhooks.BackendOperationResponse{}
+	post(t, server, webhooks.DeregBackend, &webhooks.BackendOperationRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "be-record"},
+		LBInfo:               createRsp.LBInfo,
+		BackendAddr:          addrRsp.BackendAddr,
+	}, deregRsp)
+	if lbs := driver.ListLoadBalancers(); len(lbs[0].Backends) != 0 {
+		t.Fatalf("expect no backends, get %+v", lbs[0].Backends)
+	}
+
+	deleteRsp := &webhooks.DeleteLoadBalancerResponse{}
+	post(t, server, webhooks.DeleteLoadBalancer, &webhooks.DeleteLoadBalancerRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "lb-record"},
+		LBInfo:               createRsp.LBInfo,
+	}, deleteRsp)
+	if deleteRsp.Status != webhooks.StatusSucc {
+		t.Fatalf("expect status %s, get %s, msg: %s", webhooks.StatusSucc, deleteRsp.Status, deleteRsp.Msg)
+	}
+	if lbs := driver.ListLoadBalancers(); len(lbs) != 0 {
+		t.Fatalf("expect no load balancers, get %+v", lbs)
+	}
+}
+
+func TestDriverRunningPhases(t *testing.T) {
+	driver := NewDriver(Behavior{RunningPhases: 2})
+	req := &webhooks.CreateLoadBalancerRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "lb-record"},
+	}
+	for i := 0; i < 2; i++ {
+		if rsp, _ := driver.CreateLoadBalancer(req); rsp.Status != webhooks.StatusRunning {
+			t.Fatalf("call %d, expect status %s, get %s", i, webhooks.StatusRunning, rsp.Status)
+		}
+	}
+	if rsp, _ := driver.CreateLoadBalancer(req); rsp.Status != webhooks.StatusSucc {
+		t.Fatalf("expect status %s, get %s", webhooks.StatusSucc, rsp.Status)
+	}
+}
+
+func TestDriverFailureInjection(t *testing.T) {
+	driver := NewDriver(Behavior{
+		FailRate:     1,
+		FailWebhooks: []string{webhooks.EnsureBackend, webhooks.ValidateBackend},
+	})
+	if rsp, _ := driver.ValidateBackend(&webhooks.ValidateBackendRequest{}); rsp.Succ {
+		t.Fatalf("expect validateBackend fail")
+	}
+	createRsp, _ := driver.CreateLoadBalancer(&webhooks.CreateLoadBalancerRequest{})
+	if createRsp.Status != webhooks.StatusSucc {
+		t.Fatalf("expect status %s, get %s", webhooks.StatusSucc, createRsp.Status)
+	}
+	if rsp, _ := driver.EnsureBackend(&webhooks.BackendOperationRequest{LBInfo: createRsp.LBInfo}); rsp.Status != webhooks.StatusFail {
+		t.Fatalf("expect status %s, get %s", webhooks.StatusFail, rsp.Status)
+	}
+
+	driver.SetBehavior(Behavior{})
+	if rsp, _ := driver.EnsureBackend(&webhooks.BackendOperationRequest{LBInfo: createRsp.LBInfo}); rsp.Status != webhooks.StatusSucc {
+		t.Fatalf("expect status %s, get %s", webhooks.StatusSucc, rsp.Status)
+	}
+}
+
+func TestDriverDrainBackend(t *testing.T) {
+	driver := NewDriver(Behavior{DrainRunningPhases: 2})
+	server := httptest.NewServer(driver.Handler())
+	defer server.Close()
+
+	createRsp, _ := driver.CreateLoadBalancer(&webhooks.CreateLoadBalancerRequest{})
+	req := &webhooks.BackendOperationRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "be-record"},
+		LBInfo:               createRsp.LBInfo,
+		BackendAddr:          "10.0.0.1:80",
+	}
+	if rsp, _ := driver.EnsureBackend(req); rsp.Status != webhooks.StatusSucc {
+		t.Fatalf("expect status %s, get %s", webhooks.StatusSucc, rsp.Status)
+	}
+	for i := 0; i < 2; i++ {
+		rsp := &webhooks.BackendOperationResponse{}
+		post(t, server, webhooks.DrainBackend, req, rsp)
+		if rsp.Status != webhooks.StatusRunning {
+			t.Fatalf("call %d, expect status %s, get %s", i, webhooks.StatusRunning, rsp.Status)
+		}
+	}
+	if be := driver.ListLoadBalancers()[0].Backends[req.BackendAddr]; !be.Draining {
+		t.Fatalf("expect backend draining")
+	}
+	if rsp, _ := driver.DrainBackend(req); rsp.Status != webhooks.StatusSucc {
+		t.Fatalf("expect status %s, get %s", webhooks.StatusSucc, rsp.Status)
+	}
+
+	// ensureBackend cancels draining
+	driver.EnsureBackend(req)
+	if be := driver.ListLoadBalancers()[0].Backends[req.BackendAddr]; be.Draining {
+		t.Fatalf("expect backend not draining")
+	}
+
+	// draining a backend that is already deregistered succeeds
+	driver.DeregisterBackend(req)
+	if rsp, _ := driver.DrainBackend(req); rsp.Status != webhooks.StatusSucc {
+		t.Fatalf("expect status %s, get %s", webhooks.StatusSucc, rsp.Status)
+	}
+}
+
+func TestDriverInvalidRequest(t *testing.T) {
+	server := httptest.NewServer(NewDriver(Behavior{}).Handler())
+	defer server.Close()
+
+	// requests are validated by driversdk before they reach the driver
+	body, _ := json.Marshal(&webhooks.CreateLoadBalancerRequest{})
+	httpRsp, err := http.Post(server.URL+"/"+webhooks.CreateLoadBalancer, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("call %s failed: %v", webhooks.CreateLoadBalancer, err)
+	}
+	httpRsp.Body.Close()
+	if httpRsp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect status 400, get %d", httpRsp.StatusCode)
+	}
+
+	httpRsp, err = http.Get(server.URL + HealthzPath)
+	if err != nil {
+		t.Fatalf("health check failed: %v", err)
+	}
+	httpRsp.Body.Close()
+	if httpRsp.StatusCode != http.StatusOK {
+		t.Fatalf("expect status 200, get %d", httpRsp.StatusCode)
+	}
+}
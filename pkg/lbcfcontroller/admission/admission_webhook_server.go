@@ -18,8 +18,10 @@
 package admission
 
 import (
+	gocontext "context"
 	"fmt"
 	"net/http"
+	"time"
 
 	"tkestack.io/lb-controlling-framework/cmd/lbcf-controller/app/context"
 	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/util"
@@ -32,9 +34,15 @@ import (
 
 // NewWebhookServer creates a new Server
 func NewWebhookServer(context *context.Context, crtFile string, keyFile string) *Server {
+	invoker := util.NewWebhookInvokerWithConfig(util.WebhookInvokerConfig{
+		SecretLister:    context.SecretLister,
+		ServiceLister:   context.SvcInformer.Lister(),
+		EndpointsLister: context.EndpointsInformer.Lister(),
+		ExecDriverDir:   context.Cfg.ExecDriverDir,
+	})
 	s := &Server{
 		context:      context,
-		admitWebhook: NewAdmitter(context.LBInformer.Lister(), context.LBDriverInformer.Lister(), context.BRInformer.Lister(), util.NewWebhookInvoker()),
+		admitWebhook: NewAdmitter(context.LBInformer.Lister(), context.LBDriverInformer.Lister(), context.BRInformer.Lister(), context.BGInformer.Lister(), invoker, context.Cfg.ExecDriverDir),
 		crtFile:      crtFile,
 		keyFile:      keyFile,
 	}
@@ -47,10 +55,11 @@ type Server struct {
 	admitWebhook Webhook
 	crtFile      string
 	keyFile      string
+	httpServer   *http.Server
 }
 
-// Start starts the server in a new goroutine
-func (s *Server) Start() {
+// Start starts the server in a new goroutine, the server starts serving after all informers are synced
+func (s *Server) Start(stopCh <-chan struct{}) {
 	ws := new(restful.WebService)
 	ws.Path("/")
 
@@ -60,6 +69,8 @@ func (s *Server) Start() {
 		Consumes(restful.MIME_JSON))
 	ws.Route(ws.POST("mutate-backend-broup").To(s.MutateAdmitBackendGroup).
 		Consumes(restful.MIME_JSON))
+	ws.Route(ws.POST("mutate-pod").To(s.MutateAdmitPod).
+		Consumes(restful.MIME_JSON))
 
 	ws.Route(ws.POST("validate-load-balancer").To(s.ValidateAdmitLoadBalancer).
 		Consumes(restful.MIME_JSON))
@@ -70,12 +81,27 @@ func (s *Server) Start() {
 
 	restful.Add(ws)
 
+	s.httpServer = &http.Server{Addr: ":443"}
 	go func() {
-		s.context.WaitForCacheSync()
-		klog.Fatal(http.ListenAndServeTLS(":443", s.crtFile, s.keyFile, nil))
+		if !s.context.WaitForCacheSync(stopCh) {
+			return
+		}
+		if err := s.httpServer.ListenAndServeTLS(s.crtFile, s.keyFile); err != http.ErrServerClosed {
+			klog.Fatal(err)
+		}
 	}()
 }
 
+// ShutDown stops the server from accepting new connections and waits for in-flight requests for at most timeout
+func (s *Server) ShutDown(timeout time.Duration) error {
+	if s.httpServer == nil {
+		return nil
+	}
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), timeout)
+	defer cancel()
+	return s.httpServer.Shutdown(ctx)
+}
+
 // ValidateAdmitLoadBalancer implements ValidatingWebHook for LoadBalancer
 func (s *Server) ValidateAdmitLoadBalancer(req *restful.Request, rsp *restful.Response) {
 	serveValidate(req, rsp, s.admitWebhook.ValidateLoadBalancerCreate, s.admitWebhook.ValidateLoadBalancerUpdate, s.admitWebhook.ValidateLoadBalancerDelete)
@@ -106,6 +132,11 @@ func (s *Server) MutateAdmitBackendGroup(req *restful.Request, rsp *restful.Resp
 	serveMutate(req, rsp, s.admitWebhook.MutateBackendGroup)
 }
 
+// MutateAdmitPod implements MutatingWebHook for Pod
+func (s *Server) MutateAdmitPod(req *restful.Request, rsp *restful.Response) {
+	serveMutate(req, rsp, s.admitWebhook.MutatePod)
+}
+
 func parseAdmissionReview(req *restful.Request, rsp *restful.Response) *v1beta1.AdmissionReview {
 	ar := &v1beta1.AdmissionReview{}
 	if err := req.ReadEntity(ar); err != nil {
@@ -28,6 +28,7 @@ import (
 	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
 
 	admission "k8s.io/api/admission/v1beta1"
+	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/klog"
@@ -59,15 +60,18 @@ type MutatingAdmissionWebhook interface {
 	MutateLB(*admission.AdmissionReview) *admission.AdmissionResponse
 	MutateDriver(*admission.AdmissionReview) *admission.AdmissionResponse
 	MutateBackendGroup(*admission.AdmissionReview) *admission.AdmissionResponse
+	MutatePod(*admission.AdmissionReview) *admission.AdmissionResponse
 }
 
 // NewAdmitter creates a new instance of Webhook
-func NewAdmitter(lbLister lbcflister.LoadBalancerLister, driverLister lbcflister.LoadBalancerDriverLister, backendLister lbcflister.BackendRecordLister, invoker util.WebhookInvoker) Webhook {
+func NewAdmitter(lbLister lbcflister.LoadBalancerLister, driverLister lbcflister.LoadBalancerDriverLister, backendLister lbcflister.BackendRecordLister, bgLister lbcflister.BackendGroupLister, invoker util.WebhookInvoker, execDriverDir string) Webhook {
 	return &Admitter{
 		lbLister:       lbLister,
 		driverLister:   driverLister,
 		backendLister:  backendLister,
+		bgLister:       bgLister,
 		webhookInvoker: invoker,
+		execDriverDir:  execDriverDir,
 	}
 }
 
@@ -76,8 +80,12 @@ type Admitter struct {
 	lbLister      lbcflister.LoadBalancerLister
 	driverLister  lbcflister.LoadBalancerDriverLister
 	backendLister lbcflister.BackendRecordLister
+	bgLister      lbcflister.BackendGroupLister
 
 	webhookInvoker util.WebhookInvoker
+
+	// execDriverDir is the directory that executables of Exec drivers must be in, Exec drivers are rejected if it is empty
+	execDriverDir string
 }
 
 // MutateLB implements MutatingWebHook for LoadBalancer
@@ -112,6 +120,7 @@ func (a *Admitter) MutateDriver(ar *admission.AdmissionReview) *admission.Admiss
 
 	dPatch := &driverPatch{obj: obj}
 	dPatch.setWebhook()
+	dPatch.setProtocolVersion()
 
 	p, err := json.Marshal(dPatch.patch())
 	if err != nil {
@@ -151,6 +160,41 @@ func (a *Admitter) MutateBackendGroup(ar *admission.AdmissionReview) *admission.
 	return reviewResponse
 }
 
+// MutatePod implements MutatingWebHook for Pod
+func (a *Admitter) MutatePod(ar *admission.AdmissionReview) *admission.AdmissionResponse {
+	obj := &v1.Pod{}
+	err := json.Unmarshal(ar.Request.Object.Raw, obj)
+	if err != nil {
+		return toAdmissionResponse(err)
+	}
+	// namespace may be absent in the object of CREATE requests
+	if obj.Namespace == "" {
+		obj.Namespace = ar.Request.Namespace
+	}
+	groups, err := a.bgLister.BackendGroups(obj.Namespace).List(labels.Everything())
+	if err != nil {
+		return toAdmissionResponse(err)
+	}
+
+	pPatch := &podPatch{obj: obj}
+	pPatch.addReadinessGate(groups)
+
+	reviewResponse := &admission.AdmissionResponse{}
+	reviewResponse.Allowed = true
+	// this webhook is called for every pod, most of which are not patched
+	if len(pPatch.patch()) == 0 {
+		return reviewResponse
+	}
+	p, err := json.Marshal(pPatch.patch())
+	if err != nil {
+		return toAdmissionResponse(err)
+	}
+	reviewResponse.Patch = p
+	pt := admission.PatchTypeJSONPatch
+	reviewResponse.PatchType = &pt
+	return reviewResponse
+}
+
 // ValidateLoadBalancerCreate implements ValidatingWebHook for LoadBalancer creating
 func (a *Admitter) ValidateLoadBalancerCreate(ar *admission.AdmissionReview) *admission.AdmissionResponse {
 	lb := &lbcfapi.LoadBalancer{}
@@ -247,6 +291,11 @@ func (a *Admitter) ValidateDriverCreate(ar *admission.AdmissionReview) *admissio
 	if len(errList) > 0 {
 		return toAdmissionResponse(fmt.Errorf("%s", errList.ToAggregate().Error()))
 	}
+	if lbcfapi.DriverType(d.Spec.DriverType) == lbcfapi.ExecDriver {
+		if _, err := util.ResolveExecCommand(a.execDriverDir, d.Spec.Exec.Command); err != nil {
+			return toAdmissionResponse(fmt.Errorf("invalid spec.exec.command: %v", err))
+		}
+	}
 
 	return toAdmissionResponse(nil)
 }
@@ -330,17 +379,19 @@ func (a *Admitter) ValidateBackendGroupCreate(ar *admission.AdmissionReview) *ad
 	} else if driver.DeletionTimestamp != nil {
 		return toAdmissionResponse(fmt.Errorf("driver %q is deleting, all BackendGroup creating operation for that dirver is denied", lb.Spec.LBDriver))
 	}
-	req := &webhooks.ValidateBackendRequest{
-		BackendType: string(util.GetBackendType(bg)),
-		LBInfo:      lb.Status.LBInfo,
-		Operation:   webhooks.OperationCreate,
-		Parameters:  bg.Spec.Parameters,
-	}
-	rsp, err := a.webhookInvoker.CallValidateBackend(driver, req)
-	if err != nil {
-		return toAdmissionResponse(fmt.Errorf("call webhook error, webhook validateBackend, err: %v", err))
-	} else if !rsp.Succ {
-		return toAdmissionResponse(fmt.Errorf("invalid Backend, msg: %v", rsp.Msg))
+	for _, params := range backendParameters(bg) {
+		req := &webhooks.ValidateBackendRequest{
+			BackendType: string(util.GetBackendType(bg)),
+			LBInfo:      lb.Status.LBInfo,
+			Operation:   webhooks.OperationCreate,
+			Parameters:  params,
+		}
+		rsp, err := a.webhookInvoker.CallValidateBackend(driver, req)
+		if err != nil {
+			return toAdmissionResponse(fmt.Errorf("call webhook error, webhook validateBackend, err: %v", err))
+		} else if !rsp.Succ {
+			return toAdmissionResponse(fmt.Errorf("invalid Backend, msg: %v", rsp.Msg))
+		}
 	}
 
 	return toAdmissionResponse(nil)
@@ -377,22 +428,65 @@ func (a *Admitter) ValidateBackendGroupUpdate(ar *admission.AdmissionReview) *ad
 		return toAdmissionResponse(fmt.Errorf("retrieve driver %s/%s failed: %v", driverNamespace, lb.Spec.LBDriver, err))
 	}
 
-	req := &webhooks.ValidateBackendRequest{
-		BackendType:   string(util.GetBackendType(curObj)),
-		LBInfo:        lb.Status.LBInfo,
-		Operation:     webhooks.OperationUpdate,
-		Parameters:    curObj.Spec.Parameters,
-		OldParameters: oldObj.Spec.Parameters,
-	}
-	rsp, err := a.webhookInvoker.CallValidateBackend(driver, req)
-	if err != nil {
-		return toAdmissionResponse(fmt.Errorf("call webhook error, webhook validateBackend, err: %v", err))
-	} else if !rsp.Succ {
-		return toAdmissionResponse(fmt.Errorf("invalid Backend, msg: %v", rsp.Msg))
+	for _, update := range backendParametersUpdate(curObj, oldObj) {
+		req := &webhooks.ValidateBackendRequest{
+			BackendType:   string(util.GetBackendType(curObj)),
+			LBInfo:        lb.Status.LBInfo,
+			Operation:     webhooks.OperationUpdate,
+			Parameters:    update.params,
+			OldParameters: update.oldParams,
+		}
+		rsp, err := a.webhookInvoker.CallValidateBackend(driver, req)
+		if err != nil {
+			return toAdmissionResponse(fmt.Errorf("call webhook error, webhook validateBackend, err: %v", err))
+		} else if !rsp.Succ {
+			return toAdmissionResponse(fmt.Errorf("invalid Backend, msg: %v", rsp.Msg))
+		}
 	}
 	return toAdmissionResponse(nil)
 }
 
+// backendParameters returns parameters of group, as well as those of ports that have their own parameters
+func backendParameters(group *lbcfapi.BackendGroup) []map[string]string {
+	ret := []map[string]string{group.Spec.Parameters}
+	for _, port := range util.BackendPorts(group) {
+		if len(port.Parameters) > 0 {
+			ret = append(ret, util.BackendParameters(group, port))
+		}
+	}
+	return ret
+}
+
+// parametersUpdate is the parameters of group or one of the ports, together with the parameters before updated
+type parametersUpdate struct {
+	params    map[string]string
+	oldParams map[string]string
+}
+
+// backendParametersUpdate returns the same parameters as backendParameters(cur), each of them is paired with the parameters
+// of the same port in old. oldParams is nil if the port is newly added
+func backendParametersUpdate(cur *lbcfapi.BackendGroup, old *lbcfapi.BackendGroup) []parametersUpdate {
+	oldPortParams := make(map[lbcfapi.PortSelector]map[string]string)
+	for _, port := range util.BackendPorts(old) {
+		oldPortParams[port.PortSelector] = util.BackendParameters(old, port)
+	}
+	ret := []parametersUpdate{
+		{
+			params:    cur.Spec.Parameters,
+			oldParams: old.Spec.Parameters,
+		},
+	}
+	for _, port := range util.BackendPorts(cur) {
+		if len(port.Parameters) > 0 {
+			ret = append(ret, parametersUpdate{
+				params:    util.BackendParameters(cur, port),
+				oldParams: oldPortParams[port.PortSelector],
+			})
+		}
+	}
+	return ret
+}
+
 // ValidateBackendGroupDelete implements ValidatingWebHook for BackendGroup deleting
 func (a *Admitter) ValidateBackendGroupDelete(*admission.AdmissionReview) *admission.AdmissionResponse {
 	return toAdmissionResponse(nil)
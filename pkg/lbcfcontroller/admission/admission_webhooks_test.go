@@ -18,11 +18,16 @@ package admission
 
 import (
 	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
 	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
 	lbcflister "tkestack.io/lb-controlling-framework/pkg/client-go/listers/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/util"
 	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
 
 	"github.com/evanphx/json-patch"
@@ -37,7 +42,7 @@ import (
 )
 
 func TestAdmitter_MutateLB(t *testing.T) {
-	a := NewAdmitter(&alwaysSuccLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+	a := NewAdmitter(&alwaysSuccLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 
 	// case 1: create finalizers array
 	lb := &lbcfapi.LoadBalancer{
@@ -96,7 +101,7 @@ func TestAdmitter_MutateLB(t *testing.T) {
 }
 
 func TestAdmitter_MutateDriver(t *testing.T) {
-	a := NewAdmitter(&alwaysSuccLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+	a := NewAdmitter(&alwaysSuccLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	driver := &lbcfapi.LoadBalancerDriver{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test-driver",
@@ -131,10 +136,14 @@ func TestAdmitter_MutateDriver(t *testing.T) {
 	if err := json.Unmarshal(modified, modifiedDriver); err != nil {
 		t.Fatalf(err.Error())
 	}
-	if len(modifiedDriver.Spec.Webhooks) != len(webhooks.KnownWebhooks) {
-		t.Errorf("expect %d webhooks, get %d", len(webhooks.KnownWebhooks), len(modifiedDriver.Spec.Webhooks))
+	if modifiedDriver.Spec.ProtocolVersion != webhooks.DefaultProtocolVersion {
+		t.Errorf("expect protocolVersion %s, get %s", webhooks.DefaultProtocolVersion, modifiedDriver.Spec.ProtocolVersion)
 	}
-	for known := range webhooks.KnownWebhooks {
+	defaultWebhooks := webhooks.KnownWebhooks.Difference(webhooks.OptInWebhooks)
+	if len(modifiedDriver.Spec.Webhooks) != len(defaultWebhooks) {
+		t.Errorf("expect %d webhooks, get %d", len(defaultWebhooks), len(modifiedDriver.Spec.Webhooks))
+	}
+	for known := range defaultWebhooks {
 		found := false
 		for _, wh := range modifiedDriver.Spec.Webhooks {
 			if wh.Name == known {
@@ -142,6 +151,9 @@ func TestAdmitter_MutateDriver(t *testing.T) {
 				if wh.Timeout.Duration != defaultWebhookTimeout {
 					t.Errorf("webhook %s expect timeout %s, get %s", wh.Name, defaultWebhookTimeout.String(), wh.Timeout.Duration.String())
 				}
+				if wh.Optional {
+					t.Errorf("webhook %s expect not optional by default", wh.Name)
+				}
 				break
 			}
 		}
@@ -200,11 +212,11 @@ func TestAdmitter_MutateDriver(t *testing.T) {
 	if err := json.Unmarshal(modified, modifiedDriver); err != nil {
 		t.Fatalf(err.Error())
 	}
-	if len(modifiedDriver.Spec.Webhooks) != len(webhooks.KnownWebhooks) {
-		t.Errorf("expect %d webhooks, get %d", len(webhooks.KnownWebhooks), len(modifiedDriver.Spec.Webhooks))
+	if len(modifiedDriver.Spec.Webhooks) != len(defaultWebhooks) {
+		t.Errorf("expect %d webhooks, get %d", len(defaultWebhooks), len(modifiedDriver.Spec.Webhooks))
 	}
 
-	for known := range webhooks.KnownWebhooks {
+	for known := range defaultWebhooks {
 		found := false
 		for _, wh := range modifiedDriver.Spec.Webhooks {
 			if wh.Name == known {
@@ -228,7 +240,7 @@ func TestAdmitter_MutateDriver(t *testing.T) {
 }
 
 func TestAdmitter_MutateBackendGroup(t *testing.T) {
-	a := NewAdmitter(&alwaysSuccLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+	a := NewAdmitter(&alwaysSuccLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	group := &lbcfapi.BackendGroup{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      "test-backendgroup",
@@ -335,6 +347,119 @@ func TestAdmitter_MutateBackendGroup(t *testing.T) {
 	}
 }
 
+func TestAdmitter_MutatePod(t *testing.T) {
+	group := &lbcfapi.BackendGroup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-backendgroup",
+			Namespace: "test",
+		},
+		Spec: lbcfapi.BackendGroupSpec{
+			LBName: "test-lb",
+			Pods: &lbcfapi.PodBackend{
+				Port: lbcfapi.PortSelector{
+					PortNumber: 80,
+				},
+				ByLabel: &lbcfapi.SelectPodByLabel{
+					Selector: map[string]string{
+						"app": "test",
+					},
+				},
+				ReadinessGate: true,
+			},
+		},
+	}
+	a := NewAdmitter(&alwaysSuccLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{
+		list: []*lbcfapi.BackendGroup{group},
+	}, &fakeSuccInvoker{}, "")
+
+	mutate := func(pod *apiv1.Pod) *apiv1.Pod {
+		raw, _ := json.Marshal(pod)
+		rsp := a.MutatePod(&v1beta1.AdmissionReview{
+			Request: &v1beta1.AdmissionRequest{
+				Namespace: "test",
+				Object: runtime.RawExtension{
+					Raw: raw,
+				},
+			},
+		})
+		if !rsp.Allowed {
+			t.Fatalf("expect always allow")
+		}
+		if len(rsp.Patch) == 0 {
+			return pod
+		}
+		patch, err := jsonpatch.DecodePatch(rsp.Patch)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		modified, err := patch.Apply(raw)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		modifiedPod := &apiv1.Pod{}
+		if err := json.Unmarshal(modified, modifiedPod); err != nil {
+			t.Fatalf(err.Error())
+		}
+		return modifiedPod
+	}
+	gate := apiv1.PodReadinessGate{
+		ConditionType: lbcfapi.PodConditionBackendsRegistered,
+	}
+
+	// pod selected by group
+	pod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pod-0",
+			Labels: map[string]string{
+				"app": "test",
+			},
+		},
+	}
+	modified := mutate(pod)
+	if !reflect.DeepEqual(modified.Spec.ReadinessGates, []apiv1.PodReadinessGate{gate}) {
+		t.Fatalf("get readinessGates %+v", modified.Spec.ReadinessGates)
+	}
+
+	// existing readinessGates are kept
+	other := apiv1.PodReadinessGate{
+		ConditionType: "other",
+	}
+	pod.Spec.ReadinessGates = []apiv1.PodReadinessGate{other}
+	modified = mutate(pod)
+	if !reflect.DeepEqual(modified.Spec.ReadinessGates, []apiv1.PodReadinessGate{other, gate}) {
+		t.Fatalf("get readinessGates %+v", modified.Spec.ReadinessGates)
+	}
+
+	// readiness gate is added only once
+	pod.Spec.ReadinessGates = []apiv1.PodReadinessGate{gate}
+	modified = mutate(pod)
+	if !reflect.DeepEqual(modified.Spec.ReadinessGates, []apiv1.PodReadinessGate{gate}) {
+		t.Fatalf("get readinessGates %+v", modified.Spec.ReadinessGates)
+	}
+
+	// pod not selected by group
+	pod = &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pod-1",
+			Labels: map[string]string{
+				"app": "other",
+			},
+		},
+	}
+	modified = mutate(pod)
+	if len(modified.Spec.ReadinessGates) != 0 {
+		t.Fatalf("get readinessGates %+v", modified.Spec.ReadinessGates)
+	}
+
+	// readinessGate is not enabled in group
+	group.Spec.Pods.ReadinessGate = false
+	pod.Labels["app"] = "test"
+	modified = mutate(pod)
+	if len(modified.Spec.ReadinessGates) != 0 {
+		t.Fatalf("get readinessGates %+v", modified.Spec.ReadinessGates)
+	}
+}
+
 func TestAdmitter_ValidateDriverCreate(t *testing.T) {
 	type testCase struct {
 		name        string
@@ -605,7 +730,7 @@ func TestAdmitter_ValidateDriverCreate(t *testing.T) {
 			expectAllow: true,
 		},
 	}
-	a := NewAdmitter(&alwaysSuccLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+	a := NewAdmitter(&alwaysSuccLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 
 	for _, c := range cases {
 		raw, _ := json.Marshal(c.driver)
@@ -622,6 +747,92 @@ func TestAdmitter_ValidateDriverCreate(t *testing.T) {
 	}
 }
 
+func TestAdmitter_ValidateDriverCreate_ExecCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lbcf-exec")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	driverDir := filepath.Join(dir, "drivers")
+	if err := os.Mkdir(driverDir, 0755); err != nil {
+		t.Fatalf("create driver dir failed: %v", err)
+	}
+	inside := filepath.Join(driverDir, "driver")
+	outside := filepath.Join(dir, "sh")
+	for _, f := range []string{inside, outside} {
+		if err := ioutil.WriteFile(f, []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatalf("write file failed: %v", err)
+		}
+	}
+	link := filepath.Join(driverDir, "link")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Fatalf("create symlink failed: %v", err)
+	}
+
+	cases := []struct {
+		name          string
+		execDriverDir string
+		command       string
+		expectAllow   bool
+	}{
+		{
+			name:          "inside-dir",
+			execDriverDir: driverDir,
+			command:       inside,
+			expectAllow:   true,
+		},
+		{
+			name:    "exec-driver-disabled",
+			command: inside,
+		},
+		{
+			name:          "outside-dir",
+			execDriverDir: driverDir,
+			command:       outside,
+		},
+		{
+			name:          "symlink-to-outside-dir",
+			execDriverDir: driverDir,
+			command:       link,
+		},
+		{
+			name:          "escape-by-dot-dot",
+			execDriverDir: driverDir,
+			command:       filepath.Join(driverDir, "..", "sh"),
+		},
+	}
+	for _, c := range cases {
+		driver := &lbcfapi.LoadBalancerDriver{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-driver",
+				Namespace: "default",
+			},
+			Spec: lbcfapi.LoadBalancerDriverSpec{
+				DriverType: string(lbcfapi.ExecDriver),
+				Exec:       &lbcfapi.DriverExecConfig{Command: c.command},
+			},
+		}
+		for known := range webhooks.KnownWebhooks {
+			driver.Spec.Webhooks = append(driver.Spec.Webhooks, lbcfapi.WebhookConfig{
+				Name:    known,
+				Timeout: lbcfapi.Duration{Duration: 10 * time.Second},
+			})
+		}
+		a := NewAdmitter(&alwaysSuccLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, c.execDriverDir)
+		raw, _ := json.Marshal(driver)
+		ar := &v1beta1.AdmissionReview{
+			Request: &v1beta1.AdmissionRequest{
+				Object: runtime.RawExtension{
+					Raw: raw,
+				},
+			},
+		}
+		if resp := a.ValidateDriverCreate(ar); resp.Allowed != c.expectAllow {
+			t.Errorf("case %s, expect %v, get %v", c.name, c.expectAllow, resp.Allowed)
+		}
+	}
+}
+
 func TestAdmitter_ValidateDriverDelete(t *testing.T) {
 	a := NewAdmitter(
 		&notfoundLBLister{},
@@ -634,7 +845,7 @@ func TestAdmitter_ValidateDriverDelete(t *testing.T) {
 				},
 			},
 		},
-		&notfoundBackendLister{}, &fakeSuccInvoker{})
+		&notfoundBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	ar := &v1beta1.AdmissionReview{
 		Request: &v1beta1.AdmissionRequest{},
 	}
@@ -654,7 +865,7 @@ func TestAdmitter_ValidateDriverDelete_NotDraining(t *testing.T) {
 				},
 			},
 		},
-		&notfoundBackendLister{}, &fakeSuccInvoker{})
+		&notfoundBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	ar := &v1beta1.AdmissionReview{
 		Request: &v1beta1.AdmissionRequest{},
 	}
@@ -690,7 +901,7 @@ func TestAdmitter_ValidateDriverDelete_LBRemaining(t *testing.T) {
 				},
 			},
 		},
-		&notfoundBackendLister{}, &fakeSuccInvoker{})
+		&notfoundBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	ar := &v1beta1.AdmissionReview{
 		Request: &v1beta1.AdmissionRequest{},
 	}
@@ -722,7 +933,7 @@ func TestAdmitter_ValidateDriverDelete_BackendRemaining(t *testing.T) {
 				},
 			},
 		},
-		&fakeSuccInvoker{})
+		&alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	ar := &v1beta1.AdmissionReview{
 		Request: &v1beta1.AdmissionRequest{},
 	}
@@ -948,7 +1159,7 @@ func TestAdmitter_ValidateDriverUpdate(t *testing.T) {
 		},
 	}
 
-	a := NewAdmitter(&alwaysSuccLBLister{}, &notfoundDriverLister{}, &alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+	a := NewAdmitter(&alwaysSuccLBLister{}, &notfoundDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	for _, c := range cases {
 		oldRaw, _ := json.Marshal(c.old)
 		curRaw, _ := json.Marshal(c.cur)
@@ -1002,7 +1213,7 @@ func TestAdmitter_ValidateDriverUpdate_UpdatedForbiddenField(t *testing.T) {
 			},
 		},
 	}
-	a := NewAdmitter(&alwaysSuccLBLister{}, &notfoundDriverLister{}, &alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+	a := NewAdmitter(&alwaysSuccLBLister{}, &notfoundDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	resp := a.ValidateDriverUpdate(ar)
 	if resp.Allowed {
 		t.Fatalf("expect not allow")
@@ -1042,7 +1253,7 @@ func TestAdmitter_ValidateDriverUpdate_CurObjInvalid(t *testing.T) {
 			},
 		},
 	}
-	a := NewAdmitter(&alwaysSuccLBLister{}, &notfoundDriverLister{}, &alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+	a := NewAdmitter(&alwaysSuccLBLister{}, &notfoundDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	resp := a.ValidateDriverUpdate(ar)
 	if resp.Allowed {
 		t.Fatalf("expect not allow")
@@ -1050,7 +1261,7 @@ func TestAdmitter_ValidateDriverUpdate_CurObjInvalid(t *testing.T) {
 }
 
 func TestAdmitter_ValidateLoadBalancerCreate_DriverNotExist(t *testing.T) {
-	a := NewAdmitter(&alwaysSuccLBLister{}, &notfoundDriverLister{}, &alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+	a := NewAdmitter(&alwaysSuccLBLister{}, &notfoundDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	lb := &lbcfapi.LoadBalancer{
 		Spec: lbcfapi.LoadBalancerSpec{
 			LBDriver: "test-driver",
@@ -1083,7 +1294,7 @@ func TestAdmitter_ValidateLoadBalancerCreate_DriverNotExist(t *testing.T) {
 }
 
 func TestAdmitter_ValidateLoadBalancerCreate_DriverDraining(t *testing.T) {
-	a := NewAdmitter(&alwaysSuccLBLister{}, drainingDriverLister(), &alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+	a := NewAdmitter(&alwaysSuccLBLister{}, drainingDriverLister(), &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	lb := &lbcfapi.LoadBalancer{
 		Spec: lbcfapi.LoadBalancerSpec{
 			LBDriver: "test-driver",
@@ -1116,7 +1327,7 @@ func TestAdmitter_ValidateLoadBalancerCreate_DriverDraining(t *testing.T) {
 }
 
 func TestAdmitter_ValidateLoadBalancerCreate_DriverDeleting(t *testing.T) {
-	a := NewAdmitter(&alwaysSuccLBLister{}, deletingDriverLister(), &alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+	a := NewAdmitter(&alwaysSuccLBLister{}, deletingDriverLister(), &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	lb := &lbcfapi.LoadBalancer{
 		Spec: lbcfapi.LoadBalancerSpec{
 			LBDriver: "test-driver",
@@ -1160,7 +1371,7 @@ func TestAdmitter_ValidateLoadBalancerCreate_WebhookFail(t *testing.T) {
 			},
 		},
 	}
-	a := NewAdmitter(&alwaysSuccLBLister{}, driverLister, &alwaysSuccBackendLister{}, &fakeFailInvoker{})
+	a := NewAdmitter(&alwaysSuccLBLister{}, driverLister, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeFailInvoker{}, "")
 	lb := &lbcfapi.LoadBalancer{
 		Spec: lbcfapi.LoadBalancerSpec{
 			LBDriver: "test-driver",
@@ -1237,7 +1448,7 @@ func TestAdmitter_ValidateLoadBalancerUpdate(t *testing.T) {
 		&alwaysSuccDriverLister{
 			get: &lbcfapi.LoadBalancerDriver{},
 		},
-		&alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+		&alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	resp := a.ValidateLoadBalancerUpdate(ar)
 	if !resp.Allowed {
 		t.Fatalf("expect allow")
@@ -1284,7 +1495,7 @@ func TestAdmitter_ValidateLoadBalancerUpdate_UpdatedForbiddenField(t *testing.T)
 		&alwaysSuccDriverLister{
 			get: &lbcfapi.LoadBalancerDriver{},
 		},
-		&alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+		&alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	resp := a.ValidateLoadBalancerUpdate(ar)
 	if resp.Allowed {
 		t.Fatalf("expect not allow")
@@ -1336,7 +1547,7 @@ func TestAdmitter_ValidateLoadBalancerUpdate_CurObjInvalid(t *testing.T) {
 		&alwaysSuccDriverLister{
 			get: &lbcfapi.LoadBalancerDriver{},
 		},
-		&alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+		&alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	resp := a.ValidateLoadBalancerUpdate(ar)
 	if resp.Allowed {
 		t.Fatalf("expect not allow")
@@ -1344,7 +1555,7 @@ func TestAdmitter_ValidateLoadBalancerUpdate_CurObjInvalid(t *testing.T) {
 }
 
 func TestAdmitter_ValidateLoadBalancerDelete(t *testing.T) {
-	a := NewAdmitter(&notfoundLBLister{}, &notfoundDriverLister{}, &notfoundBackendLister{}, &fakeSuccInvoker{})
+	a := NewAdmitter(&notfoundLBLister{}, &notfoundDriverLister{}, &notfoundBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	resp := a.ValidateLoadBalancerDelete(&v1beta1.AdmissionReview{
 		Request: &v1beta1.AdmissionRequest{
 			Name:      "name",
@@ -1414,7 +1625,7 @@ func TestAdmitter_ValidateBackendGroupCreate(t *testing.T) {
 				},
 			},
 		},
-		&alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+		&alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	resp := a.ValidateBackendGroupCreate(ar)
 	if !resp.Allowed {
 		t.Fatalf("expect allow")
@@ -1458,7 +1669,7 @@ func TestAdmitter_ValidateBackendGroupCreate_InvalidGroup(t *testing.T) {
 			},
 		},
 	}
-	a := NewAdmitter(&alwaysSuccLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &fakeFailInvoker{})
+	a := NewAdmitter(&alwaysSuccLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeFailInvoker{}, "")
 	resp := a.ValidateBackendGroupCreate(ar)
 	if resp.Allowed {
 		t.Fatalf("expect not allow")
@@ -1502,7 +1713,7 @@ func TestAdmitter_ValidateBackendGroupCreate_LBNotFound(t *testing.T) {
 			},
 		},
 	}
-	a := NewAdmitter(&notfoundLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &fakeFailInvoker{})
+	a := NewAdmitter(&notfoundLBLister{}, &alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeFailInvoker{}, "")
 	resp := a.ValidateBackendGroupCreate(ar)
 	if resp.Allowed {
 		t.Fatalf("expect not allow")
@@ -1553,7 +1764,7 @@ func TestAdmitter_ValidateBackendGroupCreate_LBDeleting(t *testing.T) {
 				DeletionTimestamp: &ts,
 			},
 		}},
-		&alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &fakeFailInvoker{})
+		&alwaysSuccDriverLister{}, &alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeFailInvoker{}, "")
 	resp := a.ValidateBackendGroupCreate(ar)
 	if resp.Allowed {
 		t.Fatalf("expect not allow")
@@ -1617,7 +1828,7 @@ func TestAdmitter_ValidateBackendGroupCreate_WebHookFail(t *testing.T) {
 				},
 			},
 		},
-		&alwaysSuccBackendLister{}, &fakeFailInvoker{})
+		&alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeFailInvoker{}, "")
 	resp := a.ValidateBackendGroupCreate(ar)
 	if resp.Allowed {
 		t.Fatalf("expect not allow")
@@ -1685,13 +1896,98 @@ func TestAdmitter_ValidateBackendGroupUpdate(t *testing.T) {
 		&alwaysSuccDriverLister{
 			get: &lbcfapi.LoadBalancerDriver{},
 		},
-		&alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+		&alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	resp := a.ValidateBackendGroupUpdate(ar)
 	if !resp.Allowed {
 		t.Fatalf("expect allow")
 	}
 }
 
+func TestAdmitter_ValidateBackendGroupUpdate_PortParameters(t *testing.T) {
+	newGroup := func(params map[string]string, ports ...lbcfapi.BackendPort) *lbcfapi.BackendGroup {
+		return &lbcfapi.BackendGroup{
+			Spec: lbcfapi.BackendGroupSpec{
+				LBName: "test-loadbalancer",
+				Pods: &lbcfapi.PodBackend{
+					Ports: ports,
+					ByLabel: &lbcfapi.SelectPodByLabel{
+						Selector: map[string]string{
+							"k1": "v1",
+						},
+					},
+				},
+				Parameters: params,
+			},
+		}
+	}
+	newPort := func(port int32, params map[string]string) lbcfapi.BackendPort {
+		return lbcfapi.BackendPort{
+			PortSelector: lbcfapi.PortSelector{
+				PortNumber: port,
+				Protocol:   "TCP",
+			},
+			Parameters: params,
+		}
+	}
+	old := newGroup(map[string]string{"p": "v1"},
+		newPort(80, map[string]string{"w": "1"}),
+		newPort(443, map[string]string{"w": "2"}))
+	cur := newGroup(map[string]string{"p": "v2"},
+		newPort(443, map[string]string{"w": "3"}),
+		newPort(8080, map[string]string{"w": "4"}))
+	oldRaw, _ := json.Marshal(old)
+	curRaw, _ := json.Marshal(cur)
+	ar := &v1beta1.AdmissionReview{
+		Request: &v1beta1.AdmissionRequest{
+			Object: runtime.RawExtension{
+				Raw: curRaw,
+			},
+			OldObject: runtime.RawExtension{
+				Raw: oldRaw,
+			},
+		},
+	}
+	invoker := &recordValidateBackendInvoker{}
+	a := NewAdmitter(
+		&alwaysSuccLBLister{
+			get: &lbcfapi.LoadBalancer{},
+		},
+		&alwaysSuccDriverLister{
+			get: &lbcfapi.LoadBalancerDriver{},
+		},
+		&alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, invoker, "")
+	resp := a.ValidateBackendGroupUpdate(ar)
+	if !resp.Allowed {
+		t.Fatalf("expect allow, get %v", resp.Result)
+	}
+
+	expect := []struct {
+		params    map[string]string
+		oldParams map[string]string
+	}{
+		{
+			params:    map[string]string{"p": "v2"},
+			oldParams: map[string]string{"p": "v1"},
+		},
+		{
+			params:    map[string]string{"p": "v2", "w": "3"},
+			oldParams: map[string]string{"p": "v1", "w": "2"},
+		},
+		{
+			params: map[string]string{"p": "v2", "w": "4"},
+		},
+	}
+	if len(invoker.requests) != len(expect) {
+		t.Fatalf("expect %d calls, get %d", len(expect), len(invoker.requests))
+	}
+	for i, e := range expect {
+		req := invoker.requests[i]
+		if !reflect.DeepEqual(req.Parameters, e.params) || !reflect.DeepEqual(req.OldParameters, e.oldParams) {
+			t.Errorf("call %d: expect parameters %v and old parameters %v, get %v and %v", i, e.params, e.oldParams, req.Parameters, req.OldParameters)
+		}
+	}
+}
+
 func TestAdmitter_ValidateBackendGroupUpdate_UpdatedForbiddenField(t *testing.T) {
 	old := &lbcfapi.BackendGroup{
 		Spec: lbcfapi.BackendGroupSpec{
@@ -1741,7 +2037,7 @@ func TestAdmitter_ValidateBackendGroupUpdate_UpdatedForbiddenField(t *testing.T)
 		&alwaysSuccDriverLister{
 			get: &lbcfapi.LoadBalancerDriver{},
 		},
-		&alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+		&alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	resp := a.ValidateBackendGroupUpdate(ar)
 	if resp.Allowed {
 		t.Fatalf("expect not allow")
@@ -1810,7 +2106,7 @@ func TestAdmitter_ValidateBackendGroupUpdate_CurObjInvalid(t *testing.T) {
 		&alwaysSuccDriverLister{
 			get: &lbcfapi.LoadBalancerDriver{},
 		},
-		&alwaysSuccBackendLister{}, &fakeSuccInvoker{})
+		&alwaysSuccBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	resp := a.ValidateBackendGroupUpdate(ar)
 	if resp.Allowed {
 		t.Fatalf("expect not allow")
@@ -1818,7 +2114,7 @@ func TestAdmitter_ValidateBackendGroupUpdate_CurObjInvalid(t *testing.T) {
 }
 
 func TestAdmitter_ValidateBackendGroupDelete(t *testing.T) {
-	a := NewAdmitter(&notfoundLBLister{}, &notfoundDriverLister{}, &notfoundBackendLister{}, &fakeSuccInvoker{})
+	a := NewAdmitter(&notfoundLBLister{}, &notfoundDriverLister{}, &notfoundBackendLister{}, &alwaysSuccBackendGroupLister{}, &fakeSuccInvoker{}, "")
 	resp := a.ValidateBackendGroupDelete(&v1beta1.AdmissionReview{
 		Request: &v1beta1.AdmissionRequest{
 			Name:      "name",
@@ -1899,6 +2195,23 @@ func (l *alwaysSuccBackendLister) BackendRecords(namespace string) lbcflister.Ba
 	return l
 }
 
+type alwaysSuccBackendGroupLister struct {
+	get  *lbcfapi.BackendGroup
+	list []*lbcfapi.BackendGroup
+}
+
+func (l *alwaysSuccBackendGroupLister) Get(name string) (*lbcfapi.BackendGroup, error) {
+	return l.get, nil
+}
+
+func (l *alwaysSuccBackendGroupLister) List(selector labels.Selector) (ret []*lbcfapi.BackendGroup, err error) {
+	return l.list, nil
+}
+
+func (l *alwaysSuccBackendGroupLister) BackendGroups(namespace string) lbcflister.BackendGroupNamespaceLister {
+	return l
+}
+
 type notfoundDriverLister struct {
 }
 
@@ -2016,6 +2329,35 @@ func (c *fakeSuccInvoker) CallDeregisterBackend(driver *lbcfapi.LoadBalancerDriv
 	}, nil
 }
 
+func (c *fakeSuccInvoker) CallDrainBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	return &webhooks.BackendOperationResponse{
+		ResponseForFailRetryHooks: webhooks.ResponseForFailRetryHooks{
+			Status: webhooks.StatusSucc,
+			Msg:    "fake succ",
+		},
+	}, nil
+}
+
+func (c *fakeSuccInvoker) CallHealthCheck(driver *lbcfapi.LoadBalancerDriver) error {
+	return nil
+}
+
+func (c *fakeSuccInvoker) GetCircuitBreakerStatus(driver *lbcfapi.LoadBalancerDriver) util.CircuitBreakerStatus {
+	return util.CircuitBreakerStatus{State: util.CircuitClosed}
+}
+
+func (c *fakeSuccInvoker) ForgetDriver(driverKey string) {}
+
+type recordValidateBackendInvoker struct {
+	fakeSuccInvoker
+	requests []*webhooks.ValidateBackendRequest
+}
+
+func (c *recordValidateBackendInvoker) CallValidateBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.ValidateBackendRequest) (*webhooks.ValidateBackendResponse, error) {
+	c.requests = append(c.requests, req)
+	return c.fakeSuccInvoker.CallValidateBackend(driver, req)
+}
+
 type fakeFailInvoker struct{}
 
 func (c *fakeFailInvoker) CallValidateLoadBalancer(driver *lbcfapi.LoadBalancerDriver, req *webhooks.ValidateLoadBalancerRequest) (*webhooks.ValidateLoadBalancerResponse, error) {
@@ -2090,6 +2432,25 @@ func (c *fakeFailInvoker) CallDeregisterBackend(driver *lbcfapi.LoadBalancerDriv
 	}, nil
 }
 
+func (c *fakeFailInvoker) CallDrainBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	return &webhooks.BackendOperationResponse{
+		ResponseForFailRetryHooks: webhooks.ResponseForFailRetryHooks{
+			Status: webhooks.StatusFail,
+			Msg:    "fake fail",
+		},
+	}, nil
+}
+
+func (c *fakeFailInvoker) CallHealthCheck(driver *lbcfapi.LoadBalancerDriver) error {
+	return nil
+}
+
+func (c *fakeFailInvoker) GetCircuitBreakerStatus(driver *lbcfapi.LoadBalancerDriver) util.CircuitBreakerStatus {
+	return util.CircuitBreakerStatus{State: util.CircuitClosed}
+}
+
+func (c *fakeFailInvoker) ForgetDriver(driverKey string) {}
+
 func drainingDriverLister() lbcflister.LoadBalancerDriverLister {
 	return &alwaysSuccDriverLister{
 		get: &lbcfapi.LoadBalancerDriver{
@@ -18,13 +18,16 @@
 package admission
 
 import (
+	"fmt"
 	"path"
 	"strings"
 	"time"
 
 	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/util"
 	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
 
+	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 )
 
@@ -94,6 +97,20 @@ func defaultPodProtocol() Patch {
 	}
 }
 
+func defaultPortsProtocol(portsPath string, ports []lbcfapi.BackendPort) []Patch {
+	var patches []Patch
+	for i, port := range ports {
+		if port.Protocol == "" {
+			patches = append(patches, Patch{
+				OP:    patchOpAdd,
+				Path:  fmt.Sprintf("%s/%d/protocol", portsPath, i),
+				Value: "TCP",
+			})
+		}
+	}
+	return patches
+}
+
 type backendGroupPatch struct {
 	obj     *lbcfapi.BackendGroup
 	patches []Patch
@@ -115,10 +132,18 @@ func (bp *backendGroupPatch) addLabel() {
 }
 
 func (bp *backendGroupPatch) setDefaultProtocol() {
-	if bp.obj.Spec.Service != nil && bp.obj.Spec.Service.Port.Protocol == "" {
-		bp.patches = append(bp.patches, defaultSvcProtocol())
-	} else if bp.obj.Spec.Pods != nil && bp.obj.Spec.Pods.Port.Protocol == "" {
-		bp.patches = append(bp.patches, defaultPodProtocol())
+	if bp.obj.Spec.Service != nil {
+		if len(bp.obj.Spec.Service.Ports) > 0 {
+			bp.patches = append(bp.patches, defaultPortsProtocol("/spec/service/ports", bp.obj.Spec.Service.Ports)...)
+		} else if bp.obj.Spec.Service.Port.Protocol == "" {
+			bp.patches = append(bp.patches, defaultSvcProtocol())
+		}
+	} else if bp.obj.Spec.Pods != nil {
+		if len(bp.obj.Spec.Pods.Ports) > 0 {
+			bp.patches = append(bp.patches, defaultPortsProtocol("/spec/pods/ports", bp.obj.Spec.Pods.Ports)...)
+		} else if bp.obj.Spec.Pods.Port.Protocol == "" {
+			bp.patches = append(bp.patches, defaultPodProtocol())
+		}
 	}
 }
 
@@ -151,7 +176,7 @@ func (dp *driverPatch) setWebhook() {
 	}
 
 	for known := range webhooks.KnownWebhooks {
-		if existWebhooks.Has(known) {
+		if existWebhooks.Has(known) || webhooks.OptInWebhooks.Has(known) {
 			continue
 		}
 		dp.patches = append(dp.patches, Patch{
@@ -167,6 +192,59 @@ func (dp *driverPatch) setWebhook() {
 	}
 }
 
+func (dp *driverPatch) setProtocolVersion() {
+	if dp.obj.Spec.ProtocolVersion != "" {
+		return
+	}
+	dp.patches = append(dp.patches, Patch{
+		OP:    patchOpAdd,
+		Path:  path.Join("/", "spec", "protocolVersion"),
+		Value: webhooks.DefaultProtocolVersion,
+	})
+}
+
 func (dp *driverPatch) patch() []Patch {
 	return dp.patches
 }
+
+type podPatch struct {
+	obj     *v1.Pod
+	patches []Patch
+}
+
+func (pp *podPatch) addReadinessGate(groups []*lbcfapi.BackendGroup) {
+	if util.HasBackendsRegisteredGate(pp.obj) {
+		return
+	}
+	required := false
+	for _, group := range groups {
+		if group.Spec.Pods != nil && group.Spec.Pods.ReadinessGate && util.IsPodMatchBackendGroup(group, pp.obj) {
+			required = true
+			break
+		}
+	}
+	if !required {
+		return
+	}
+
+	gate := v1.PodReadinessGate{
+		ConditionType: v1.PodConditionType(lbcfapi.PodConditionBackendsRegistered),
+	}
+	if len(pp.obj.Spec.ReadinessGates) == 0 {
+		pp.patches = append(pp.patches, Patch{
+			OP:    patchOpAdd,
+			Path:  path.Join("/", "spec", "readinessGates"),
+			Value: []v1.PodReadinessGate{gate},
+		})
+		return
+	}
+	pp.patches = append(pp.patches, Patch{
+		OP:    patchOpAdd,
+		Path:  path.Join("/", "spec", "readinessGates", "-"),
+		Value: gate,
+	})
+}
+
+func (pp *podPatch) patch() []Patch {
+	return pp.patches
+}
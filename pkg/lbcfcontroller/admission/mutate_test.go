@@ -260,3 +260,40 @@ func TestDefaultProtocolOfSvcBackend(t *testing.T) {
 		t.Fatalf("expect %d/%s, get %d/%s", svcGroup.Spec.Service.Port.PortNumber, "TCP", ps.PortNumber, ps.Protocol)
 	}
 }
+
+func TestDefaultProtocolOfPorts(t *testing.T) {
+	podGroup := &lbcfapi.BackendGroup{
+		Spec: lbcfapi.BackendGroupSpec{
+			Pods: &lbcfapi.PodBackend{
+				Ports: []lbcfapi.BackendPort{
+					{PortSelector: lbcfapi.PortSelector{PortNumber: 80}},
+					{PortSelector: lbcfapi.PortSelector{PortNumber: 53, Protocol: "UDP"}},
+				},
+			},
+		},
+	}
+	origin, err := json.Marshal(podGroup)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	p, err := json.Marshal(defaultPortsProtocol("/spec/pods/ports", podGroup.Spec.Pods.Ports))
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	patch, err := jsonpatch.DecodePatch(p)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	modified, err := patch.Apply(origin)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+	modifiedObj := &lbcfapi.BackendGroup{}
+	if err := json.Unmarshal(modified, modifiedObj); err != nil {
+		t.Fatal(err.Error())
+	}
+	ports := modifiedObj.Spec.Pods.Ports
+	if len(ports) != 2 || ports[0].Protocol != "TCP" || ports[1].Protocol != "UDP" {
+		t.Fatalf("expect 80/TCP and 53/UDP, get %+v", ports)
+	}
+}
@@ -18,10 +18,13 @@
 package admission
 
 import (
+	"crypto/x509"
 	"fmt"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/selection"
+	"net"
 	"net/url"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"time"
@@ -32,6 +35,9 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
@@ -41,8 +47,34 @@ func ValidateLoadBalancerDriver(raw *lbcfapi.LoadBalancerDriver) field.ErrorList
 
 	allErrs = append(allErrs, validateDriverName(raw.Name, raw.Namespace, field.NewPath("metadata").Child("name"))...)
 	allErrs = append(allErrs, validateDriverType(raw.Spec.DriverType, field.NewPath("spec").Child("driverType"))...)
-	allErrs = append(allErrs, validateDriverURL(raw.Spec.Url, field.NewPath("spec").Child("url"))...)
+	if lbcfapi.DriverType(raw.Spec.DriverType) == lbcfapi.ExecDriver {
+		allErrs = append(allErrs, validateExecDriver(&raw.Spec, field.NewPath("spec"))...)
+	} else if raw.Spec.Exec != nil {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("exec"), fmt.Sprintf("exec is only supported by %s driver", lbcfapi.ExecDriver)))
+	} else if raw.Spec.Service != nil {
+		if raw.Spec.Url != "" {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec").Child("url"), "url and service must not be specified at the same time"))
+		}
+		allErrs = append(allErrs, validateDriverService(*raw.Spec.Service, field.NewPath("spec").Child("service"))...)
+	} else {
+		allErrs = append(allErrs, validateDriverURL(raw.Spec.DriverType, raw.Spec.Url, field.NewPath("spec").Child("url"))...)
+	}
 	allErrs = append(allErrs, validateDriverWebhooks(raw.Spec.Webhooks, field.NewPath("spec").Child("webhooks"))...)
+	if !webhooks.IsProtocolVersionSupported(raw.Spec.ProtocolVersion) {
+		allErrs = append(allErrs, field.NotSupported(field.NewPath("spec").Child("protocolVersion"), raw.Spec.ProtocolVersion, webhooks.SupportedProtocolVersions()))
+	}
+	if raw.Spec.MaxConcurrentCalls != nil && *raw.Spec.MaxConcurrentCalls <= 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("spec").Child("maxConcurrentCalls"), *raw.Spec.MaxConcurrentCalls, "maxConcurrentCalls must be greater than 0"))
+	}
+	if raw.Spec.TLS != nil {
+		allErrs = append(allErrs, validateDriverTLS(raw.Spec.DriverType, raw.Spec.Url, *raw.Spec.TLS, field.NewPath("spec").Child("tls"))...)
+	}
+	if raw.Spec.Auth != nil {
+		allErrs = append(allErrs, validateDriverAuth(raw.Spec.DriverType, *raw.Spec.Auth, field.NewPath("spec").Child("auth"))...)
+	}
+	if raw.Spec.HealthCheck != nil {
+		allErrs = append(allErrs, validateDriverHealthCheck(raw.Spec.DriverType, *raw.Spec.HealthCheck, field.NewPath("spec").Child("healthCheck"))...)
+	}
 	return allErrs
 }
 
@@ -64,6 +96,9 @@ func ValidateBackendGroup(raw *lbcfapi.BackendGroup) field.ErrorList {
 	if raw.Spec.EnsurePolicy != nil {
 		allErrs = append(allErrs, validateEnsurePolicy(*raw.Spec.EnsurePolicy, field.NewPath("spec").Child("ensurePolicy"))...)
 	}
+	if raw.Spec.DrainPolicy != nil {
+		allErrs = append(allErrs, validateDrainPolicy(*raw.Spec.DrainPolicy, field.NewPath("spec").Child("drainPolicy"))...)
+	}
 	allErrs = append(allErrs, validateBackends(&raw.Spec, field.NewPath("spec"))...)
 	return allErrs
 }
@@ -76,6 +111,12 @@ func DriverUpdatedFieldsAllowed(cur *lbcfapi.LoadBalancerDriver, old *lbcfapi.Lo
 	if old.Spec.DriverType != cur.Spec.DriverType {
 		return false, "updating driverType is prohibited"
 	}
+	if !reflect.DeepEqual(old.Spec.Service, cur.Spec.Service) {
+		return false, "updating service is prohibited"
+	}
+	if !reflect.DeepEqual(old.Spec.Exec, cur.Spec.Exec) {
+		return false, "updating exec is prohibited"
+	}
 	return true, ""
 }
 
@@ -118,6 +159,14 @@ func validateEnsurePolicy(raw lbcfapi.EnsurePolicyConfig, path *field.Path) fiel
 	return allErrs
 }
 
+func validateDrainPolicy(raw lbcfapi.DrainPolicyConfig, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if raw.Period.Nanoseconds() <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("period"), raw.Period, "period must be greater than 0"))
+	}
+	return allErrs
+}
+
 func validateDriverName(name string, namespace string, path *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	if namespace == metav1.NamespaceSystem {
@@ -134,20 +183,137 @@ func validateDriverName(name string, namespace string, path *field.Path) field.E
 
 func validateDriverType(raw string, path *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	if raw != string(lbcfapi.WebhookDriver) {
-		allErrs = append(allErrs, field.Invalid(path, raw, fmt.Sprintf("driverType must be %v", lbcfapi.WebhookDriver)))
+	switch lbcfapi.DriverType(raw) {
+	case lbcfapi.WebhookDriver, lbcfapi.GRPCDriver, lbcfapi.ExecDriver:
+	default:
+		allErrs = append(allErrs, field.NotSupported(path, raw, []string{string(lbcfapi.WebhookDriver), string(lbcfapi.GRPCDriver), string(lbcfapi.ExecDriver)}))
 	}
 	return allErrs
 }
 
-func validateDriverURL(raw string, path *field.Path) field.ErrorList {
+func validateDriverURL(driverType string, raw string, path *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
+	if raw == "" {
+		allErrs = append(allErrs, field.Required(path, "either url or service must be specified"))
+		return allErrs
+	}
+	if lbcfapi.DriverType(driverType) == lbcfapi.GRPCDriver {
+		if _, _, err := net.SplitHostPort(raw); err != nil {
+			allErrs = append(allErrs, field.Invalid(path, raw, fmt.Sprintf("url of GRPC driver must be in the form of host:port, %v", err)))
+		}
+		return allErrs
+	}
 	if _, err := url.Parse(raw); err != nil {
 		allErrs = append(allErrs, field.Invalid(path, raw, err.Error()))
 	}
 	return allErrs
 }
 
+func validateExecDriver(spec *lbcfapi.LoadBalancerDriverSpec, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if spec.Url != "" {
+		allErrs = append(allErrs, field.Forbidden(path.Child("url"), fmt.Sprintf("url is not supported by %s driver", lbcfapi.ExecDriver)))
+	}
+	if spec.Service != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("service"), fmt.Sprintf("service is not supported by %s driver", lbcfapi.ExecDriver)))
+	}
+	if spec.TLS != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("tls"), fmt.Sprintf("tls is not supported by %s driver", lbcfapi.ExecDriver)))
+	}
+	if spec.Auth != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("auth"), fmt.Sprintf("auth is not supported by %s driver", lbcfapi.ExecDriver)))
+	}
+	if spec.Exec == nil {
+		allErrs = append(allErrs, field.Required(path.Child("exec"), fmt.Sprintf("exec must be specified for %s driver", lbcfapi.ExecDriver)))
+		return allErrs
+	}
+	if !filepath.IsAbs(spec.Exec.Command) {
+		allErrs = append(allErrs, field.Invalid(path.Child("exec").Child("command"), spec.Exec.Command, "command must be an absolute path"))
+	}
+	return allErrs
+}
+
+func validateDriverService(raw lbcfapi.DriverServiceReference, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for _, msg := range validation.IsDNS1123Label(raw.Namespace) {
+		allErrs = append(allErrs, field.Invalid(path.Child("namespace"), raw.Namespace, msg))
+	}
+	for _, msg := range validation.IsDNS1035Label(raw.Name) {
+		allErrs = append(allErrs, field.Invalid(path.Child("name"), raw.Name, msg))
+	}
+	for _, msg := range validation.IsValidPortNum(int(raw.Port)) {
+		allErrs = append(allErrs, field.Invalid(path.Child("port"), raw.Port, msg))
+	}
+	if raw.Path != "" && !strings.HasPrefix(raw.Path, "/") {
+		allErrs = append(allErrs, field.Invalid(path.Child("path"), raw.Path, "path must start with /"))
+	}
+	return allErrs
+}
+
+func validateDriverTLS(driverType string, driverURL string, raw lbcfapi.DriverTLSConfig, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if lbcfapi.DriverType(driverType) == lbcfapi.WebhookDriver && driverURL != "" {
+		if u, err := url.Parse(driverURL); err == nil && u.Scheme != "https" {
+			allErrs = append(allErrs, field.Invalid(path, driverURL, "tls is only supported when the scheme of url is https"))
+		}
+	}
+	if len(raw.CABundle) > 0 && !x509.NewCertPool().AppendCertsFromPEM(raw.CABundle) {
+		allErrs = append(allErrs, field.Invalid(path.Child("caBundle"), "", "no PEM encoded certificate found"))
+	}
+	if raw.ClientCertSecretName != "" {
+		for _, msg := range validation.IsDNS1123Subdomain(raw.ClientCertSecretName) {
+			allErrs = append(allErrs, field.Invalid(path.Child("clientCertSecretName"), raw.ClientCertSecretName, msg))
+		}
+	}
+	return allErrs
+}
+
+func validateDriverAuth(driverType string, raw lbcfapi.DriverAuthConfig, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch raw.Type {
+	case lbcfapi.BearerTokenAuth:
+	case lbcfapi.HMACAuth:
+		if lbcfapi.DriverType(driverType) == lbcfapi.GRPCDriver {
+			allErrs = append(allErrs, field.Invalid(path.Child("type"), raw.Type, fmt.Sprintf("%s is not supported by GRPC driver", lbcfapi.HMACAuth)))
+		}
+	default:
+		allErrs = append(allErrs, field.NotSupported(path.Child("type"), raw.Type, []string{string(lbcfapi.BearerTokenAuth), string(lbcfapi.HMACAuth)}))
+	}
+	if raw.SecretName == "" {
+		allErrs = append(allErrs, field.Required(path.Child("secretName"), "secretName must be specified"))
+	} else {
+		for _, msg := range validation.IsDNS1123Subdomain(raw.SecretName) {
+			allErrs = append(allErrs, field.Invalid(path.Child("secretName"), raw.SecretName, msg))
+		}
+	}
+	return allErrs
+}
+
+func validateDriverHealthCheck(driverType string, raw lbcfapi.DriverHealthCheckConfig, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if raw.Path != "" {
+		if lbcfapi.DriverType(driverType) != lbcfapi.WebhookDriver {
+			allErrs = append(allErrs, field.Forbidden(path.Child("path"), fmt.Sprintf("path is not supported by %s driver", driverType)))
+		} else if !strings.HasPrefix(raw.Path, "/") {
+			allErrs = append(allErrs, field.Invalid(path.Child("path"), raw.Path, "path must start with /"))
+		}
+	}
+	if raw.Period != nil && raw.Period.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("period"), raw.Period, "period must be greater than 0"))
+	}
+	if raw.Timeout != nil {
+		if raw.Timeout.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("timeout"), raw.Timeout, "timeout must be greater than 0"))
+		} else if raw.Timeout.Nanoseconds() > (1 * time.Minute).Nanoseconds() {
+			allErrs = append(allErrs, field.Invalid(path.Child("timeout"), raw.Timeout, "timeout must be less than or equal to 1m"))
+		}
+	}
+	if raw.FailureThreshold < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("failureThreshold"), raw.FailureThreshold, "failureThreshold must not be negative"))
+	}
+	return allErrs
+}
+
 func validateDriverWebhooks(raw []lbcfapi.WebhookConfig, path *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 	var supported []string
@@ -168,7 +334,9 @@ func validateDriverWebhooks(raw []lbcfapi.WebhookConfig, path *field.Path) field
 
 	for known := range webhooks.KnownWebhooks {
 		wh, ok := hasWebhook[known]
-		if !ok {
+		if !ok && webhooks.OptInWebhooks.Has(known) {
+			continue
+		} else if !ok {
 			allErrs = append(allErrs, field.Required(path.Child(known), fmt.Sprintf("webhook %s must be configured", known)))
 			continue
 		}
@@ -177,6 +345,23 @@ func validateDriverWebhooks(raw []lbcfapi.WebhookConfig, path *field.Path) field
 		} else if wh.Timeout.Duration == 0 {
 			allErrs = append(allErrs, field.Invalid(path.Child(known).Child("timeout"), wh.Timeout, fmt.Sprintf("webhook %s invalid, timeout of must be specified", wh.Name)))
 		}
+		if wh.RateLimit != nil {
+			allErrs = append(allErrs, validateRateLimit(*wh.RateLimit, path.Child(known).Child("rateLimit"))...)
+		}
+		if wh.Optional && !webhooks.OptionalWebhooks.Has(known) {
+			allErrs = append(allErrs, field.Invalid(path.Child(known).Child("optional"), wh.Optional, fmt.Sprintf("webhook %s can not be optional", wh.Name)))
+		}
+	}
+	return allErrs
+}
+
+func validateRateLimit(raw lbcfapi.RateLimitConfig, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if raw.QPS <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("qps"), raw.QPS, "qps must be greater than 0"))
+	}
+	if raw.Burst < 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("burst"), raw.Burst, "burst must not be negative"))
 	}
 	return allErrs
 }
@@ -208,22 +393,34 @@ func validateBackends(raw *lbcfapi.BackendGroupSpec, path *field.Path) field.Err
 
 func validateServiceBackend(raw *lbcfapi.ServiceBackend, path *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	allErrs = append(allErrs, validatePortSelector(raw.Port, path.Child("port"))...)
+	allErrs = append(allErrs, validateBackendPorts(raw.Port, raw.Ports, validatePortSelector, path)...)
+	allErrs = append(allErrs, validateServiceBackendMode(raw.Mode, path.Child("mode"))...)
+	if raw.Mode == lbcfapi.ServiceModeEndpoints && len(raw.NodeSelector) > 0 {
+		allErrs = append(allErrs, field.Forbidden(path.Child("nodeSelector"), "nodeSelector is not supported in Endpoints mode"))
+	}
 	allErrs = append(allErrs, validateLabelSelector(raw.NodeSelector, path.Child("nodeSelector"))...)
 	return allErrs
 }
 
+func validateServiceBackendMode(raw lbcfapi.ServiceBackendMode, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch raw {
+	case "", lbcfapi.ServiceModeNodePort, lbcfapi.ServiceModeEndpoints:
+	default:
+		allErrs = append(allErrs, field.NotSupported(path, raw, []string{string(lbcfapi.ServiceModeNodePort), string(lbcfapi.ServiceModeEndpoints)}))
+	}
+	return allErrs
+}
+
 func validatePodBackend(raw *lbcfapi.PodBackend, path *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
-	allErrs = append(allErrs, validatePortSelector(raw.Port, path.Child("port"))...)
+	allErrs = append(allErrs, validateBackendPorts(raw.Port, raw.Ports, validatePodPortSelector, path)...)
+	allErrs = append(allErrs, validateDeregisterPolicy(raw.DeregisterPolicy, path.Child("deregisterPolicy"))...)
 	if raw.ByLabel != nil {
 		if raw.ByName != nil {
 			allErrs = append(allErrs, field.Invalid(path.Child("byName"), raw.ByName, "only one of \"byLabel, byName\" is allowed"))
 		}
-		if len(raw.ByLabel.Selector) == 0 {
-			allErrs = append(allErrs, field.Required(path.Child("byLabel").Child("selector"), "selector must be specified"))
-		}
-		allErrs = append(allErrs, validateLabelSelector(raw.ByLabel.Selector, path.Child("byLabel").Child("selector"))...)
+		allErrs = append(allErrs, validateSelectPodByLabel(raw.ByLabel, path.Child("byLabel"))...)
 		return allErrs
 	}
 
@@ -233,9 +430,78 @@ func validatePodBackend(raw *lbcfapi.PodBackend, path *field.Path) field.ErrorLi
 	return allErrs
 }
 
+func validateSelectPodByLabel(raw *lbcfapi.SelectPodByLabel, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	if raw.LabelSelector != nil {
+		if len(raw.Selector) > 0 {
+			allErrs = append(allErrs, field.Invalid(path.Child("selector"), raw.Selector, "only one of \"selector, labelSelector\" is allowed"))
+		}
+		if len(raw.LabelSelector.MatchLabels) == 0 && len(raw.LabelSelector.MatchExpressions) == 0 {
+			allErrs = append(allErrs, field.Required(path.Child("labelSelector"), "labelSelector must not be empty"))
+		}
+		allErrs = append(allErrs, metav1validation.ValidateLabelSelector(raw.LabelSelector, path.Child("labelSelector"))...)
+		return allErrs
+	}
+	if len(raw.Selector) == 0 {
+		allErrs = append(allErrs, field.Required(path.Child("selector"), "one of \"selector, labelSelector\" must be specified"))
+	}
+	allErrs = append(allErrs, validateLabelSelector(raw.Selector, path.Child("selector"))...)
+	return allErrs
+}
+
+func validateDeregisterPolicy(raw lbcfapi.DeregisterPolicy, path *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+	switch raw {
+	case "", lbcfapi.DeregisterIfNotReady, lbcfapi.DeregisterIfNotRunning, lbcfapi.DeregisterOnDelete:
+	default:
+		allErrs = append(allErrs, field.NotSupported(path, raw, []string{string(lbcfapi.DeregisterIfNotReady), string(lbcfapi.DeregisterIfNotRunning), string(lbcfapi.DeregisterOnDelete)}))
+	}
+	return allErrs
+}
+
+func validateBackendPorts(port lbcfapi.PortSelector, ports []lbcfapi.BackendPort, validatePort func(lbcfapi.PortSelector, *field.Path) field.ErrorList, path *field.Path) field.ErrorList {
+	if len(ports) == 0 {
+		return validatePort(port, path.Child("port"))
+	}
+	allErrs := field.ErrorList{}
+	if port.PortNumber != 0 || port.PortName != "" {
+		allErrs = append(allErrs, field.Invalid(path.Child("port"), port, "only one of \"port, ports\" is allowed"))
+	}
+	seen := sets.NewString()
+	for i, p := range ports {
+		allErrs = append(allErrs, validatePort(p.PortSelector, path.Child("ports").Index(i))...)
+		key := fmt.Sprintf("%d/%s/%s", p.PortNumber, p.PortName, p.Protocol)
+		if seen.Has(key) {
+			allErrs = append(allErrs, field.Duplicate(path.Child("ports").Index(i), p.PortSelector))
+		}
+		seen.Insert(key)
+	}
+	return allErrs
+}
+
+func validatePodPortSelector(raw lbcfapi.PortSelector, path *field.Path) field.ErrorList {
+	if raw.PortName == "" {
+		return validatePortSelector(raw, path)
+	}
+	allErrs := field.ErrorList{}
+	if raw.PortNumber != 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("portNumber"), raw.PortNumber, "only one of \"portNumber, portName\" is allowed"))
+	}
+	for _, msg := range validation.IsValidPortName(raw.PortName) {
+		allErrs = append(allErrs, field.Invalid(path.Child("portName"), raw.PortName, msg))
+	}
+	if raw.Protocol != string(v1.ProtocolTCP) && raw.Protocol != string(v1.ProtocolUDP) {
+		allErrs = append(allErrs, field.Invalid(path.Child("protocol"), raw.Protocol, "protocol must be \"TCP\" or \"UDP\""))
+	}
+	return allErrs
+}
+
 func validatePortSelector(raw lbcfapi.PortSelector, path *field.Path) field.ErrorList {
 	allErrs := field.ErrorList{}
 
+	if raw.PortName != "" {
+		allErrs = append(allErrs, field.Forbidden(path.Child("portName"), "portName is only supported in pods"))
+	}
 	if raw.PortNumber <= 0 || raw.PortNumber > 65535 {
 		allErrs = append(allErrs, field.Invalid(path.Child("portNumber"), raw.PortNumber, "portNumber must be greater than 0 and less than 65536"))
 	}
@@ -24,6 +24,7 @@ import (
 	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 )
 
 func TestValidateLoadBalancerDriver(t *testing.T) {
@@ -300,6 +301,82 @@ func TestValidateLoadBalancerDriver(t *testing.T) {
 	}
 }
 
+func TestValidateDriverTypeAndURL(t *testing.T) {
+	cases := []struct {
+		name        string
+		driverType  string
+		url         string
+		expectValid bool
+	}{
+		{
+			name:        "valid-webhook",
+			driverType:  string(lbcfapi.WebhookDriver),
+			url:         "http://1.1.1.1:80",
+			expectValid: true,
+		},
+		{
+			name:        "valid-grpc",
+			driverType:  string(lbcfapi.GRPCDriver),
+			url:         "driver.kube-system.svc:9090",
+			expectValid: true,
+		},
+		{
+			name:       "invalid-grpc-url",
+			driverType: string(lbcfapi.GRPCDriver),
+			url:        "http://driver.kube-system.svc",
+		},
+		{
+			name:       "invalid-driver-type",
+			driverType: "unknown",
+			url:        "http://1.1.1.1:80",
+		},
+	}
+	for _, c := range cases {
+		err := validateDriverType(c.driverType, field.NewPath("driverType"))
+		err = append(err, validateDriverURL(c.driverType, c.url, field.NewPath("url"))...)
+		if c.expectValid && len(err) > 0 {
+			t.Errorf("case %s, expect valid, get error: %v", c.name, err.ToAggregate().Error())
+		} else if !c.expectValid && len(err) == 0 {
+			t.Errorf("case %s, expect invalid, get valid", c.name)
+		}
+	}
+}
+
+func TestValidateRateLimit(t *testing.T) {
+	cases := []struct {
+		name        string
+		rateLimit   lbcfapi.RateLimitConfig
+		expectValid bool
+	}{
+		{
+			name:        "valid",
+			rateLimit:   lbcfapi.RateLimitConfig{QPS: 10, Burst: 20},
+			expectValid: true,
+		},
+		{
+			name:        "valid-default-burst",
+			rateLimit:   lbcfapi.RateLimitConfig{QPS: 10},
+			expectValid: true,
+		},
+		{
+			name:      "invalid-qps",
+			rateLimit: lbcfapi.RateLimitConfig{QPS: 0, Burst: 20},
+		},
+		{
+			name:      "invalid-burst",
+			rateLimit: lbcfapi.RateLimitConfig{QPS: 10, Burst: -1},
+		},
+	}
+	for _, c := range cases {
+		err := validateRateLimit(c.rateLimit, field.NewPath("rateLimit"))
+		if c.expectValid && len(err) > 0 {
+			t.Errorf("case %s, expect valid, get error: %v", c.name, err.ToAggregate().Error())
+		} else if !c.expectValid && len(err) == 0 {
+			t.Errorf("case %s, expect invalid, get valid", c.name)
+		}
+	}
+}
+
 func TestValidateLoadBalancer(t *testing.T) {
 	type testCase struct {
 		name        string
@@ -846,6 +923,329 @@ func TestValidateBackendGroup(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "valid-svc-backend-endpoints-mode",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Service: &lbcfapi.ServiceBackend{
+						Name: "svc-name",
+						Port: lbcfapi.PortSelector{
+							PortNumber: 80,
+							Protocol:   tcp,
+						},
+						Mode: lbcfapi.ServiceModeEndpoints,
+					},
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "invalid-svc-backend-unknown-mode",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Service: &lbcfapi.ServiceBackend{
+						Name: "svc-name",
+						Port: lbcfapi.PortSelector{
+							PortNumber: 80,
+							Protocol:   tcp,
+						},
+						Mode: "ClusterIP",
+					},
+				},
+			},
+		},
+		{
+			name: "invalid-svc-backend-endpoints-mode-with-nodeSelector",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Service: &lbcfapi.ServiceBackend{
+						Name: "svc-name",
+						Port: lbcfapi.PortSelector{
+							PortNumber: 80,
+							Protocol:   tcp,
+						},
+						Mode: lbcfapi.ServiceModeEndpoints,
+						NodeSelector: map[string]string{
+							"key": "value",
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "valid-pod-backend-deregister-policy",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Pods: &lbcfapi.PodBackend{
+						Port: lbcfapi.PortSelector{
+							PortNumber: 80,
+							Protocol:   tcp,
+						},
+						ByName:           []string{"pod-0"},
+						DeregisterPolicy: lbcfapi.DeregisterOnDelete,
+					},
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "invalid-pod-backend-deregister-policy",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Pods: &lbcfapi.PodBackend{
+						Port: lbcfapi.PortSelector{
+							PortNumber: 80,
+							Protocol:   tcp,
+						},
+						ByName:           []string{"pod-0"},
+						DeregisterPolicy: "Never",
+					},
+				},
+			},
+		},
+		{
+			name: "valid-pod-backend-label-selector",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Pods: &lbcfapi.PodBackend{
+						Port: lbcfapi.PortSelector{
+							PortNumber: 80,
+							Protocol:   tcp,
+						},
+						ByLabel: &lbcfapi.SelectPodByLabel{
+							LabelSelector: &metav1.LabelSelector{
+								MatchExpressions: []metav1.LabelSelectorRequirement{
+									{
+										Key:      "track",
+										Operator: metav1.LabelSelectorOpIn,
+										Values:   []string{"stable", "canary"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "invalid-pod-backend-label-selector-operator",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Pods: &lbcfapi.PodBackend{
+						Port: lbcfapi.PortSelector{
+							PortNumber: 80,
+							Protocol:   tcp,
+						},
+						ByLabel: &lbcfapi.SelectPodByLabel{
+							LabelSelector: &metav1.LabelSelector{
+								MatchExpressions: []metav1.LabelSelectorRequirement{
+									{
+										Key:      "track",
+										Operator: metav1.LabelSelectorOpExists,
+										Values:   []string{"stable"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid-pod-backend-both-selectors",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Pods: &lbcfapi.PodBackend{
+						Port: lbcfapi.PortSelector{
+							PortNumber: 80,
+							Protocol:   tcp,
+						},
+						ByLabel: &lbcfapi.SelectPodByLabel{
+							Selector: map[string]string{
+								"k1": "v1",
+							},
+							LabelSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{
+									"k1": "v1",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid-pod-backend-empty-label-selector",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Pods: &lbcfapi.PodBackend{
+						Port: lbcfapi.PortSelector{
+							PortNumber: 80,
+							Protocol:   tcp,
+						},
+						ByLabel: &lbcfapi.SelectPodByLabel{
+							LabelSelector: &metav1.LabelSelector{},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "valid-pod-backend-port-name",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Pods: &lbcfapi.PodBackend{
+						Port: lbcfapi.PortSelector{
+							PortName: "http",
+							Protocol: tcp,
+						},
+						ByName: []string{"pod-0"},
+					},
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "invalid-pod-backend-port-name-and-number",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Pods: &lbcfapi.PodBackend{
+						Port: lbcfapi.PortSelector{
+							PortNumber: 80,
+							PortName:   "http",
+							Protocol:   tcp,
+						},
+						ByName: []string{"pod-0"},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid-pod-backend-port-name",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Pods: &lbcfapi.PodBackend{
+						Port: lbcfapi.PortSelector{
+							PortName: "Invalid_Name",
+							Protocol: tcp,
+						},
+						ByName: []string{"pod-0"},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid-svc-backend-port-name",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Service: &lbcfapi.ServiceBackend{
+						Name: "svc-name",
+						Port: lbcfapi.PortSelector{
+							PortNumber: 80,
+							PortName:   "http",
+							Protocol:   tcp,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "valid-pod-backend-ports",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Pods: &lbcfapi.PodBackend{
+						Ports: []lbcfapi.BackendPort{
+							{PortSelector: lbcfapi.PortSelector{PortNumber: 80, Protocol: tcp}},
+							{PortSelector: lbcfapi.PortSelector{PortName: "https", Protocol: tcp}, Parameters: map[string]string{"p1": "v1"}},
+						},
+						ByName: []string{"pod-0"},
+					},
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "valid-svc-backend-ports",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Service: &lbcfapi.ServiceBackend{
+						Name: "svc-name",
+						Ports: []lbcfapi.BackendPort{
+							{PortSelector: lbcfapi.PortSelector{PortNumber: 80, Protocol: tcp}},
+							{PortSelector: lbcfapi.PortSelector{PortNumber: 443, Protocol: tcp}},
+						},
+					},
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "invalid-pod-backend-port-and-ports",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Pods: &lbcfapi.PodBackend{
+						Port: lbcfapi.PortSelector{PortNumber: 80, Protocol: tcp},
+						Ports: []lbcfapi.BackendPort{
+							{PortSelector: lbcfapi.PortSelector{PortNumber: 443, Protocol: tcp}},
+						},
+						ByName: []string{"pod-0"},
+					},
+				},
+			},
+		},
+		{
+			name: "invalid-svc-backend-duplicate-ports",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					Service: &lbcfapi.ServiceBackend{
+						Name: "svc-name",
+						Ports: []lbcfapi.BackendPort{
+							{PortSelector: lbcfapi.PortSelector{PortNumber: 80, Protocol: tcp}},
+							{PortSelector: lbcfapi.PortSelector{PortNumber: 80, Protocol: tcp}},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "valid-drain-policy",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName: "test-lb",
+					DrainPolicy: &lbcfapi.DrainPolicyConfig{
+						Period: lbcfapi.Duration{Duration: 30 * time.Second},
+					},
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "invalid-drain-policy-zero-period",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					LBName:      "test-lb",
+					DrainPolicy: &lbcfapi.DrainPolicyConfig{},
+				},
+			},
+		},
 	}
 	for _, c := range cases {
 		err := ValidateBackendGroup(c.group)
@@ -1212,3 +1612,387 @@ func TestBackendGroupUpdateFieldsAllowed(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateDriverTLS(t *testing.T) {
+	cases := []struct {
+		name        string
+		driverType  string
+		url         string
+		tls         lbcfapi.DriverTLSConfig
+		expectValid bool
+	}{
+		{
+			name:        "valid-webhook",
+			driverType:  string(lbcfapi.WebhookDriver),
+			url:         "https://driver.kube-system.svc",
+			tls:         lbcfapi.DriverTLSConfig{ClientCertSecretName: "client-cert"},
+			expectValid: true,
+		},
+		{
+			name:        "valid-grpc",
+			driverType:  string(lbcfapi.GRPCDriver),
+			url:         "driver.kube-system.svc:9090",
+			tls:         lbcfapi.DriverTLSConfig{ServerName: "driver"},
+			expectValid: true,
+		},
+		{
+			name:       "invalid-http-scheme",
+			driverType: string(lbcfapi.WebhookDriver),
+			url:        "http://driver.kube-system.svc",
+		},
+		{
+			name:       "invalid-ca-bundle",
+			driverType: string(lbcfapi.WebhookDriver),
+			url:        "https://driver.kube-system.svc",
+			tls:        lbcfapi.DriverTLSConfig{CABundle: []byte("invalid")},
+		},
+		{
+			name:       "invalid-secret-name",
+			driverType: string(lbcfapi.WebhookDriver),
+			url:        "https://driver.kube-system.svc",
+			tls:        lbcfapi.DriverTLSConfig{ClientCertSecretName: "Invalid_Name"},
+		},
+	}
+	for _, c := range cases {
+		err := validateDriverTLS(c.driverType, c.url, c.tls, field.NewPath("tls"))
+		if c.expectValid && len(err) > 0 {
+			t.Errorf("case %s, expect valid, get error: %v", c.name, err.ToAggregate().Error())
+		} else if !c.expectValid && len(err) == 0 {
+			t.Errorf("case %s, expect invalid, get valid", c.name)
+		}
+	}
+}
+
+func TestValidateDriverAuth(t *testing.T) {
+	cases := []struct {
+		name        string
+		driverType  string
+		auth        lbcfapi.DriverAuthConfig
+		expectValid bool
+	}{
+		{
+			name:        "valid-bearer-token",
+			driverType:  string(lbcfapi.GRPCDriver),
+			auth:        lbcfapi.DriverAuthConfig{Type: lbcfapi.BearerTokenAuth, SecretName: "driver-token"},
+			expectValid: true,
+		},
+		{
+			name:        "valid-hmac",
+			driverType:  string(lbcfapi.WebhookDriver),
+			auth:        lbcfapi.DriverAuthConfig{Type: lbcfapi.HMACAuth, SecretName: "driver-key"},
+			expectValid: true,
+		},
+		{
+			name:       "invalid-hmac-grpc",
+			driverType: string(lbcfapi.GRPCDriver),
+			auth:       lbcfapi.DriverAuthConfig{Type: lbcfapi.HMACAuth, SecretName: "driver-key"},
+		},
+		{
+			name:       "invalid-type",
+			driverType: string(lbcfapi.WebhookDriver),
+			auth:       lbcfapi.DriverAuthConfig{Type: "Basic", SecretName: "driver-key"},
+		},
+		{
+			name:       "missing-secret-name",
+			driverType: string(lbcfapi.WebhookDriver),
+			auth:       lbcfapi.DriverAuthConfig{Type: lbcfapi.BearerTokenAuth},
+		},
+	}
+	for _, c := range cases {
+		err := validateDriverAuth(c.driverType, c.auth, field.NewPath("auth"))
+		if c.expectValid && len(err) > 0 {
+			t.Errorf("case %s, expect valid, get error: %v", c.name, err.ToAggregate().Error())
+		} else if !c.expectValid && len(err) == 0 {
+			t.Errorf("case %s, expect invalid, get valid", c.name)
+		}
+	}
+}
+
+func TestValidateDriverService(t *testing.T) {
+	cases := []struct {
+		name        string
+		service     lbcfapi.DriverServiceReference
+		expectValid bool
+	}{
+		{
+			name:        "valid",
+			service:     lbcfapi.DriverServiceReference{Namespace: "kube-system", Name: "driver", Port: 80, Path: "/lbcf"},
+			expectValid: true,
+		},
+		{
+			name:        "valid-without-path",
+			service:     lbcfapi.DriverServiceReference{Namespace: "kube-system", Name: "driver", Port: 80},
+			expectValid: true,
+		},
+		{
+			name:    "invalid-port",
+			service: lbcfapi.DriverServiceReference{Namespace: "kube-system", Name: "driver"},
+		},
+		{
+			name:    "invalid-name",
+			service: lbcfapi.DriverServiceReference{Namespace: "kube-system", Port: 80},
+		},
+		{
+			name:    "invalid-path",
+			service: lbcfapi.DriverServiceReference{Namespace: "kube-system", Name: "driver", Port: 80, Path: "lbcf"},
+		},
+	}
+	for _, c := range cases {
+		err := validateDriverService(c.service, field.NewPath("service"))
+		if c.expectValid && len(err) > 0 {
+			t.Errorf("case %s, expect valid, get error: %v", c.name, err.ToAggregate().Error())
+		} else if !c.expectValid && len(err) == 0 {
+			t.Errorf("case %s, expect invalid, get valid", c.name)
+		}
+	}
+}
+
+func TestValidateDriverHealthCheck(t *testing.T) {
+	cases := []struct {
+		name        string
+		driverType  string
+		healthCheck lbcfapi.DriverHealthCheckConfig
+		expectValid bool
+	}{
+		{
+			name:        "valid-default",
+			driverType:  string(lbcfapi.WebhookDriver),
+			expectValid: true,
+		},
+		{
+			name:       "valid",
+			driverType: string(lbcfapi.WebhookDriver),
+			healthCheck: lbcfapi.DriverHealthCheckConfig{
+				Path:             "/ping",
+				Period:           &lbcfapi.Duration{Duration: 30 * time.Second},
+				Timeout:          &lbcfapi.Duration{Duration: 5 * time.Second},
+				FailureThreshold: 2,
+			},
+			expectValid: true,
+		},
+		{
+			name:        "invalid-path",
+			driverType:  string(lbcfapi.WebhookDriver),
+			healthCheck: lbcfapi.DriverHealthCheckConfig{Path: "ping"},
+		},
+		{
+			name:        "invalid-grpc-path",
+			driverType:  string(lbcfapi.GRPCDriver),
+			healthCheck: lbcfapi.DriverHealthCheckConfig{Path: "/ping"},
+		},
+		{
+			name:        "invalid-period",
+			driverType:  string(lbcfapi.WebhookDriver),
+			healthCheck: lbcfapi.DriverHealthCheckConfig{Period: &lbcfapi.Duration{}},
+		},
+		{
+			name:        "invalid-timeout",
+			driverType:  string(lbcfapi.WebhookDriver),
+			healthCheck: lbcfapi.DriverHealthCheckConfig{Timeout: &lbcfapi.Duration{Duration: 2 * time.Minute}},
+		},
+		{
+			name:        "invalid-failure-threshold",
+			driverType:  string(lbcfapi.WebhookDriver),
+			healthCheck: lbcfapi.DriverHealthCheckConfig{FailureThreshold: -1},
+		},
+	}
+	for _, c := range cases {
+		err := validateDriverHealthCheck(c.driverType, c.healthCheck, field.NewPath("healthCheck"))
+		if c.expectValid && len(err) > 0 {
+			t.Errorf("case %s, expect valid, get error: %v", c.name, err.ToAggregate().Error())
+		} else if !c.expectValid && len(err) == 0 {
+			t.Errorf("case %s, expect invalid, get valid", c.name)
+		}
+	}
+}
+
+func TestValidateDriverWebhooksOptional(t *testing.T) {
+	cases := []struct {
+		name        string
+		optional    string
+		expectValid bool
+	}{
+		{
+			name:        "valid-optional",
+			optional:    webhooks.ValidateBackend,
+			expectValid: true,
+		},
+		{
+			name:     "invalid-optional",
+			optional: webhooks.EnsureBackend,
+		},
+		{
+			name:     "invalid-optional-create-lb",
+			optional: webhooks.CreateLoadBalancer,
+		},
+		{
+			name:     "invalid-optional-delete-lb",
+			optional: webhooks.DeleteLoadBalancer,
+		},
+	}
+	for _, c := range cases {
+		var hooks []lbcfapi.WebhookConfig
+		for known := range webhooks.KnownWebhooks {
+			hooks = append(hooks, lbcfapi.WebhookConfig{
+				Name:     known,
+				Timeout:  lbcfapi.Duration{Duration: 10 * time.Second},
+				Optional: known == c.optional,
+			})
+		}
+		err := validateDriverWebhooks(hooks, field.NewPath("webhooks"))
+		if c.expectValid && len(err) > 0 {
+			t.Errorf("case %s, expect valid, get error: %v", c.name, err.ToAggregate().Error())
+		} else if !c.expectValid && len(err) == 0 {
+			t.Errorf("case %s, expect invalid, get valid", c.name)
+		}
+	}
+}
+
+func TestValidateDriverWebhooksOptIn(t *testing.T) {
+	var hooks []lbcfapi.WebhookConfig
+	for known := range webhooks.KnownWebhooks.Difference(webhooks.OptInWebhooks) {
+		hooks = append(hooks, lbcfapi.WebhookConfig{
+			Name:    known,
+			Timeout: lbcfapi.Duration{Duration: 10 * time.Second},
+		})
+	}
+	if err := validateDriverWebhooks(hooks, field.NewPath("webhooks")); len(err) > 0 {
+		t.Fatalf("expect valid without opt-in webhooks, get error: %v", err.ToAggregate().Error())
+	}
+}
+
+func TestValidateDriverProtocolVersion(t *testing.T) {
+	cases := []struct {
+		name        string
+		version     string
+		expectValid bool
+	}{
+		{
+			name:        "valid-default",
+			expectValid: true,
+		},
+		{
+			name:        "valid-v2",
+			version:     webhooks.ProtocolV2,
+			expectValid: true,
+		},
+		{
+			name:    "invalid-unsupported",
+			version: "v100",
+		},
+	}
+	for _, c := range cases {
+		driver := &lbcfapi.LoadBalancerDriver{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-driver",
+				Namespace: "default",
+			},
+			Spec: lbcfapi.LoadBalancerDriverSpec{
+				DriverType:      string(lbcfapi.WebhookDriver),
+				Url:             "http://1.1.1.1:80",
+				ProtocolVersion: c.version,
+			},
+		}
+		for known := range webhooks.KnownWebhooks {
+			driver.Spec.Webhooks = append(driver.Spec.Webhooks, lbcfapi.WebhookConfig{
+				Name:    known,
+				Timeout: lbcfapi.Duration{Duration: 10 * time.Second},
+			})
+		}
+		err := ValidateLoadBalancerDriver(driver)
+		if c.expectValid && len(err) > 0 {
+			t.Errorf("case %s, expect valid, get error: %v", c.name, err.ToAggregate().Error())
+		} else if !c.expectValid && len(err) == 0 {
+			t.Errorf("case %s, expect invalid, get valid", c.name)
+		}
+	}
+}
+
+func TestValidateExecDriver(t *testing.T) {
+	cases := []struct {
+		name        string
+		spec        lbcfapi.LoadBalancerDriverSpec
+		expectValid bool
+	}{
+		{
+			name: "valid",
+			spec: lbcfapi.LoadBalancerDriverSpec{
+				DriverType: string(lbcfapi.ExecDriver),
+				Exec: &lbcfapi.DriverExecConfig{
+					Command: "/opt/lbcf/driver",
+					Args:    []string{"--config", "/etc/driver.conf"},
+				},
+			},
+			expectValid: true,
+		},
+		{
+			name: "invalid-no-exec",
+			spec: lbcfapi.LoadBalancerDriverSpec{
+				DriverType: string(lbcfapi.ExecDriver),
+			},
+		},
+		{
+			name: "invalid-relative-command",
+			spec: lbcfapi.LoadBalancerDriverSpec{
+				DriverType: string(lbcfapi.ExecDriver),
+				Exec:       &lbcfapi.DriverExecConfig{Command: "driver"},
+			},
+		},
+		{
+			name: "invalid-url",
+			spec: lbcfapi.LoadBalancerDriverSpec{
+				DriverType: string(lbcfapi.ExecDriver),
+				Url:        "http://1.1.1.1:80",
+				Exec:       &lbcfapi.DriverExecConfig{Command: "/opt/lbcf/driver"},
+			},
+		},
+		{
+			name: "invalid-auth",
+			spec: lbcfapi.LoadBalancerDriverSpec{
+				DriverType: string(lbcfapi.ExecDriver),
+				Exec:       &lbcfapi.DriverExecConfig{Command: "/opt/lbcf/driver"},
+				Auth: &lbcfapi.DriverAuthConfig{
+					Type:       lbcfapi.BearerTokenAuth,
+					SecretName: "token",
+				},
+			},
+		},
+		{
+			name: "invalid-health-check-path",
+			spec: lbcfapi.LoadBalancerDriverSpec{
+				DriverType:  string(lbcfapi.ExecDriver),
+				Exec:        &lbcfapi.DriverExecConfig{Command: "/opt/lbcf/driver"},
+				HealthCheck: &lbcfapi.DriverHealthCheckConfig{Path: "/healthz"},
+			},
+		},
+		{
+			name: "invalid-exec-for-webhook-driver",
+			spec: lbcfapi.LoadBalancerDriverSpec{
+				DriverType: string(lbcfapi.WebhookDriver),
+				Url:        "http://1.1.1.1:80",
+				Exec:       &lbcfapi.DriverExecConfig{Command: "/opt/lbcf/driver"},
+			},
+		},
+	}
+	for _, c := range cases {
+		driver := &lbcfapi.LoadBalancerDriver{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-driver",
+				Namespace: "default",
+			},
+			Spec: c.spec,
+		}
+		for known := range webhooks.KnownWebhooks {
+			driver.Spec.Webhooks = append(driver.Spec.Webhooks, lbcfapi.WebhookConfig{
+				Name:    known,
+				Timeout: lbcfapi.Duration{Duration: 10 * time.Second},
+			})
+		}
+		err := ValidateLoadBalancerDriver(driver)
+		if c.expectValid && len(err) > 0 {
+			t.Errorf("case %s, expect valid, get error: %v", c.name, err.ToAggregate().Error())
+		} else if !c.expectValid && len(err) == 0 {
+			t.Errorf("case %s, expect invalid, get valid", c.name)
+		}
+	}
+}
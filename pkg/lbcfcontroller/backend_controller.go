@@ -20,6 +20,7 @@ package lbcfcontroller
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
 	lbcfclient "tkestack.io/lb-controlling-framework/pkg/client-go/clientset/versioned"
@@ -30,17 +31,23 @@ import (
 	apicore "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/record"
+	podutil "k8s.io/kubernetes/pkg/api/v1/pod"
 )
 
-func newBackendController(client lbcfclient.Interface, brLister v1beta1.BackendRecordLister, driverLister v1beta1.LoadBalancerDriverLister, podLister corev1.PodLister, svcLister corev1.ServiceLister, nodeLister corev1.NodeLister, recorder record.EventRecorder, invoker util.WebhookInvoker) *backendController {
+func newBackendController(client lbcfclient.Interface, k8sClient kubernetes.Interface, brLister v1beta1.BackendRecordLister, driverLister v1beta1.LoadBalancerDriverLister, lbLister v1beta1.LoadBalancerLister, bgLister v1beta1.BackendGroupLister, podLister corev1.PodLister, svcLister corev1.ServiceLister, nodeLister corev1.NodeLister, recorder record.EventRecorder, invoker util.WebhookInvoker) *backendController {
 	return &backendController{
 		client:             client,
+		k8sClient:          k8sClient,
 		brLister:           brLister,
 		driverLister:       driverLister,
+		lbLister:           lbLister,
+		bgLister:           bgLister,
 		podLister:          podLister,
 		svcLister:          svcLister,
 		nodeLister:         nodeLister,
@@ -52,8 +59,11 @@ func newBackendController(client lbcfclient.Interface, brLister v1beta1.BackendR
 
 type backendController struct {
 	client        lbcfclient.Interface
+	k8sClient     kubernetes.Interface
 	brLister      v1beta1.BackendRecordLister
 	driverLister  v1beta1.LoadBalancerDriverLister
+	lbLister      v1beta1.LoadBalancerLister
+	bgLister      v1beta1.BackendGroupLister
 	podLister     corev1.PodLister
 	svcLister     corev1.ServiceLister
 	nodeLister    corev1.NodeLister
@@ -95,6 +105,10 @@ func (c *backendController) generateBackendAddr(backend *lbcfapi.BackendRecord)
 	if err != nil {
 		return util.ErrorResult(fmt.Errorf("retrieve driver %q for BackendRecord %s failed: %v", backend.Spec.LBDriver, backend.Name, err))
 	}
+	if !util.IsDriverReady(driver) {
+		c.eventRecorder.Eventf(backend, apicore.EventTypeWarning, "DriverNotReady", "driver %s/%s is not ready", driver.Namespace, driver.Name)
+		return util.FailResult(util.GetDuration(driver.Spec.HealthCheck.Period, util.DefaultHealthCheckPeriod), "driver not ready")
+	}
 
 	var rsp *webhooks.GenerateBackendAddrResponse
 	if backend.Spec.PodBackendInfo != nil {
@@ -147,6 +161,10 @@ func (c *backendController) ensureBackend(backend *lbcfapi.BackendRecord) *util.
 	if err != nil {
 		return util.ErrorResult(fmt.Errorf("retrieve driver %q for BackendRecord %s failed: %v", backend.Spec.LBDriver, backend.Name, err))
 	}
+	if !util.IsDriverReady(driver) {
+		c.eventRecorder.Eventf(backend, apicore.EventTypeWarning, "DriverNotReady", "driver %s/%s is not ready", driver.Namespace, driver.Name)
+		return util.FailResult(util.GetDuration(driver.Spec.HealthCheck.Period, util.DefaultHealthCheckPeriod), "driver not ready")
+	}
 
 	req := &webhooks.BackendOperationRequest{
 		RequestForRetryHooks: webhooks.RequestForRetryHooks{
@@ -180,6 +198,10 @@ func (c *backendController) ensureBackend(backend *lbcfapi.BackendRecord) *util.
 			return util.ErrorResult(err)
 		}
 		c.eventRecorder.Eventf(backend, apicore.EventTypeNormal, "SuccEnsureBackend", "Successfully ensured backend")
+		if err := c.syncPodReadinessGate(backend); err != nil {
+			c.eventRecorder.Eventf(backend, apicore.EventTypeWarning, "FailedSetReadinessGate", "update pod status failed: %v", err)
+			return util.ErrorResult(err)
+		}
 		if backend.Spec.EnsurePolicy != nil && backend.Spec.EnsurePolicy.Policy == lbcfapi.PolicyAlways {
 			return util.PeriodicResult(util.GetDuration(backend.Spec.EnsurePolicy.MinPeriod, util.DefaultEnsurePeriod))
 		}
@@ -221,6 +243,15 @@ func (c *backendController) deregisterBackend(backend *lbcfapi.BackendRecord) *u
 	if err != nil {
 		return util.ErrorResult(fmt.Errorf("retrieve driver %q for BackendRecord %s failed: %v", backend.Spec.LBDriver, backend.Name, err))
 	}
+	if !util.IsDriverReady(driver) {
+		c.eventRecorder.Eventf(backend, apicore.EventTypeWarning, "DriverNotReady", "driver %s/%s is not ready", driver.Namespace, driver.Name)
+		return util.FailResult(util.GetDuration(driver.Spec.HealthCheck.Period, util.DefaultHealthCheckPeriod), "driver not ready")
+	}
+	backend, result := c.drainBackend(backend, driver)
+	if result != nil {
+		return result
+	}
+
 	req := &webhooks.BackendOperationRequest{
 		RequestForRetryHooks: webhooks.RequestForRetryHooks{
 			RecordID: fmt.Sprintf("deregisterBackend(%s)", backend.UID),
@@ -251,9 +282,100 @@ func (c *backendController) deregisterBackend(backend *lbcfapi.BackendRecord) *u
 	}
 }
 
+// drainBackend keeps backend in Draining condition until the drain period ends or the driver reports it is drained.
+// It returns a nil SyncResult if backend is ready to be deregistered, together with the latest version of backend
+func (c *backendController) drainBackend(backend *lbcfapi.BackendRecord, driver *lbcfapi.LoadBalancerDriver) (*lbcfapi.BackendRecord, *util.SyncResult) {
+	if backend.Spec.DrainPolicy == nil || !util.BackendRegistered(backend) {
+		return backend, nil
+	}
+	cond := util.GetBackendRecordCondition(&backend.Status, lbcfapi.BackendDraining)
+	if cond == nil {
+		cpy := backend.DeepCopy()
+		util.AddBackendCondition(&cpy.Status, lbcfapi.BackendRecordCondition{
+			Type:               lbcfapi.BackendDraining,
+			Status:             lbcfapi.ConditionTrue,
+			LastTransitionTime: v1.Now(),
+			Message:            fmt.Sprintf("drain period: %s", backend.Spec.DrainPolicy.Period.Duration.String()),
+		})
+		updated, err := c.client.LbcfV1beta1().BackendRecords(cpy.Namespace).UpdateStatus(cpy)
+		if err != nil {
+			return backend, util.ErrorResult(err)
+		}
+		c.eventRecorder.Eventf(backend, apicore.EventTypeNormal, "StartDraining", "drain period: %s", backend.Spec.DrainPolicy.Period.Duration.String())
+		backend = updated
+		cond = util.GetBackendRecordCondition(&backend.Status, lbcfapi.BackendDraining)
+	} else if cond.Status != lbcfapi.ConditionTrue {
+		return backend, nil
+	}
+
+	remaining := backend.Spec.DrainPolicy.Period.Duration - time.Since(cond.LastTransitionTime.Time)
+	if remaining <= 0 {
+		return c.finishDraining(backend, "drain period ends")
+	}
+	if !util.IsWebhookConfigured(driver, webhooks.DrainBackend) {
+		return backend, util.AsyncResult(remaining)
+	}
+
+	req := &webhooks.BackendOperationRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{
+			RecordID: fmt.Sprintf("drainBackend(%s)", backend.UID),
+			RetryID:  string(uuid.NewUUID()),
+		},
+		LBInfo:       backend.Spec.LBInfo,
+		BackendAddr:  backend.Status.BackendAddr,
+		Parameters:   backend.Spec.Parameters,
+		InjectedInfo: backend.Status.InjectedInfo,
+	}
+	rsp, err := c.webhookInvoker.CallDrainBackend(driver, req)
+	if err != nil {
+		return backend, util.ErrorResult(err)
+	}
+	switch rsp.Status {
+	case webhooks.StatusSucc:
+		return c.finishDraining(backend, rsp.Msg)
+	case webhooks.StatusFail:
+		c.eventRecorder.Eventf(backend, apicore.EventTypeWarning, "FailedDrain", "msg: %s", rsp.Msg)
+		return backend, util.FailResult(minDuration(util.CalculateRetryInterval(rsp.MinRetryDelayInSeconds), remaining), rsp.Msg)
+	case webhooks.StatusRunning:
+		c.eventRecorder.Eventf(backend, apicore.EventTypeNormal, "RunningDrain", "msg: %s", rsp.Msg)
+		return backend, util.AsyncResult(minDuration(util.CalculateRetryInterval(rsp.MinRetryDelayInSeconds), remaining))
+	default:
+		c.eventRecorder.Eventf(backend, apicore.EventTypeWarning, "InvalidDrain", "unsupported status: %s, msg: %s", rsp.Status, rsp.Msg)
+		return backend, util.ErrorResult(fmt.Errorf("unknown status %q", rsp.Status))
+	}
+}
+
+func (c *backendController) finishDraining(backend *lbcfapi.BackendRecord, msg string) (*lbcfapi.BackendRecord, *util.SyncResult) {
+	cpy := backend.DeepCopy()
+	util.AddBackendCondition(&cpy.Status, lbcfapi.BackendRecordCondition{
+		Type:               lbcfapi.BackendDraining,
+		Status:             lbcfapi.ConditionFalse,
+		LastTransitionTime: v1.Now(),
+		Message:            msg,
+	})
+	updated, err := c.client.LbcfV1beta1().BackendRecords(cpy.Namespace).UpdateStatus(cpy)
+	if err != nil {
+		return backend, util.ErrorResult(err)
+	}
+	c.eventRecorder.Eventf(backend, apicore.EventTypeNormal, "SuccDrain", "msg: %s", msg)
+	return updated, nil
+}
+
+func minDuration(a, b time.Duration) time.Duration {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 func (c *backendController) removeFinalizer(backend *lbcfapi.BackendRecord) *util.SyncResult {
 	c.removeDeletingRecord(backend)
 
+	// the remaining BackendRecords of the pod may be all registered now
+	if err := c.syncPodReadinessGate(backend); err != nil {
+		return util.ErrorResult(err)
+	}
+
 	backend = backend.DeepCopy()
 	backend.Finalizers = util.RemoveFinalizer(backend.Finalizers, lbcfapi.FinalizerDeregisterBackend)
 	_, err := c.client.LbcfV1beta1().BackendRecords(backend.Namespace).Update(backend)
@@ -263,6 +385,103 @@ func (c *backendController) removeFinalizer(backend *lbcfapi.BackendRecord) *uti
 	return util.FinishedResult()
 }
 
+// syncPodReadinessGate sets the readiness gate condition of the pod to True once all BackendRecords of the pod are registered.
+// backend is the latest version of the BackendRecord being synced, which may not be observed by brLister yet
+func (c *backendController) syncPodReadinessGate(backend *lbcfapi.BackendRecord) error {
+	if backend.Spec.PodBackendInfo == nil {
+		return nil
+	}
+	pod, err := c.podLister.Pods(backend.Namespace).Get(backend.Spec.PodBackendInfo.Name)
+	if errors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if pod.DeletionTimestamp != nil || !util.HasBackendsRegisteredGate(pod) {
+		return nil
+	}
+
+	// the gate is released if the BackendGroups are deleted, their LoadBalancers are deleted, or readinessGate is disabled
+	gated, err := podGatedByBackendGroups(pod, c.bgLister, c.lbLister)
+	if err != nil {
+		return err
+	}
+	if !gated {
+		return setPodBackendsRegistered(c.k8sClient, pod, podNotGatedMsg)
+	}
+
+	all, err := c.brLister.BackendRecords(backend.Namespace).List(labels.SelectorFromSet(labels.Set{
+		lbcfapi.LabelPodName: pod.Name,
+	}))
+	if err != nil {
+		return err
+	}
+	var remaining []*lbcfapi.BackendRecord
+	if backend.DeletionTimestamp == nil {
+		remaining = append(remaining, backend)
+	}
+	for _, record := range all {
+		if record.Name != backend.Name && record.DeletionTimestamp == nil {
+			remaining = append(remaining, record)
+		}
+	}
+	if len(remaining) == 0 {
+		return nil
+	}
+	for _, record := range remaining {
+		if !util.BackendRegistered(record) {
+			return nil
+		}
+	}
+
+	return setPodBackendsRegistered(c.k8sClient, pod, fmt.Sprintf("registered to %d load balancers", len(remaining)))
+}
+
+const podNotGatedMsg = "no BackendGroup with readinessGate is going to register the pod"
+
+// podGatedByBackendGroups returns true if any BackendGroup with readinessGate enabled is going to register pod,
+// which means the LoadBalancer of the BackendGroup is created and the pod exposes at least one port of the BackendGroup
+func podGatedByBackendGroups(pod *apicore.Pod, bgLister v1beta1.BackendGroupLister, lbLister v1beta1.LoadBalancerLister) (bool, error) {
+	groups, err := bgLister.BackendGroups(pod.Namespace).List(labels.Everything())
+	if err != nil {
+		return false, err
+	}
+	for _, group := range groups {
+		if group.DeletionTimestamp != nil || group.Spec.Pods == nil || !group.Spec.Pods.ReadinessGate || !util.IsPodMatchBackendGroup(group, pod) {
+			continue
+		}
+		lb, err := lbLister.LoadBalancers(group.Namespace).Get(group.Spec.LBName)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		if lb.DeletionTimestamp != nil || !util.LBCreated(lb) {
+			continue
+		}
+		for _, port := range util.BackendPorts(group) {
+			if _, ok := util.ResolvePodPort(pod, port.PortSelector); ok {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+func setPodBackendsRegistered(k8sClient kubernetes.Interface, pod *apicore.Pod, msg string) error {
+	cpy := pod.DeepCopy()
+	changed := podutil.UpdatePodCondition(&cpy.Status, &apicore.PodCondition{
+		Type:    apicore.PodConditionType(lbcfapi.PodConditionBackendsRegistered),
+		Status:  apicore.ConditionTrue,
+		Message: msg,
+	})
+	if !changed {
+		return nil
+	}
+	_, err := k8sClient.CoreV1().Pods(cpy.Namespace).UpdateStatus(cpy)
+	return err
+}
+
 func (c *backendController) storeDeletingBackend(backend *lbcfapi.BackendRecord) {
 	key := fmt.Sprintf("%s|%s", backend.Spec.LBInfo, backend.Status.BackendAddr)
 	value := util.NamespacedNameKeyFunc(backend.Namespace, backend.Name)
@@ -26,24 +26,29 @@ import (
 	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
 	"tkestack.io/lb-controlling-framework/pkg/client-go/clientset/versioned/fake"
 	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/util"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
 
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/kubernetes/pkg/controller"
 )
 
 func TestBackendGenerateAddr(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	fakeClient := fake.NewSimpleClientset(backend)
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -74,17 +79,20 @@ func TestBackendGenerateSvcAddr(t *testing.T) {
 	svc := newFakeService("", "test-svc", v12.ServiceTypeNodePort)
 	node := newFakeNode("", "node")
 	bg := newFakeBackendGroupOfService("", "bg", lb.Name, 80, "TCP", svc.Name)
-	backend := util.ConstructServiceBackendRecord(lb, bg, svc, node)
+	backend := util.ConstructServiceBackendRecord(lb, bg, svc, node, util.BackendPorts(bg)[0])
 	fakeClient := fake.NewSimpleClientset(backend)
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{},
 		&fakeSvcListerWithStore{
 			store: map[string]*v12.Service{
@@ -125,12 +133,15 @@ func TestBackendGenerateStaticAddr(t *testing.T) {
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{},
 		&fakeSvcListerWithStore{},
 		&fakeNodeListerWithStore{},
@@ -157,17 +168,20 @@ func TestBackendGenerateStaticAddr(t *testing.T) {
 func TestBackendGenerateAddrFailed(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	fakeClient := fake.NewSimpleClientset(backend)
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -200,17 +214,20 @@ func TestBackendGenerateAddrFailed(t *testing.T) {
 func TestBackendGenerateAddrRunning(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	fakeClient := fake.NewSimpleClientset(backend)
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -243,17 +260,20 @@ func TestBackendGenerateAddrRunning(t *testing.T) {
 func TestBackendGenerateAddrInvalidResponse(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	fakeClient := fake.NewSimpleClientset(backend)
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -287,18 +307,21 @@ func TestBackendEnsure(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
 	//ts := v1.Time{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)}
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	backend.Status.BackendAddr = "fake.addr.com:1234"
 	fakeClient := fake.NewSimpleClientset(backend)
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -329,18 +352,21 @@ func TestBackendEnsureFailed(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
 	//ts := v1.Time{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)}
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	backend.Status.BackendAddr = "fake.addr.com:1234"
 	fakeClient := fake.NewSimpleClientset(backend)
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -375,7 +401,7 @@ func TestBackendEnsureRunning(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
 	ts := v1.Time{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)}
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	backend.Spec.EnsurePolicy = &lbcfapi.EnsurePolicyConfig{
 		Policy: lbcfapi.PolicyAlways,
 	}
@@ -390,12 +416,15 @@ func TestBackendEnsureRunning(t *testing.T) {
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -425,7 +454,7 @@ func TestBackendEnsureRerun(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
 	ts := v1.Time{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)}
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	backend.Spec.EnsurePolicy = &lbcfapi.EnsurePolicyConfig{
 		Policy: lbcfapi.PolicyIfNotSucc,
 	}
@@ -440,12 +469,15 @@ func TestBackendEnsureRerun(t *testing.T) {
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -474,18 +506,21 @@ func TestBackendEnsureInvalidResponse(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
 	//ts := v1.Time{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)}
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	backend.Status.BackendAddr = "fake.addr.com:1234"
 	fakeClient := fake.NewSimpleClientset(backend)
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -511,7 +546,7 @@ func TestBackendDeregister(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
 	ts := v1.Time{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)}
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	backend.DeletionTimestamp = &ts
 	backend.Finalizers = []string{lbcfapi.FinalizerDeregisterBackend}
 	backend.Spec.EnsurePolicy = &lbcfapi.EnsurePolicyConfig{
@@ -529,12 +564,15 @@ func TestBackendDeregister(t *testing.T) {
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -557,7 +595,7 @@ func TestBackendDeregisterFailed(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
 	ts := v1.Time{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)}
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	backend.DeletionTimestamp = &ts
 	backend.Finalizers = []string{lbcfapi.FinalizerDeregisterBackend}
 	backend.Spec.EnsurePolicy = &lbcfapi.EnsurePolicyConfig{
@@ -575,12 +613,15 @@ func TestBackendDeregisterFailed(t *testing.T) {
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -610,7 +651,7 @@ func TestBackendDeregisterRunning(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
 	ts := v1.Time{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)}
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	backend.DeletionTimestamp = &ts
 	backend.Finalizers = []string{lbcfapi.FinalizerDeregisterBackend}
 	backend.Spec.EnsurePolicy = &lbcfapi.EnsurePolicyConfig{
@@ -628,12 +669,15 @@ func TestBackendDeregisterRunning(t *testing.T) {
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -663,7 +707,7 @@ func TestBackendDeregisterInvalidResponse(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
 	ts := v1.Time{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)}
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	backend.DeletionTimestamp = &ts
 	backend.Finalizers = []string{lbcfapi.FinalizerDeregisterBackend}
 	backend.Spec.EnsurePolicy = &lbcfapi.EnsurePolicyConfig{
@@ -681,12 +725,15 @@ func TestBackendDeregisterInvalidResponse(t *testing.T) {
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -716,7 +763,7 @@ func TestBackendDeregisterEmptyAddr(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
 	ts := v1.Time{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)}
-	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	backend.DeletionTimestamp = &ts
 	backend.Finalizers = []string{lbcfapi.FinalizerDeregisterBackend}
 	backend.Spec.EnsurePolicy = &lbcfapi.EnsurePolicyConfig{
@@ -733,12 +780,15 @@ func TestBackendDeregisterEmptyAddr(t *testing.T) {
 	store := make(map[string]string)
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeBackendLister{
 			get: backend,
 		},
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -763,7 +813,7 @@ func TestBackendSameAddrOperation(t *testing.T) {
 	ts := v1.Time{time.Date(2000, 1, 1, 12, 0, 0, 0, time.UTC)}
 
 	// oldBackend is deleting
-	oldBackend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false))
+	oldBackend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
 	oldBackend.DeletionTimestamp = &ts
 	oldBackend.Finalizers = []string{lbcfapi.FinalizerDeregisterBackend}
 	oldBackend.Spec.LBInfo = map[string]string{
@@ -784,7 +834,7 @@ func TestBackendSameAddrOperation(t *testing.T) {
 	// newBackend has the same backendAddr and lbInfo
 	pod2 := newFakePod("", "pod-0", nil, true, false)
 	pod2.UID = "anotherUID"
-	newBackend := util.ConstructPodBackendRecord(lb, bg, pod2)
+	newBackend := util.ConstructPodBackendRecord(lb, bg, pod2, util.BackendPorts(bg)[0])
 	newBackend.Finalizers = []string{lbcfapi.FinalizerDeregisterBackend}
 	newBackend.Spec.LBInfo = map[string]string{
 		"lbID": "1234",
@@ -801,10 +851,13 @@ func TestBackendSameAddrOperation(t *testing.T) {
 	backendLister.store[newBackend.Name] = newBackend
 	ctrl := newBackendController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		backendLister,
 		&fakeDriverLister{
 			get: newFakeDriver("", "driver"),
 		},
+		&fakeLBLister{},
+		&fakeBackendGroupLister{},
 		&fakePodLister{
 			get: newFakePod("", "pod=0", nil, true, false),
 		},
@@ -866,3 +919,297 @@ func TestBackendSameAddrOperation(t *testing.T) {
 	}
 	delete(store, newBackend.Name)
 }
+
+func TestBackendEnsureReadinessGate(t *testing.T) {
+	lb1 := newFakeLoadBalancer("", "lb-1", nil, nil)
+	fakeLBEnsured(lb1)
+	lb2 := newFakeLoadBalancer("", "lb-2", nil, nil)
+	bg := newFakeBackendGroupOfPods("", "group", lb1.Name, 80, "tcp", nil, nil, []string{"pod-0"})
+	bg.Spec.Pods.ReadinessGate = true
+	pod := newFakePod("", "pod-0", nil, true, false)
+	pod.Spec.ReadinessGates = []v12.PodReadinessGate{
+		{ConditionType: lbcfapi.PodConditionBackendsRegistered},
+	}
+	backend1 := util.ConstructPodBackendRecord(lb1, bg, pod, util.BackendPorts(bg)[0])
+	backend1.Status.BackendAddr = "fake.addr.com:1234"
+	backend2 := util.ConstructPodBackendRecord(lb2, bg, pod, util.BackendPorts(bg)[0])
+	backend2.Status.BackendAddr = "fake.addr.com:1234"
+
+	backendLister := newFakeBackendListerWithStore()
+	backendLister.store[backend1.Name] = backend1
+	backendLister.store[backend2.Name] = backend2
+	k8sClient := k8sfake.NewSimpleClientset(pod)
+	ctrl := newBackendController(
+		fake.NewSimpleClientset(backend1, backend2),
+		k8sClient,
+		backendLister,
+		&fakeDriverLister{
+			get: newFakeDriver("", "driver"),
+		},
+		&fakeLBLister{
+			get: lb1,
+		},
+		&fakeBackendGroupLister{
+			list: []*lbcfapi.BackendGroup{bg},
+		},
+		&fakePodLister{
+			get: pod,
+		},
+		&fakeSvcListerWithStore{},
+		&fakeNodeListerWithStore{},
+		&fakeEventRecorder{store: make(map[string]string)},
+		&fakeSuccInvoker{})
+
+	// backend2 is not registered yet
+	key, _ := controller.KeyFunc(backend1)
+	resp := ctrl.syncBackendRecord(key)
+	if !resp.IsFinished() {
+		t.Fatalf("expect succ result, get %#v, err: %v", resp, resp.GetFailReason())
+	}
+	get, _ := k8sClient.CoreV1().Pods(pod.Namespace).Get(pod.Name, v1.GetOptions{})
+	if len(get.Status.Conditions) != 1 {
+		t.Fatalf("expect readiness gate not set, get %#v", get.Status.Conditions)
+	}
+
+	// both are registered
+	backendLister.store[backend1.Name] = backend1.DeepCopy()
+	util.AddBackendCondition(&backendLister.store[backend1.Name].Status, lbcfapi.BackendRecordCondition{
+		Type:   lbcfapi.BackendRegistered,
+		Status: lbcfapi.ConditionTrue,
+	})
+	key, _ = controller.KeyFunc(backend2)
+	resp = ctrl.syncBackendRecord(key)
+	if !resp.IsFinished() {
+		t.Fatalf("expect succ result, get %#v, err: %v", resp, resp.GetFailReason())
+	}
+	get, _ = k8sClient.CoreV1().Pods(pod.Namespace).Get(pod.Name, v1.GetOptions{})
+	found := false
+	for _, cond := range get.Status.Conditions {
+		if cond.Type == v12.PodConditionType(lbcfapi.PodConditionBackendsRegistered) {
+			found = true
+			if cond.Status != v12.ConditionTrue {
+				t.Fatalf("expect readiness gate %s, get %s", v12.ConditionTrue, cond.Status)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expect readiness gate set, get %#v", get.Status.Conditions)
+	}
+}
+
+func TestBackendReleaseReadinessGate(t *testing.T) {
+	lb := newFakeLoadBalancer("", "lb", nil, nil)
+	fakeLBEnsured(lb)
+	bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
+	bg.Spec.Pods.ReadinessGate = true
+	pod := newFakePod("", "pod-0", nil, true, false)
+	pod.Spec.ReadinessGates = []v12.PodReadinessGate{
+		{ConditionType: lbcfapi.PodConditionBackendsRegistered},
+	}
+	backend := util.ConstructPodBackendRecord(lb, bg, pod, util.BackendPorts(bg)[0])
+	backend.Status.BackendAddr = "fake.addr.com:1234"
+	ts := v1.Now()
+	backend.DeletionTimestamp = &ts
+	backend.Finalizers = []string{lbcfapi.FinalizerDeregisterBackend}
+
+	disabled := bg.DeepCopy()
+	disabled.Spec.Pods.ReadinessGate = false
+	deletingLB := lb.DeepCopy()
+	deletingLB.DeletionTimestamp = &ts
+
+	cases := []struct {
+		name   string
+		lb     *lbcfapi.LoadBalancer
+		groups []*lbcfapi.BackendGroup
+		expect v12.ConditionStatus
+	}{
+		{
+			name:   "group-deleted",
+			lb:     lb,
+			expect: v12.ConditionTrue,
+		},
+		{
+			name:   "readiness-gate-disabled",
+			lb:     lb,
+			groups: []*lbcfapi.BackendGroup{disabled},
+			expect: v12.ConditionTrue,
+		},
+		{
+			name:   "lb-deleted",
+			groups: []*lbcfapi.BackendGroup{bg},
+			expect: v12.ConditionTrue,
+		},
+		{
+			name:   "lb-deleting",
+			lb:     deletingLB,
+			groups: []*lbcfapi.BackendGroup{bg},
+			expect: v12.ConditionTrue,
+		},
+		{
+			name:   "still-gated",
+			lb:     lb,
+			groups: []*lbcfapi.BackendGroup{bg},
+		},
+	}
+	for _, c := range cases {
+		k8sClient := k8sfake.NewSimpleClientset(pod)
+		ctrl := newBackendController(
+			fake.NewSimpleClientset(backend),
+			k8sClient,
+			&fakeBackendLister{
+				get: backend,
+			},
+			&fakeDriverLister{
+				get: newFakeDriver("", "driver"),
+			},
+			&fakeLBLister{
+				get: c.lb,
+			},
+			&fakeBackendGroupLister{
+				list: c.groups,
+			},
+			&fakePodLister{
+				get: pod,
+			},
+			&fakeSvcListerWithStore{},
+			&fakeNodeListerWithStore{},
+			&fakeEventRecorder{store: make(map[string]string)},
+			&fakeSuccInvoker{})
+		key, _ := controller.KeyFunc(backend)
+		resp := ctrl.syncBackendRecord(key)
+		if !resp.IsFinished() {
+			t.Fatalf("case %s: expect succ result, get %#v, err: %v", c.name, resp, resp.GetFailReason())
+		}
+		get, _ := k8sClient.CoreV1().Pods(pod.Namespace).Get(pod.Name, v1.GetOptions{})
+		var status v12.ConditionStatus
+		for _, cond := range get.Status.Conditions {
+			if cond.Type == v12.PodConditionType(lbcfapi.PodConditionBackendsRegistered) {
+				status = cond.Status
+			}
+		}
+		if status != c.expect {
+			t.Fatalf("case %s: expect readiness gate %q, get %q", c.name, c.expect, status)
+		}
+	}
+}
+
+func TestBackendDrain(t *testing.T) {
+	newDrainingBackend := func(drainStart *v1.Time) *lbcfapi.BackendRecord {
+		lb := newFakeLoadBalancer("", "lb", nil, nil)
+		bg := newFakeBackendGroupOfPods("", "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
+		ts := v1.Now()
+		backend := util.ConstructPodBackendRecord(lb, bg, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(bg)[0])
+		backend.DeletionTimestamp = &ts
+		backend.Finalizers = []string{lbcfapi.FinalizerDeregisterBackend}
+		backend.Spec.DrainPolicy = &lbcfapi.DrainPolicyConfig{
+			Period: lbcfapi.Duration{Duration: time.Hour},
+		}
+		backend.Status.BackendAddr = "fake.addr.com:1234"
+		backend.Status.Conditions = []lbcfapi.BackendRecordCondition{
+			{
+				Type:   lbcfapi.BackendRegistered,
+				Status: lbcfapi.ConditionTrue,
+			},
+		}
+		if drainStart != nil {
+			backend.Status.Conditions = append(backend.Status.Conditions, lbcfapi.BackendRecordCondition{
+				Type:               lbcfapi.BackendDraining,
+				Status:             lbcfapi.ConditionTrue,
+				LastTransitionTime: *drainStart,
+			})
+		}
+		return backend
+	}
+	drainingDriver := newFakeDriver("", "driver")
+	drainingDriver.Spec.Webhooks = []lbcfapi.WebhookConfig{
+		{
+			Name: webhooks.DrainBackend,
+		},
+	}
+	longAgo := v1.NewTime(time.Now().Add(-2 * time.Hour))
+	recently := v1.Now()
+
+	cases := []struct {
+		name             string
+		backend          *lbcfapi.BackendRecord
+		driver           *lbcfapi.LoadBalancerDriver
+		invoker          util.WebhookInvoker
+		expectFinished   bool
+		expectDraining   bool
+		expectFinalizers int
+	}{
+		{
+			name:             "start-draining",
+			backend:          newDrainingBackend(nil),
+			driver:           newFakeDriver("", "driver"),
+			invoker:          &fakeSuccInvoker{},
+			expectDraining:   true,
+			expectFinalizers: 1,
+		},
+		{
+			name:             "draining-in-period",
+			backend:          newDrainingBackend(&recently),
+			driver:           newFakeDriver("", "driver"),
+			invoker:          &fakeSuccInvoker{},
+			expectDraining:   true,
+			expectFinalizers: 1,
+		},
+		{
+			name:             "drain-period-ends",
+			backend:          newDrainingBackend(&longAgo),
+			driver:           newFakeDriver("", "driver"),
+			invoker:          &fakeSuccInvoker{},
+			expectFinished:   true,
+			expectFinalizers: 0,
+		},
+		{
+			name:             "drained-by-driver",
+			backend:          newDrainingBackend(&recently),
+			driver:           drainingDriver,
+			invoker:          &fakeSuccInvoker{},
+			expectFinished:   true,
+			expectFinalizers: 0,
+		},
+		{
+			name:             "draining-by-driver",
+			backend:          newDrainingBackend(&recently),
+			driver:           drainingDriver,
+			invoker:          &fakeRunningInvoker{},
+			expectDraining:   true,
+			expectFinalizers: 1,
+		},
+	}
+	for _, c := range cases {
+		fakeClient := fake.NewSimpleClientset(c.backend)
+		ctrl := newBackendController(
+			fakeClient,
+			k8sfake.NewSimpleClientset(),
+			&fakeBackendLister{
+				get: c.backend,
+			},
+			&fakeDriverLister{
+				get: c.driver,
+			},
+			&fakeLBLister{},
+			&fakeBackendGroupLister{},
+			&fakePodLister{},
+			&fakeSvcListerWithStore{},
+			&fakeNodeListerWithStore{},
+			&fakeEventRecorder{store: make(map[string]string)},
+			c.invoker)
+		key, _ := controller.KeyFunc(c.backend)
+		resp := ctrl.syncBackendRecord(key)
+		if c.expectFinished && !resp.IsFinished() {
+			t.Errorf("case %s, expect succ result, get %#v, err: %v", c.name, resp, resp.GetFailReason())
+		} else if !c.expectFinished && !resp.IsRunning() {
+			t.Errorf("case %s, expect running result, get %#v, err: %v", c.name, resp, resp.GetFailReason())
+		}
+		get, _ := fakeClient.LbcfV1beta1().BackendRecords(c.backend.Namespace).Get(c.backend.Name, v1.GetOptions{})
+		if util.BackendDraining(get) != c.expectDraining {
+			t.Errorf("case %s, expect draining %v, get %#v", c.name, c.expectDraining, get.Status.Conditions)
+		}
+		if len(get.Finalizers) != c.expectFinalizers {
+			t.Errorf("case %s, expect %d finalizers, get %#v", c.name, c.expectFinalizers, get.Finalizers)
+		}
+	}
+}
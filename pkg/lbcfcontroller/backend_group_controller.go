@@ -32,8 +32,10 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
 	corev1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"k8s.io/klog"
 	"k8s.io/kubernetes/pkg/controller"
@@ -41,37 +43,51 @@ import (
 
 func newBackendGroupController(
 	client lbcfclient.Interface,
+	k8sClient kubernetes.Interface,
 	lbLister lbcflister.LoadBalancerLister,
 	bgLister lbcflister.BackendGroupLister,
 	brLister lbcflister.BackendRecordLister,
 	podLister corev1.PodLister,
 	svcLister corev1.ServiceLister,
-	nodeLister corev1.NodeLister) *backendGroupController {
+	nodeLister corev1.NodeLister,
+	endpointsLister corev1.EndpointsLister,
+	recorder record.EventRecorder) *backendGroupController {
 	return &backendGroupController{
 		client:              client,
+		k8sClient:           k8sClient,
 		lbLister:            lbLister,
 		bgLister:            bgLister,
 		brLister:            brLister,
 		podLister:           podLister,
 		serviceLister:       svcLister,
 		nodeLister:          nodeLister,
+		endpointsLister:     endpointsLister,
+		eventRecorder:       recorder,
 		relatedLoadBalancer: &sync.Map{},
 		relatedPod:          &sync.Map{},
+		portNotFound:        &sync.Map{},
 	}
 }
 
 type backendGroupController struct {
-	client lbcfclient.Interface
+	client    lbcfclient.Interface
+	k8sClient kubernetes.Interface
 
-	lbLister      lbcflister.LoadBalancerLister
-	bgLister      lbcflister.BackendGroupLister
-	brLister      lbcflister.BackendRecordLister
-	podLister     corev1.PodLister
-	serviceLister corev1.ServiceLister
-	nodeLister    corev1.NodeLister
+	lbLister        lbcflister.LoadBalancerLister
+	bgLister        lbcflister.BackendGroupLister
+	brLister        lbcflister.BackendRecordLister
+	podLister       corev1.PodLister
+	serviceLister   corev1.ServiceLister
+	nodeLister      corev1.NodeLister
+	endpointsLister corev1.EndpointsLister
+	eventRecorder   record.EventRecorder
 
 	relatedLoadBalancer *sync.Map
 	relatedPod          *sync.Map
+
+	// portNotFound maps the key of a BackendGroup to a set of pod ports that are skipped in the last sync,
+	// so that event PortNotFound is emitted only when a pod port is newly skipped
+	portNotFound *sync.Map
 }
 
 func (c *backendGroupController) syncBackendGroup(key string) *util.SyncResult {
@@ -81,6 +97,10 @@ func (c *backendGroupController) syncBackendGroup(key string) *util.SyncResult {
 	}
 	group, err := c.bgLister.BackendGroups(namespace).Get(name)
 	if errors.IsNotFound(err) {
+		c.portNotFound.Delete(key)
+		if err := c.releasePodReadinessGates(namespace, nil); err != nil {
+			return util.ErrorResult(err)
+		}
 		return util.FinishedResult()
 	} else if err != nil {
 		return util.ErrorResult(err)
@@ -88,9 +108,19 @@ func (c *backendGroupController) syncBackendGroup(key string) *util.SyncResult {
 
 	if group.DeletionTimestamp != nil {
 		// BackendGroups will be deleted by K8S GC
+		c.portNotFound.Delete(key)
+		if err := c.releasePodReadinessGates(namespace, nil); err != nil {
+			return util.ErrorResult(err)
+		}
 		return util.FinishedResult()
 	}
 
+	if group.Spec.Pods != nil {
+		if err := c.releasePodReadinessGates(namespace, group); err != nil {
+			return util.ErrorResult(err)
+		}
+	}
+
 	// compare graph
 	lb, err := c.lbLister.LoadBalancers(namespace).Get(group.Spec.LBName)
 	if errors.IsNotFound(err) {
@@ -121,10 +151,69 @@ func (c *backendGroupController) syncBackendGroup(key string) *util.SyncResult {
 }
 
 func (c *backendGroupController) expectedPodBackends(group *lbcfapi.BackendGroup, lb *lbcfapi.LoadBalancer) ([]*lbcfapi.BackendRecord, error) {
+	pods, err := c.listPodsOfGroup(group)
+	if err != nil {
+		return nil, err
+	}
+
+	existingRecords, err := c.listBackendRecords(group.Namespace, lb.Name, group.Name)
+	if err != nil {
+		return nil, err
+	}
+	existing := sets.NewString()
+	for _, record := range existingRecords {
+		if record.DeletionTimestamp == nil {
+			existing.Insert(record.Name)
+		}
+	}
+
+	groupKey, err := controller.KeyFunc(group)
+	if err != nil {
+		return nil, err
+	}
+	lastSkipped := sets.NewString()
+	if v, ok := c.portNotFound.Load(groupKey); ok {
+		lastSkipped = v.(sets.String)
+	}
+	skipped := sets.NewString()
+
+	ports := util.BackendPorts(group)
+	var expectedRecords []*lbcfapi.BackendRecord
+	for _, pod := range pods {
+		for _, port := range ports {
+			record := util.ConstructPodBackendRecord(lb, group, pod, port)
+			if record == nil {
+				if util.PodAvailable(pod) {
+					skippedPort := fmt.Sprintf("%s/%s/%s", pod.UID, port.PortName, port.Protocol)
+					skipped.Insert(skippedPort)
+					if !lastSkipped.Has(skippedPort) {
+						c.eventRecorder.Eventf(group, v1.EventTypeWarning, "PortNotFound", "pod %s does not expose port %s/%s", pod.Name, port.PortName, port.Protocol)
+					}
+				}
+				continue
+			}
+			// pods that are already registered are kept until deregisterPolicy is met
+			if util.PodAvailable(pod) || (existing.Has(record.Name) && util.KeepPodBackend(pod, group.Spec.Pods.DeregisterPolicy)) {
+				expectedRecords = append(expectedRecords, record)
+			}
+		}
+	}
+	if skipped.Len() > 0 {
+		c.portNotFound.Store(groupKey, skipped)
+	} else {
+		c.portNotFound.Delete(groupKey)
+	}
+	return expectedRecords, nil
+}
+
+func (c *backendGroupController) listPodsOfGroup(group *lbcfapi.BackendGroup) ([]*v1.Pod, error) {
 	var pods []*v1.Pod
 	if group.Spec.Pods.ByLabel != nil {
-		var err error
-		pods, err = c.podLister.List(labels.SelectorFromSet(labels.Set(group.Spec.Pods.ByLabel.Selector)))
+		selector, err := util.PodSelector(group.Spec.Pods.ByLabel)
+		if err != nil {
+			return nil, err
+		}
+		pods, err = c.podLister.List(selector)
 		if err != nil {
 			return nil, err
 		}
@@ -150,20 +239,48 @@ func (c *backendGroupController) expectedPodBackends(group *lbcfapi.BackendGroup
 			pods = append(pods, pod)
 		}
 	}
+	return pods, nil
+}
 
-	var expectedRecords []*lbcfapi.BackendRecord
-	for _, pod := range util.FilterPods(pods, util.PodAvailable) {
-		record := util.ConstructPodBackendRecord(lb, group, pod)
-		expectedRecords = append(expectedRecords, record)
+// releasePodReadinessGates sets the readiness gate condition of pods to True if no BackendGroup with readinessGate
+// is going to register them, e.g. the LoadBalancer is not created or the pod does not expose the port.
+// Such pods never get a BackendRecord, so the gate can not be released when BackendRecords are synced.
+// If group is nil, all pods in namespace are checked
+func (c *backendGroupController) releasePodReadinessGates(namespace string, group *lbcfapi.BackendGroup) error {
+	var pods []*v1.Pod
+	var err error
+	if group != nil {
+		pods, err = c.listPodsOfGroup(group)
+	} else {
+		pods, err = c.podLister.Pods(namespace).List(labels.Everything())
 	}
-	return expectedRecords, nil
+	if err != nil {
+		return err
+	}
+	var errs util.ErrorList
+	for _, pod := range pods {
+		if pod.DeletionTimestamp != nil || !util.HasBackendsRegisteredGate(pod) || util.PodBackendsRegistered(pod) {
+			continue
+		}
+		gated, err := podGatedByBackendGroups(pod, c.bgLister, c.lbLister)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if gated {
+			continue
+		}
+		if err := setPodBackendsRegistered(c.k8sClient, pod, podNotGatedMsg); err != nil {
+			errs = append(errs, fmt.Errorf("update status of pod %s/%s failed: %v", pod.Namespace, pod.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
 }
 
 func (c *backendGroupController) expectedServiceBackends(group *lbcfapi.BackendGroup, lb *lbcfapi.LoadBalancer) ([]*lbcfapi.BackendRecord, error) {
-	nodes, err := c.nodeLister.List(labels.SelectorFromSet(labels.Set(group.Spec.Service.NodeSelector)))
-	if err != nil {
-		return nil, err
-	}
 	svc, err := c.serviceLister.Services(group.Namespace).Get(group.Spec.Service.Name)
 	if err != nil {
 		if errors.IsNotFound(err) {
@@ -171,19 +288,74 @@ func (c *backendGroupController) expectedServiceBackends(group *lbcfapi.BackendG
 		}
 		return nil, err
 	}
-	if svc.DeletionTimestamp != nil || svc.Spec.Type != v1.ServiceTypeNodePort {
+	if svc.DeletionTimestamp != nil {
+		return nil, nil
+	}
+	if group.Spec.Service.Mode == lbcfapi.ServiceModeEndpoints {
+		return c.expectedEndpointsBackends(group, lb, svc)
+	}
+	if svc.Spec.Type != v1.ServiceTypeNodePort {
 		return nil, nil
 	}
+	nodes, err := c.nodeLister.List(labels.SelectorFromSet(labels.Set(group.Spec.Service.NodeSelector)))
+	if err != nil {
+		return nil, err
+	}
+	var ep *v1.Endpoints
+	if util.IsLocalTrafficService(svc) {
+		ep, err = c.endpointsLister.Endpoints(svc.Namespace).Get(svc.Name)
+		if errors.IsNotFound(err) {
+			return nil, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
 	var expectedRecords []*lbcfapi.BackendRecord
+	for _, port := range util.BackendPorts(group) {
+		portNodes := nodes
+		if ep != nil {
+			// traffic sent to nodes without local endpoints is dropped, only register nodes hosting ready endpoints of the port
+			portNodes = filterNodesWithEndpoints(ep, svc, port.PortSelector, nodes)
+		}
+		for _, node := range portNodes {
+			backend := util.ConstructServiceBackendRecord(lb, group, svc, node, port)
+			if backend == nil {
+				klog.Infof("servicePort not found in svc %s/%s. looking for: %d/%s",
+					svc.Namespace, svc.Name,
+					port.PortNumber, port.Protocol)
+				break
+			}
+			expectedRecords = append(expectedRecords, backend)
+		}
+	}
+	return expectedRecords, nil
+}
+
+func filterNodesWithEndpoints(ep *v1.Endpoints, svc *v1.Service, port lbcfapi.PortSelector, nodes []*v1.Node) []*v1.Node {
+	nodeNames := util.EndpointsNodeNames(ep, svc, port)
+	var filtered []*v1.Node
 	for _, node := range nodes {
-		backend := util.ConstructServiceBackendRecord(lb, group, svc, node)
-		if backend == nil {
-			klog.Infof("servicePort not found in svc %s/%s. looking for: %d/%s",
-				svc.Namespace, svc.Name,
-				group.Spec.Service.Port.PortNumber, group.Spec.Service.Port.Protocol)
-			continue
+		if nodeNames.Has(node.Name) {
+			filtered = append(filtered, node)
 		}
-		expectedRecords = append(expectedRecords, backend)
+	}
+	return filtered
+}
+
+func (c *backendGroupController) expectedEndpointsBackends(group *lbcfapi.BackendGroup, lb *lbcfapi.LoadBalancer, svc *v1.Service) ([]*lbcfapi.BackendRecord, error) {
+	if svc.Spec.Type == v1.ServiceTypeExternalName {
+		return nil, nil
+	}
+	ep, err := c.endpointsLister.Endpoints(svc.Namespace).Get(svc.Name)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var expectedRecords []*lbcfapi.BackendRecord
+	for _, port := range util.BackendPorts(group) {
+		expectedRecords = append(expectedRecords, util.ConstructEndpointsBackendRecords(lb, group, svc, ep, port)...)
 	}
 	return expectedRecords, nil
 }
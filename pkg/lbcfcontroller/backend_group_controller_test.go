@@ -26,6 +26,7 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/kubernetes/pkg/controller"
 )
 
@@ -39,6 +40,7 @@ func TestBackendGroupCreateRecord(t *testing.T) {
 	fakeClient := fake.NewSimpleClientset(group)
 	ctrl := newBackendGroupController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeLBLister{
 			get:  lb,
 			list: []*lbcfapi.LoadBalancer{lb},
@@ -53,6 +55,8 @@ func TestBackendGroupCreateRecord(t *testing.T) {
 		},
 		&fakeSvcListerWithStore{},
 		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
 	)
 	key, _ := controller.KeyFunc(group)
 	result := ctrl.syncBackendGroup(key)
@@ -76,9 +80,9 @@ func TestBackendGroupCreateRecord(t *testing.T) {
 		var expected *lbcfapi.BackendRecord
 		switch r.Name {
 		case util.MakePodBackendName(lb.Name, group.Name, pod1.UID, lbcfapi.PortSelector{PortNumber: 80, Protocol: "TCP"}):
-			expected = util.ConstructPodBackendRecord(lb, group, pod1)
+			expected = util.ConstructPodBackendRecord(lb, group, pod1, util.BackendPorts(group)[0])
 		case util.MakePodBackendName(lb.Name, group.Name, pod2.UID, lbcfapi.PortSelector{PortNumber: 80, Protocol: "TCP"}):
-			expected = util.ConstructPodBackendRecord(lb, group, pod2)
+			expected = util.ConstructPodBackendRecord(lb, group, pod2, util.BackendPorts(group)[0])
 		default:
 			t.Fatalf("unknown BackendRecord %#v", r)
 		}
@@ -88,6 +92,123 @@ func TestBackendGroupCreateRecord(t *testing.T) {
 	}
 }
 
+func TestBackendGroupCreateRecordByPortName(t *testing.T) {
+	lb := newFakeLoadBalancer("", "lb", map[string]string{"a1": "v1"}, nil)
+	fakeLBEnsured(lb)
+	pod1 := newFakePod("", "pod-1", map[string]string{"k1": "v1"}, true, false)
+	pod1.Spec.Containers = []v1.Container{
+		{
+			Ports: []v1.ContainerPort{
+				{Name: "http", ContainerPort: 8080},
+			},
+		},
+	}
+	pod2 := newFakePod("", "pod-2", map[string]string{"k1": "v1"}, true, false)
+	pod2.UID = "anotherUID"
+	group := newFakeBackendGroupOfPods(pod1.Namespace, "group", lb.Name, 0, "TCP", pod1.Labels, nil, nil)
+	group.Spec.Pods.Port.PortName = "http"
+	fakeClient := fake.NewSimpleClientset(group)
+	recorder := &fakeEventRecorder{}
+	ctrl := newBackendGroupController(
+		fakeClient,
+		k8sfake.NewSimpleClientset(),
+		&fakeLBLister{
+			get:  lb,
+			list: []*lbcfapi.LoadBalancer{lb},
+		},
+		&fakeBackendGroupLister{
+			get: group,
+		},
+		&fakeBackendLister{},
+		&fakePodLister{
+			list: []*v1.Pod{pod1, pod2},
+		},
+		&fakeSvcListerWithStore{},
+		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		recorder,
+	)
+	key, _ := controller.KeyFunc(group)
+	result := ctrl.syncBackendGroup(key)
+	if !result.IsFinished() {
+		t.Fatalf("expect succ result, get %#v", result)
+	}
+
+	records, _ := fakeClient.LbcfV1beta1().BackendRecords(group.Namespace).List(metav1.ListOptions{})
+	if len(records.Items) != 1 {
+		t.Fatalf("expect 1 BackendReocrds, get %v, %#v", len(records.Items), records.Items)
+	}
+	if port := records.Items[0].Spec.PodBackendInfo.Port; records.Items[0].Spec.PodBackendInfo.Name != pod1.Name || port.PortNumber != 8080 || port.PortName != "" {
+		t.Fatalf("expect BackendRecord of pod-1 with port 8080, get %#v", records.Items[0].Spec.PodBackendInfo)
+	}
+	if recorder.store[group.Name] != "PortNotFound" {
+		t.Fatalf("expect event PortNotFound, get %q", recorder.store[group.Name])
+	}
+
+	// the event is not emitted again for the same pod
+	recorder.store = nil
+	ctrl.brLister = &fakeBackendLister{list: []*lbcfapi.BackendRecord{&records.Items[0]}}
+	if result := ctrl.syncBackendGroup(key); !result.IsFinished() {
+		t.Fatalf("expect succ result, get %#v", result)
+	}
+	if reason, ok := recorder.store[group.Name]; ok {
+		t.Fatalf("expect no event, get %q", reason)
+	}
+}
+
+func TestBackendGroupCreateRecordOfMultiplePorts(t *testing.T) {
+	lb := newFakeLoadBalancer("", "lb", map[string]string{"a1": "v1"}, nil)
+	fakeLBEnsured(lb)
+	pod1 := newFakePod("", "pod-1", map[string]string{"k1": "v1"}, true, false)
+	pod2 := newFakePod("", "pod-2", map[string]string{"k1": "v1"}, true, false)
+	pod2.UID = "anotherUID"
+	group := newFakeBackendGroupOfPods(pod1.Namespace, "group", lb.Name, 0, "", pod1.Labels, nil, nil)
+	group.Spec.Parameters = map[string]string{"scheme": "http"}
+	group.Spec.Pods.Ports = []lbcfapi.BackendPort{
+		{PortSelector: lbcfapi.PortSelector{PortNumber: 80, Protocol: "TCP"}},
+		{PortSelector: lbcfapi.PortSelector{PortNumber: 443, Protocol: "TCP"}, Parameters: map[string]string{"scheme": "https"}},
+	}
+	fakeClient := fake.NewSimpleClientset(group)
+	ctrl := newBackendGroupController(
+		fakeClient,
+		k8sfake.NewSimpleClientset(),
+		&fakeLBLister{
+			get:  lb,
+			list: []*lbcfapi.LoadBalancer{lb},
+		},
+		&fakeBackendGroupLister{
+			get: group,
+		},
+		&fakeBackendLister{},
+		&fakePodLister{
+			list: []*v1.Pod{pod1, pod2},
+		},
+		&fakeSvcListerWithStore{},
+		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
+	)
+	key, _ := controller.KeyFunc(group)
+	result := ctrl.syncBackendGroup(key)
+	if !result.IsFinished() {
+		t.Fatalf("expect succ result, get %#v", result)
+	}
+
+	records, _ := fakeClient.LbcfV1beta1().BackendRecords(group.Namespace).List(metav1.ListOptions{})
+	if len(records.Items) != 4 {
+		t.Fatalf("expect 4 BackendReocrds, get %v, %#v", len(records.Items), records.Items)
+	}
+	for _, r := range records.Items {
+		expectScheme := "http"
+		if r.Spec.PodBackendInfo.Port.PortNumber == 443 {
+			expectScheme = "https"
+		}
+		if r.Spec.Parameters["scheme"] != expectScheme {
+			t.Errorf("expect scheme %s for port %d, get %v", expectScheme, r.Spec.PodBackendInfo.Port.PortNumber, r.Spec.Parameters)
+		}
+	}
+}
+
 func TestBackendGroupCreateRecordByPodName(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", map[string]string{"a1": "v1"}, nil)
 	fakeLBEnsured(lb)
@@ -97,6 +218,7 @@ func TestBackendGroupCreateRecordByPodName(t *testing.T) {
 	fakeClient := fake.NewSimpleClientset(group)
 	ctrl := newBackendGroupController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeLBLister{
 			get:  lb,
 			list: []*lbcfapi.LoadBalancer{lb},
@@ -111,6 +233,8 @@ func TestBackendGroupCreateRecordByPodName(t *testing.T) {
 		},
 		&fakeSvcListerWithStore{},
 		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
 	)
 	key, _ := controller.KeyFunc(group)
 	result := ctrl.syncBackendGroup(key)
@@ -130,7 +254,7 @@ func TestBackendGroupCreateRecordByPodName(t *testing.T) {
 	if len(records.Items) != 1 {
 		t.Fatalf("expect 1 BackendReocrds, get %v, %#v", len(records.Items), records.Items)
 	}
-	expect := util.ConstructPodBackendRecord(lb, group, pod1)
+	expect := util.ConstructPodBackendRecord(lb, group, pod1, util.BackendPorts(group)[0])
 	if !reflect.DeepEqual(*expect, records.Items[0]) {
 		t.Errorf("expect BackendRecord %#v \n get %#v", *expect, records.Items[0])
 	}
@@ -144,6 +268,7 @@ func TestBackendGroupCreateRecordByService(t *testing.T) {
 	fakeClient := fake.NewSimpleClientset(group)
 	ctrl := newBackendGroupController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeLBLister{
 			get:  lb,
 			list: []*lbcfapi.LoadBalancer{lb},
@@ -164,6 +289,8 @@ func TestBackendGroupCreateRecordByService(t *testing.T) {
 				"node2": newFakeNode("", "node2"),
 			},
 		},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
 	)
 	key, _ := controller.KeyFunc(group)
 	result := ctrl.syncBackendGroup(key)
@@ -185,6 +312,95 @@ func TestBackendGroupCreateRecordByService(t *testing.T) {
 	}
 }
 
+func TestBackendGroupCreateRecordByServiceWithLocalTrafficPolicy(t *testing.T) {
+	lb := newFakeLoadBalancer("", "lb", map[string]string{"a1": "v1"}, nil)
+	fakeLBEnsured(lb)
+	svc := newFakeService("", "test-svc", v1.ServiceTypeNodePort)
+	svc.Spec.ExternalTrafficPolicy = v1.ServiceExternalTrafficPolicyTypeLocal
+	group := newFakeBackendGroupOfService("", "test-group", lb.Name, 0, "", svc.Name)
+	group.Spec.Service.Ports = []lbcfapi.BackendPort{
+		{PortSelector: lbcfapi.PortSelector{PortNumber: 80, Protocol: "TCP"}},
+		{PortSelector: lbcfapi.PortSelector{PortNumber: 443, Protocol: "TCP"}},
+	}
+	node1, node2, node3 := "node1", "node2", "node3"
+	ep := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: svc.Name,
+		},
+		Subsets: []v1.EndpointSubset{
+			{
+				Addresses: []v1.EndpointAddress{
+					{IP: "1.1.1.1", NodeName: &node1},
+				},
+				NotReadyAddresses: []v1.EndpointAddress{
+					{IP: "1.1.1.3", NodeName: &node3},
+				},
+				Ports: []v1.EndpointPort{
+					{Name: "http", Port: 80, Protocol: v1.ProtocolTCP},
+				},
+			},
+			{
+				// endpoints exposing only https
+				Addresses: []v1.EndpointAddress{
+					{IP: "1.1.1.2", NodeName: &node2},
+				},
+				Ports: []v1.EndpointPort{
+					{Name: "https", Port: 443, Protocol: v1.ProtocolTCP},
+				},
+			},
+		},
+	}
+	fakeClient := fake.NewSimpleClientset(group)
+	ctrl := newBackendGroupController(
+		fakeClient,
+		k8sfake.NewSimpleClientset(),
+		&fakeLBLister{
+			get:  lb,
+			list: []*lbcfapi.LoadBalancer{lb},
+		},
+		&fakeBackendGroupLister{
+			get: group,
+		},
+		&fakeBackendLister{},
+		&fakePodLister{},
+		&fakeSvcListerWithStore{
+			store: map[string]*v1.Service{
+				svc.Name: svc,
+			},
+		},
+		&fakeNodeListerWithStore{
+			store: map[string]*v1.Node{
+				"node1": newFakeNode("", "node1"),
+				"node2": newFakeNode("", "node2"),
+				"node3": newFakeNode("", "node3"),
+			},
+		},
+		&fakeEndpointsListerWithStore{
+			store: map[string]*v1.Endpoints{
+				ep.Name: ep,
+			},
+		},
+		&fakeEventRecorder{},
+	)
+	key, _ := controller.KeyFunc(group)
+	result := ctrl.syncBackendGroup(key)
+	if !result.IsFinished() {
+		t.Fatalf("expect succ result, get %#v", result)
+	}
+
+	records, _ := fakeClient.LbcfV1beta1().BackendRecords(group.Namespace).List(metav1.ListOptions{})
+	if len(records.Items) != 2 {
+		t.Fatalf("expect 2 BackendReocrds, get %v, %#v", len(records.Items), records.Items)
+	}
+	expect := map[int32]string{80: node1, 443: node2}
+	for _, r := range records.Items {
+		info := r.Spec.ServiceBackendInfo
+		if info.NodeName != expect[info.Port.PortNumber] {
+			t.Fatalf("expect %s for port %d, get %s", expect[info.Port.PortNumber], info.Port.PortNumber, info.NodeName)
+		}
+	}
+}
+
 func TestBackendGroupCreateRecordByServiceNotAvailable(t *testing.T) {
 	type testCase struct {
 		name string
@@ -232,6 +448,7 @@ func TestBackendGroupCreateRecordByServiceNotAvailable(t *testing.T) {
 		}
 		ctrl := newBackendGroupController(
 			fakeClient,
+			k8sfake.NewSimpleClientset(),
 			&fakeLBLister{
 				get:  lb,
 				list: []*lbcfapi.LoadBalancer{lb},
@@ -247,6 +464,8 @@ func TestBackendGroupCreateRecordByServiceNotAvailable(t *testing.T) {
 			&fakeNodeListerWithStore{
 				store: nodeStore,
 			},
+			&fakeEndpointsListerWithStore{},
+			&fakeEventRecorder{},
 		)
 		key, _ := controller.KeyFunc(c.group)
 		result := ctrl.syncBackendGroup(key)
@@ -266,6 +485,95 @@ func TestBackendGroupCreateRecordByServiceNotAvailable(t *testing.T) {
 	}
 }
 
+func TestBackendGroupCreateRecordByServiceEndpoints(t *testing.T) {
+	lb := newFakeLoadBalancer("", "lb", map[string]string{"a1": "v1"}, nil)
+	fakeLBEnsured(lb)
+	svc := newFakeService("", "test-svc", v1.ServiceTypeClusterIP)
+	group := newFakeBackendGroupOfService("", "test-group", lb.Name, 80, "TCP", svc.Name)
+	group.Spec.Service.Mode = lbcfapi.ServiceModeEndpoints
+	ep := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: svc.Name,
+		},
+		Subsets: []v1.EndpointSubset{
+			{
+				Addresses: []v1.EndpointAddress{
+					{
+						IP:        "1.1.1.1",
+						TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "pod-1", UID: "uid-1"},
+					},
+					{
+						IP:        "1.1.1.2",
+						TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "pod-2", UID: "uid-2"},
+					},
+				},
+				NotReadyAddresses: []v1.EndpointAddress{
+					{
+						IP:        "1.1.1.3",
+						TargetRef: &v1.ObjectReference{Kind: "Pod", Name: "pod-3", UID: "uid-3"},
+					},
+				},
+				Ports: []v1.EndpointPort{
+					{
+						Name:     "http",
+						Port:     8080,
+						Protocol: v1.ProtocolTCP,
+					},
+				},
+			},
+		},
+	}
+	fakeClient := fake.NewSimpleClientset(group)
+	ctrl := newBackendGroupController(
+		fakeClient,
+		k8sfake.NewSimpleClientset(),
+		&fakeLBLister{
+			get:  lb,
+			list: []*lbcfapi.LoadBalancer{lb},
+		},
+		&fakeBackendGroupLister{
+			get: group,
+		},
+		&fakeBackendLister{},
+		&fakePodLister{},
+		&fakeSvcListerWithStore{
+			store: map[string]*v1.Service{
+				svc.Name: svc,
+			},
+		},
+		&fakeNodeListerWithStore{
+			store: map[string]*v1.Node{
+				"node1": newFakeNode("", "node1"),
+			},
+		},
+		&fakeEndpointsListerWithStore{
+			store: map[string]*v1.Endpoints{
+				ep.Name: ep,
+			},
+		},
+		&fakeEventRecorder{},
+	)
+	key, _ := controller.KeyFunc(group)
+	result := ctrl.syncBackendGroup(key)
+	if !result.IsFinished() {
+		t.Fatalf("expect succ result, get %#v", result)
+	}
+
+	records, _ := fakeClient.LbcfV1beta1().BackendRecords(group.Namespace).List(metav1.ListOptions{})
+	if len(records.Items) != 2 {
+		t.Fatalf("expect 2 BackendReocrds, get %v, %#v", len(records.Items), records.Items)
+	}
+	for _, r := range records.Items {
+		if r.Spec.PodBackendInfo == nil {
+			t.Fatalf("expect pod backend, get %#v", r.Spec)
+		} else if r.Spec.PodBackendInfo.Name != "pod-1" && r.Spec.PodBackendInfo.Name != "pod-2" {
+			t.Fatalf("unexpected pod %s", r.Spec.PodBackendInfo.Name)
+		} else if r.Spec.PodBackendInfo.Port.PortNumber != 8080 {
+			t.Fatalf("expect port 8080, get %d", r.Spec.PodBackendInfo.Port.PortNumber)
+		}
+	}
+}
+
 func TestBackendGroupCreateRecordByStatic(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", map[string]string{"a1": "v1"}, nil)
 	fakeLBEnsured(lb)
@@ -277,6 +585,7 @@ func TestBackendGroupCreateRecordByStatic(t *testing.T) {
 	fakeClient := fake.NewSimpleClientset(group)
 	ctrl := newBackendGroupController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeLBLister{
 			get:  lb,
 			list: []*lbcfapi.LoadBalancer{lb},
@@ -288,6 +597,8 @@ func TestBackendGroupCreateRecordByStatic(t *testing.T) {
 		&fakePodLister{},
 		&fakeSvcListerWithStore{},
 		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
 	)
 	key, _ := controller.KeyFunc(group)
 	result := ctrl.syncBackendGroup(key)
@@ -318,11 +629,12 @@ func TestBackendGroupUpdateRecordCausedByGroupUpdate(t *testing.T) {
 	curGroup.Spec.Parameters = map[string]string{
 		"p1": "v1",
 	}
-	oldBackend1 := util.ConstructPodBackendRecord(lb, oldGroup, pod1)
-	oldBackend2 := util.ConstructPodBackendRecord(lb, oldGroup, pod2)
+	oldBackend1 := util.ConstructPodBackendRecord(lb, oldGroup, pod1, util.BackendPorts(oldGroup)[0])
+	oldBackend2 := util.ConstructPodBackendRecord(lb, oldGroup, pod2, util.BackendPorts(oldGroup)[0])
 	fakeClient := fake.NewSimpleClientset(curGroup, oldBackend1, oldBackend2)
 	ctrl := newBackendGroupController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeLBLister{
 			get:  lb,
 			list: []*lbcfapi.LoadBalancer{lb},
@@ -342,6 +654,8 @@ func TestBackendGroupUpdateRecordCausedByGroupUpdate(t *testing.T) {
 		},
 		&fakeSvcListerWithStore{},
 		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
 	)
 	key, _ := controller.KeyFunc(curGroup)
 	result := ctrl.syncBackendGroup(key)
@@ -365,9 +679,9 @@ func TestBackendGroupUpdateRecordCausedByGroupUpdate(t *testing.T) {
 		var expected *lbcfapi.BackendRecord
 		switch r.Name {
 		case util.MakePodBackendName(lb.Name, curGroup.Name, pod1.UID, lbcfapi.PortSelector{PortNumber: 80, Protocol: "TCP"}):
-			expected = util.ConstructPodBackendRecord(lb, curGroup, pod1)
+			expected = util.ConstructPodBackendRecord(lb, curGroup, pod1, util.BackendPorts(curGroup)[0])
 		case util.MakePodBackendName(lb.Name, curGroup.Name, pod2.UID, lbcfapi.PortSelector{PortNumber: 80, Protocol: "TCP"}):
-			expected = util.ConstructPodBackendRecord(lb, curGroup, pod2)
+			expected = util.ConstructPodBackendRecord(lb, curGroup, pod2, util.BackendPorts(curGroup)[0])
 		default:
 			t.Fatalf("unknown BackendRecord %#v", r)
 		}
@@ -388,12 +702,13 @@ func TestBackendGroupUpdateRecordCausedByLBUpdate(t *testing.T) {
 	pod2 := newFakePod("", "pod-2", map[string]string{"k1": "v1"}, true, false)
 	pod2.UID = "anotherUID"
 	group := newFakeBackendGroupOfPods(pod1.Namespace, "group", curLB.Name, 80, "TCP", pod1.Labels, nil, nil)
-	oldBackend1 := util.ConstructPodBackendRecord(oldLB, group, pod1)
-	oldBackend2 := util.ConstructPodBackendRecord(oldLB, group, pod2)
+	oldBackend1 := util.ConstructPodBackendRecord(oldLB, group, pod1, util.BackendPorts(group)[0])
+	oldBackend2 := util.ConstructPodBackendRecord(oldLB, group, pod2, util.BackendPorts(group)[0])
 	fakeClient := fake.NewSimpleClientset(group, oldBackend1, oldBackend2)
 
 	ctrl := newBackendGroupController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeLBLister{
 			get:  curLB,
 			list: []*lbcfapi.LoadBalancer{curLB},
@@ -413,6 +728,8 @@ func TestBackendGroupUpdateRecordCausedByLBUpdate(t *testing.T) {
 		},
 		&fakeSvcListerWithStore{},
 		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
 	)
 	key, _ := controller.KeyFunc(group)
 	result := ctrl.syncBackendGroup(key)
@@ -436,9 +753,9 @@ func TestBackendGroupUpdateRecordCausedByLBUpdate(t *testing.T) {
 		var expected *lbcfapi.BackendRecord
 		switch r.Name {
 		case util.MakePodBackendName(curLB.Name, group.Name, pod1.UID, lbcfapi.PortSelector{PortNumber: 80, Protocol: "TCP"}):
-			expected = util.ConstructPodBackendRecord(curLB, group, pod1)
+			expected = util.ConstructPodBackendRecord(curLB, group, pod1, util.BackendPorts(group)[0])
 		case util.MakePodBackendName(curLB.Name, group.Name, pod2.UID, lbcfapi.PortSelector{PortNumber: 80, Protocol: "TCP"}):
-			expected = util.ConstructPodBackendRecord(curLB, group, pod2)
+			expected = util.ConstructPodBackendRecord(curLB, group, pod2, util.BackendPorts(group)[0])
 		default:
 			t.Fatalf("unknown BackendRecord %#v", r)
 		}
@@ -457,12 +774,13 @@ func TestBackendGroupDeleteRecordCausedByPodStatusChange(t *testing.T) {
 	pod2.UID = "anotherUID"
 
 	group := newFakeBackendGroupOfPods(curPod.Namespace, "group", lb.Name, 80, "TCP", curPod.Labels, nil, nil)
-	existingBackend1 := util.ConstructPodBackendRecord(lb, group, oldPod)
-	existingBackend2 := util.ConstructPodBackendRecord(lb, group, pod2)
+	existingBackend1 := util.ConstructPodBackendRecord(lb, group, oldPod, util.BackendPorts(group)[0])
+	existingBackend2 := util.ConstructPodBackendRecord(lb, group, pod2, util.BackendPorts(group)[0])
 
 	fakeClient := fake.NewSimpleClientset(group, existingBackend1, existingBackend2)
 	ctrl := newBackendGroupController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeLBLister{
 			get:  lb,
 			list: []*lbcfapi.LoadBalancer{lb},
@@ -479,6 +797,8 @@ func TestBackendGroupDeleteRecordCausedByPodStatusChange(t *testing.T) {
 		},
 		&fakeSvcListerWithStore{},
 		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
 	)
 	key, _ := controller.KeyFunc(group)
 	result := ctrl.syncBackendGroup(key)
@@ -504,6 +824,72 @@ func TestBackendGroupDeleteRecordCausedByPodStatusChange(t *testing.T) {
 	}
 }
 
+func TestBackendGroupKeepRecordByDeregisterPolicy(t *testing.T) {
+	lb := newFakeLoadBalancer("", "lb", map[string]string{"a1": "v1"}, nil)
+	fakeLBEnsured(lb)
+	readyPod := newFakePod("", "pod-1", map[string]string{"k1": "v1"}, true, false)
+	notReadyPod := newFakePod("", "pod-1", map[string]string{"k1": "v1"}, false, false)
+	notReadyPod.Status.PodIP = "1.1.1.1"
+	notReadyPod.Status.Phase = v1.PodRunning
+	newPod := newFakePod("", "pod-2", map[string]string{"k1": "v1"}, false, false)
+	newPod.UID = "anotherUID"
+	newPod.Status.PodIP = "1.1.1.2"
+	newPod.Status.Phase = v1.PodRunning
+
+	cases := []struct {
+		name   string
+		policy lbcfapi.DeregisterPolicy
+		expect int
+	}{
+		{"default", "", 0},
+		{"if-not-ready", lbcfapi.DeregisterIfNotReady, 0},
+		{"if-not-running", lbcfapi.DeregisterIfNotRunning, 1},
+		{"on-delete", lbcfapi.DeregisterOnDelete, 1},
+	}
+	for _, c := range cases {
+		group := newFakeBackendGroupOfPods(readyPod.Namespace, "group", lb.Name, 80, "TCP", readyPod.Labels, nil, nil)
+		group.Spec.Pods.DeregisterPolicy = c.policy
+		existingBackend := util.ConstructPodBackendRecord(lb, group, readyPod, util.BackendPorts(group)[0])
+
+		fakeClient := fake.NewSimpleClientset(group, existingBackend)
+		ctrl := newBackendGroupController(
+			fakeClient,
+			k8sfake.NewSimpleClientset(),
+			&fakeLBLister{
+				get:  lb,
+				list: []*lbcfapi.LoadBalancer{lb},
+			},
+			&fakeBackendGroupLister{
+				get: group,
+			},
+			&fakeBackendLister{
+				list: []*lbcfapi.BackendRecord{existingBackend},
+			},
+			&fakePodLister{
+				list: []*v1.Pod{notReadyPod, newPod},
+			},
+			&fakeSvcListerWithStore{},
+			&fakeNodeListerWithStore{},
+			&fakeEndpointsListerWithStore{},
+			&fakeEventRecorder{},
+		)
+		key, _ := controller.KeyFunc(group)
+		result := ctrl.syncBackendGroup(key)
+		if !result.IsFinished() {
+			t.Fatalf("case %s, expect succ result, get %#v", c.name, result)
+		}
+
+		// pods that are not registered yet are never registered before they are ready
+		records, _ := fakeClient.LbcfV1beta1().BackendRecords(group.Namespace).List(metav1.ListOptions{})
+		if len(records.Items) != c.expect {
+			t.Fatalf("case %s, expect %d BackendRecords, get %d", c.name, c.expect, len(records.Items))
+		}
+		if c.expect > 0 && records.Items[0].Name != existingBackend.Name {
+			t.Fatalf("case %s, wrong BackendRecord, get %v", c.name, records.Items[0])
+		}
+	}
+}
+
 func TestBackendGroupDeleteRecordCausedByLBDeleted(t *testing.T) {
 	ts := metav1.Now()
 	lb := newFakeLoadBalancer("", "lb", map[string]string{"a1": "v1"}, nil)
@@ -512,11 +898,12 @@ func TestBackendGroupDeleteRecordCausedByLBDeleted(t *testing.T) {
 	pod2 := newFakePod("", "pod-2", map[string]string{"k1": "v1"}, true, false)
 	pod2.UID = "anotherUID"
 	group := newFakeBackendGroupOfPods(pod1.Namespace, "group", lb.Name, 80, "tcp", pod1.Labels, nil, nil)
-	existingBackend1 := util.ConstructPodBackendRecord(lb, group, pod1)
-	existingBackend2 := util.ConstructPodBackendRecord(lb, group, pod2)
+	existingBackend1 := util.ConstructPodBackendRecord(lb, group, pod1, util.BackendPorts(group)[0])
+	existingBackend2 := util.ConstructPodBackendRecord(lb, group, pod2, util.BackendPorts(group)[0])
 	fakeClient := fake.NewSimpleClientset(group, existingBackend1, existingBackend2)
 	ctrl := newBackendGroupController(
 		fakeClient,
+		k8sfake.NewSimpleClientset(),
 		&fakeLBLister{
 			get:  lb,
 			list: []*lbcfapi.LoadBalancer{lb},
@@ -536,6 +923,8 @@ func TestBackendGroupDeleteRecordCausedByLBDeleted(t *testing.T) {
 		},
 		&fakeSvcListerWithStore{},
 		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
 	)
 	key, _ := controller.KeyFunc(group)
 	result := ctrl.syncBackendGroup(key)
@@ -557,6 +946,90 @@ func TestBackendGroupDeleteRecordCausedByLBDeleted(t *testing.T) {
 	}
 }
 
+func TestBackendGroupReleaseReadinessGate(t *testing.T) {
+	pod := newFakePod("", "pod-0", map[string]string{"k1": "v1"}, true, false)
+	pod.Spec.ReadinessGates = []v1.PodReadinessGate{
+		{ConditionType: lbcfapi.PodConditionBackendsRegistered},
+	}
+	createdLB := newFakeLoadBalancer("", "lb", nil, nil)
+	fakeLBEnsured(createdLB)
+	notCreatedLB := newFakeLoadBalancer("", "lb", nil, nil)
+	group := newFakeBackendGroupOfPods(pod.Namespace, "group", createdLB.Name, 80, "TCP", pod.Labels, nil, nil)
+	group.Spec.Pods.ReadinessGate = true
+	byPortName := group.DeepCopy()
+	byPortName.Spec.Pods.Port.PortNumber = 0
+	byPortName.Spec.Pods.Port.PortName = "http"
+
+	cases := []struct {
+		name   string
+		lb     *lbcfapi.LoadBalancer
+		group  *lbcfapi.BackendGroup
+		expect v1.ConditionStatus
+	}{
+		{
+			name:   "lb-not-created",
+			lb:     notCreatedLB,
+			group:  group,
+			expect: v1.ConditionTrue,
+		},
+		{
+			name:   "port-not-found",
+			lb:     createdLB,
+			group:  byPortName,
+			expect: v1.ConditionTrue,
+		},
+		{
+			name:   "group-deleted",
+			lb:     createdLB,
+			expect: v1.ConditionTrue,
+		},
+		{
+			name:  "still-gated",
+			lb:    createdLB,
+			group: group,
+		},
+	}
+	for _, c := range cases {
+		k8sClient := k8sfake.NewSimpleClientset(pod)
+		bgLister := &fakeBackendGroupLister{}
+		if c.group != nil {
+			bgLister.get = c.group
+			bgLister.list = []*lbcfapi.BackendGroup{c.group}
+		}
+		ctrl := newBackendGroupController(
+			fake.NewSimpleClientset(group),
+			k8sClient,
+			&fakeLBLister{
+				get: c.lb,
+			},
+			bgLister,
+			&fakeBackendLister{},
+			&fakePodLister{
+				get:  pod,
+				list: []*v1.Pod{pod},
+			},
+			&fakeSvcListerWithStore{},
+			&fakeNodeListerWithStore{},
+			&fakeEndpointsListerWithStore{},
+			&fakeEventRecorder{},
+		)
+		key, _ := controller.KeyFunc(group)
+		if result := ctrl.syncBackendGroup(key); !result.IsFinished() {
+			t.Fatalf("case %s: expect succ result, get %#v", c.name, result)
+		}
+		get, _ := k8sClient.CoreV1().Pods(pod.Namespace).Get(pod.Name, metav1.GetOptions{})
+		var status v1.ConditionStatus
+		for _, cond := range get.Status.Conditions {
+			if cond.Type == v1.PodConditionType(lbcfapi.PodConditionBackendsRegistered) {
+				status = cond.Status
+			}
+		}
+		if status != c.expect {
+			t.Fatalf("case %s: expect readiness gate %q, get %q", c.name, c.expect, status)
+		}
+	}
+}
+
 func fakeLBEnsured(lb *lbcfapi.LoadBalancer) {
 	ts := metav1.Now()
 	util.AddLBCondition(&lb.Status, lbcfapi.LoadBalancerCondition{
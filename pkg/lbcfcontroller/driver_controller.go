@@ -18,6 +18,9 @@
 package lbcfcontroller
 
 import (
+	"fmt"
+	"sync"
+
 	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
 	lbcfclient "tkestack.io/lb-controlling-framework/pkg/client-go/clientset/versioned"
 	"tkestack.io/lb-controlling-framework/pkg/client-go/listers/lbcf.tkestack.io/v1beta1"
@@ -26,18 +29,26 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog"
 )
 
-func newDriverController(client lbcfclient.Interface, lister v1beta1.LoadBalancerDriverLister) *driverController {
+func newDriverController(client lbcfclient.Interface, lister v1beta1.LoadBalancerDriverLister, invoker util.WebhookInvoker) *driverController {
 	return &driverController{
-		lbcfClient: client,
-		lister:     lister,
+		lbcfClient:     client,
+		lister:         lister,
+		webhookInvoker: invoker,
+		probeFailures:  make(map[string]int32),
 	}
 }
 
 type driverController struct {
-	lbcfClient lbcfclient.Interface
-	lister     v1beta1.LoadBalancerDriverLister
+	lbcfClient     lbcfclient.Interface
+	lister         v1beta1.LoadBalancerDriverLister
+	webhookInvoker util.WebhookInvoker
+
+	// probeFailures is the number of consecutive health check failures of each driver
+	lock          sync.Mutex
+	probeFailures map[string]int32
 }
 
 func (c *driverController) syncDriver(key string) *util.SyncResult {
@@ -47,17 +58,21 @@ func (c *driverController) syncDriver(key string) *util.SyncResult {
 	}
 	driver, err := c.lister.LoadBalancerDrivers(namespace).Get(name)
 	if errors.IsNotFound(err) {
+		c.forgetProbeFailures(key)
+		c.webhookInvoker.ForgetDriver(key)
 		return util.FinishedResult()
 	} else if err != nil {
 		return util.ErrorResult(err)
 	}
 
 	if driver.DeletionTimestamp != nil {
+		c.forgetProbeFailures(key)
 		return util.FinishedResult()
 	}
 
 	// create DriverConnector
 	if len(driver.Status.Conditions) == 0 {
+		driver = driver.DeepCopy()
 		driver.Status = lbcfapi.LoadBalancerDriverStatus{
 			Conditions: []lbcfapi.LoadBalancerDriverCondition{
 				{
@@ -67,11 +82,117 @@ func (c *driverController) syncDriver(key string) *util.SyncResult {
 				},
 			},
 		}
-		_, err := c.lbcfClient.LbcfV1beta1().LoadBalancerDrivers(namespace).UpdateStatus(driver)
+		driver, err = c.lbcfClient.LbcfV1beta1().LoadBalancerDrivers(namespace).UpdateStatus(driver)
 		if err != nil {
 			return util.ErrorResult(err)
 		}
+	}
+	driver, err = c.syncCircuitBreakerCondition(driver)
+	if err != nil {
+		return util.ErrorResult(err)
+	}
+	if driver.Spec.HealthCheck == nil {
+		c.forgetProbeFailures(key)
 		return util.FinishedResult()
 	}
-	return util.FinishedResult()
+	return c.probeDriver(key, driver)
+}
+
+// syncCircuitBreakerCondition updates the CircuitBreakerOpen condition if the state of the circuit breaker in invoker changed,
+// the condition is not added until the circuit breaker opens for the first time
+func (c *driverController) syncCircuitBreakerCondition(driver *lbcfapi.LoadBalancerDriver) (*lbcfapi.LoadBalancerDriver, error) {
+	status := c.webhookInvoker.GetCircuitBreakerStatus(driver)
+	expect := lbcfapi.LoadBalancerDriverCondition{
+		Type:               lbcfapi.DriverCircuitBreakerOpen,
+		Status:             lbcfapi.ConditionFalse,
+		LastTransitionTime: v1.Now(),
+	}
+	switch status.State {
+	case util.CircuitOpen:
+		expect.Status = lbcfapi.ConditionTrue
+		expect.Reason = lbcfapi.ReasonCircuitOpen.String()
+	case util.CircuitHalfOpen:
+		expect.Status = lbcfapi.ConditionTrue
+		expect.Reason = lbcfapi.ReasonCircuitHalfOpen.String()
+	}
+	if expect.Status == lbcfapi.ConditionTrue {
+		expect.Message = fmt.Sprintf("%d consecutive failures, last error: %s", status.ConsecutiveFailures, status.LastError)
+	}
+
+	cur := util.GetDriverCondition(&driver.Status, lbcfapi.DriverCircuitBreakerOpen)
+	if cur == nil && expect.Status == lbcfapi.ConditionFalse {
+		return driver, nil
+	}
+	if cur != nil && cur.Status == expect.Status && cur.Reason == expect.Reason {
+		return driver, nil
+	}
+	driver = driver.DeepCopy()
+	util.AddDriverCondition(&driver.Status, expect)
+	return c.lbcfClient.LbcfV1beta1().LoadBalancerDrivers(driver.Namespace).UpdateStatus(driver)
+}
+
+// probeDriver calls the health check of driver and updates the Ready condition, it is called periodically
+func (c *driverController) probeDriver(key string, driver *lbcfapi.LoadBalancerDriver) *util.SyncResult {
+	cfg := driver.Spec.HealthCheck
+	period := util.GetDuration(cfg.Period, util.DefaultHealthCheckPeriod)
+	threshold := cfg.FailureThreshold
+	if threshold <= 0 {
+		threshold = util.DefaultHealthCheckFailureThreshold
+	}
+
+	probeErr := c.webhookInvoker.CallHealthCheck(driver)
+	failures := c.recordProbeResult(key, probeErr)
+
+	cur := util.GetDriverCondition(&driver.Status, lbcfapi.DriverReady)
+	now := v1.Now()
+	expect := lbcfapi.LoadBalancerDriverCondition{
+		Type:          lbcfapi.DriverReady,
+		Status:        lbcfapi.ConditionTrue,
+		LastProbeTime: now,
+	}
+	if probeErr != nil {
+		klog.Infof("health check of driver %s failed %d times: %v", key, failures, probeErr)
+		expect.Status = lbcfapi.ConditionFalse
+		expect.Reason = lbcfapi.ReasonHealthCheckFailed.String()
+		expect.Message = probeErr.Error()
+		if failures < threshold {
+			// not enough consecutive failures, the condition is kept unchanged
+			if cur != nil {
+				return util.PeriodicResult(period)
+			}
+			expect.Status = lbcfapi.ConditionUnknown
+		}
+	}
+	// the status is written only if the condition changes, rather than every period for LastProbeTime
+	if cur != nil && cur.Status == expect.Status && cur.Reason == expect.Reason && cur.Message == expect.Message {
+		return util.PeriodicResult(period)
+	}
+	expect.LastTransitionTime = now
+	if cur != nil && cur.Status == expect.Status {
+		expect.LastTransitionTime = cur.LastTransitionTime
+	}
+
+	driver = driver.DeepCopy()
+	util.AddDriverCondition(&driver.Status, expect)
+	if _, err := c.lbcfClient.LbcfV1beta1().LoadBalancerDrivers(driver.Namespace).UpdateStatus(driver); err != nil {
+		return util.ErrorResult(err)
+	}
+	return util.PeriodicResult(period)
+}
+
+func (c *driverController) recordProbeResult(key string, probeErr error) int32 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if probeErr == nil {
+		delete(c.probeFailures, key)
+		return 0
+	}
+	c.probeFailures[key]++
+	return c.probeFailures[key]
+}
+
+func (c *driverController) forgetProbeFailures(key string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.probeFailures, key)
 }
@@ -24,6 +24,7 @@ import (
 	"tkestack.io/lb-controlling-framework/pkg/client-go/clientset/versioned/fake"
 
 	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/util"
 )
 
 func TestDriverControllerSyncDriverCreate(t *testing.T) {
@@ -32,7 +33,7 @@ func TestDriverControllerSyncDriverCreate(t *testing.T) {
 	key, _ := controller.KeyFunc(driver)
 	ctrl := newDriverController(fake.NewSimpleClientset(driver), &fakeDriverLister{
 		get: driver,
-	})
+	}, &fakeSuccInvoker{})
 	result := ctrl.syncDriver(key)
 	if !result.IsFinished() {
 		t.Logf("%v", result.GetFailReason())
@@ -53,7 +54,7 @@ func TestDriverControllerSyncDriverAccepted(t *testing.T) {
 	key, _ := controller.KeyFunc(driver)
 	ctrl := newDriverController(fake.NewSimpleClientset(), &fakeDriverLister{
 		get: driver,
-	})
+	}, &fakeSuccInvoker{})
 	result := ctrl.syncDriver(key)
 	if !result.IsFinished() {
 		t.Logf("%v", result.GetFailReason())
@@ -65,7 +66,7 @@ func TestDriverControllerSyncDriverNotFound(t *testing.T) {
 	driver := newFakeDriver("kube-system", fmt.Sprintf("%s%s", lbcfapi.SystemDriverPrefix, "driver"))
 
 	key, _ := controller.KeyFunc(driver)
-	ctrl := newDriverController(fake.NewSimpleClientset(), &fakeDriverLister{})
+	ctrl := newDriverController(fake.NewSimpleClientset(), &fakeDriverLister{}, &fakeSuccInvoker{})
 	result := ctrl.syncDriver(key)
 	if !result.IsFinished() {
 		t.Fatalf("expect succ result, get %v", result)
@@ -80,9 +81,134 @@ func TestDriverControllerSyncDriverDeleting(t *testing.T) {
 	key, _ := controller.KeyFunc(driver)
 	ctrl := newDriverController(fake.NewSimpleClientset(), &fakeDriverLister{
 		get: driver,
-	})
+	}, &fakeSuccInvoker{})
 	result := ctrl.syncDriver(key)
 	if !result.IsFinished() {
 		t.Fatalf("expect succ result, get %v", result)
 	}
 }
+
+func TestDriverControllerSyncDriverHealthCheck(t *testing.T) {
+	driver := newFakeDriver("kube-system", fmt.Sprintf("%s%s", lbcfapi.SystemDriverPrefix, "driver"))
+	driver.Spec.HealthCheck = &lbcfapi.DriverHealthCheckConfig{
+		FailureThreshold: 2,
+	}
+	key, _ := controller.KeyFunc(driver)
+
+	client := fake.NewSimpleClientset(driver)
+	ctrl := newDriverController(client, &fakeDriverLister{
+		get: driver,
+	}, &fakeSuccInvoker{})
+	result := ctrl.syncDriver(key)
+	if !result.IsPeriodic() {
+		t.Fatalf("expect periodic result, get %#v", result)
+	}
+	get, _ := client.LbcfV1beta1().LoadBalancerDrivers(driver.Namespace).Get(driver.Name, metav1.GetOptions{})
+	if cond := util.GetDriverCondition(&get.Status, lbcfapi.DriverReady); cond == nil || cond.Status != lbcfapi.ConditionTrue {
+		t.Fatalf("expect driver ready, get %#v", get.Status)
+	}
+
+	// the status is not written if the condition is unchanged
+	updates := countStatusUpdates(client)
+	ctrl.lister = &fakeDriverLister{get: get}
+	if result := ctrl.syncDriver(key); !result.IsPeriodic() {
+		t.Fatalf("expect periodic result, get %#v", result)
+	}
+	if n := countStatusUpdates(client); n != updates {
+		t.Fatalf("expect no status update, get %d updates", n-updates)
+	}
+
+	ctrl = newDriverController(client, &fakeDriverLister{
+		get: get,
+	}, &fakeFailInvoker{})
+	result = ctrl.syncDriver(key)
+	if !result.IsPeriodic() {
+		t.Fatalf("expect periodic result, get %#v", result)
+	}
+	get, _ = client.LbcfV1beta1().LoadBalancerDrivers(driver.Namespace).Get(driver.Name, metav1.GetOptions{})
+	if !util.IsDriverReady(get) {
+		t.Fatalf("expect driver ready before reaching failure threshold, get %#v", get.Status)
+	}
+	if cond := util.GetDriverCondition(&get.Status, lbcfapi.DriverReady); cond.Reason != "" || cond.Message != "" {
+		t.Fatalf("expect no failure reason before reaching failure threshold, get %#v", cond)
+	}
+	if n := countStatusUpdates(client); n != updates {
+		t.Fatalf("expect no status update before reaching failure threshold, get %d updates", n-updates)
+	}
+
+	ctrl.lister = &fakeDriverLister{get: get}
+	result = ctrl.syncDriver(key)
+	if !result.IsPeriodic() {
+		t.Fatalf("expect periodic result, get %#v", result)
+	}
+	get, _ = client.LbcfV1beta1().LoadBalancerDrivers(driver.Namespace).Get(driver.Name, metav1.GetOptions{})
+	cond := util.GetDriverCondition(&get.Status, lbcfapi.DriverReady)
+	if cond == nil || cond.Status != lbcfapi.ConditionFalse || cond.Reason != lbcfapi.ReasonHealthCheckFailed.String() {
+		t.Fatalf("expect driver not ready, get %#v", get.Status)
+	}
+	if util.IsDriverReady(get) {
+		t.Fatalf("expect driver not ready")
+	}
+}
+
+func countStatusUpdates(client *fake.Clientset) int {
+	count := 0
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "update" && action.GetSubresource() == "status" {
+			count++
+		}
+	}
+	return count
+}
+
+type fakeCircuitOpenInvoker struct {
+	fakeSuccInvoker
+}
+
+func (c *fakeCircuitOpenInvoker) GetCircuitBreakerStatus(driver *lbcfapi.LoadBalancerDriver) util.CircuitBreakerStatus {
+	return util.CircuitBreakerStatus{
+		State:               util.CircuitOpen,
+		ConsecutiveFailures: 5,
+		LastError:           "fake error",
+	}
+}
+
+func TestDriverControllerSyncDriverCircuitBreaker(t *testing.T) {
+	driver := newFakeDriver("kube-system", fmt.Sprintf("%s%s", lbcfapi.SystemDriverPrefix, "driver"))
+	key, _ := controller.KeyFunc(driver)
+
+	client := fake.NewSimpleClientset(driver)
+	ctrl := newDriverController(client, &fakeDriverLister{
+		get: driver,
+	}, &fakeSuccInvoker{})
+	if result := ctrl.syncDriver(key); !result.IsFinished() {
+		t.Fatalf("expect succ result, get %#v", result)
+	}
+	get, _ := client.LbcfV1beta1().LoadBalancerDrivers(driver.Namespace).Get(driver.Name, metav1.GetOptions{})
+	if cond := util.GetDriverCondition(&get.Status, lbcfapi.DriverCircuitBreakerOpen); cond != nil {
+		t.Fatalf("expect no CircuitBreakerOpen condition, get %#v", cond)
+	}
+
+	ctrl = newDriverController(client, &fakeDriverLister{
+		get: get,
+	}, &fakeCircuitOpenInvoker{})
+	if result := ctrl.syncDriver(key); !result.IsFinished() {
+		t.Fatalf("expect succ result, get %#v", result)
+	}
+	get, _ = client.LbcfV1beta1().LoadBalancerDrivers(driver.Namespace).Get(driver.Name, metav1.GetOptions{})
+	cond := util.GetDriverCondition(&get.Status, lbcfapi.DriverCircuitBreakerOpen)
+	if cond == nil || cond.Status != lbcfapi.ConditionTrue || cond.Reason != lbcfapi.ReasonCircuitOpen.String() {
+		t.Fatalf("expect circuit breaker open, get %#v", get.Status)
+	}
+
+	ctrl = newDriverController(client, &fakeDriverLister{
+		get: get,
+	}, &fakeSuccInvoker{})
+	if result := ctrl.syncDriver(key); !result.IsFinished() {
+		t.Fatalf("expect succ result, get %#v", result)
+	}
+	get, _ = client.LbcfV1beta1().LoadBalancerDrivers(driver.Namespace).Get(driver.Name, metav1.GetOptions{})
+	if cond := util.GetDriverCondition(&get.Status, lbcfapi.DriverCircuitBreakerOpen); cond == nil || cond.Status != lbcfapi.ConditionFalse {
+		t.Fatalf("expect circuit breaker closed, get %#v", get.Status)
+	}
+}
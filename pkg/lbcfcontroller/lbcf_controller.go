@@ -19,50 +19,83 @@ package lbcfcontroller
 
 import (
 	"reflect"
+	"sync"
 	"time"
 
 	"tkestack.io/lb-controlling-framework/cmd/lbcf-controller/app/context"
 	"tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/metrics"
 	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/util"
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog"
 	"k8s.io/kubernetes/pkg/controller"
 )
 
+// maxExecStderrInEvent limits the size of stderr of Exec drivers recorded in events
+const maxExecStderrInEvent = 1024
+
 // NewController creates a new LBCF-controller
 func NewController(ctx *context.Context) *Controller {
 	c := &Controller{
 		context:           ctx,
-		driverQueue:       util.NewConditionalDelayingQueue(nil, ctx.Cfg.MinRetryDelay, ctx.Cfg.RetryDelayStep, ctx.Cfg.MaxRetryDelay),
-		loadBalancerQueue: util.NewConditionalDelayingQueue(util.QueueFilterForLB(ctx.LBInformer.Lister()), ctx.Cfg.MinRetryDelay, ctx.Cfg.RetryDelayStep, ctx.Cfg.MaxRetryDelay),
-		backendGroupQueue: util.NewConditionalDelayingQueue(nil, ctx.Cfg.MinRetryDelay, ctx.Cfg.RetryDelayStep, ctx.Cfg.MaxRetryDelay),
-		backendQueue:      util.NewConditionalDelayingQueue(util.QueueFilterForBackend(ctx.BRInformer.Lister()), ctx.Cfg.MinRetryDelay, ctx.Cfg.RetryDelayStep, ctx.Cfg.MaxRetryDelay),
-	}
-
-	c.driverCtrl = newDriverController(c.context.LbcfClient, c.context.LBDriverInformer.Lister())
-	c.lbCtrl = newLoadBalancerController(c.context.LbcfClient, c.context.LBInformer.Lister(), ctx.LBDriverInformer.Lister(), ctx.EventRecorder, util.NewWebhookInvoker())
+		driverQueue:       util.NewNamedConditionalDelayingQueue("driver", nil, ctx.Cfg.MinRetryDelay, ctx.Cfg.RetryDelayStep, ctx.Cfg.MaxRetryDelay),
+		loadBalancerQueue: util.NewNamedConditionalDelayingQueue("loadbalancer", util.QueueFilterForLB(ctx.LBInformer.Lister()), ctx.Cfg.MinRetryDelay, ctx.Cfg.RetryDelayStep, ctx.Cfg.MaxRetryDelay),
+		backendGroupQueue: util.NewNamedConditionalDelayingQueue("backendgroup", nil, ctx.Cfg.MinRetryDelay, ctx.Cfg.RetryDelayStep, ctx.Cfg.MaxRetryDelay),
+		backendQueue:      util.NewNamedConditionalDelayingQueue("backendrecord", util.QueueFilterForBackend(ctx.BRInformer.Lister()), ctx.Cfg.MinRetryDelay, ctx.Cfg.RetryDelayStep, ctx.Cfg.MaxRetryDelay),
+	}
+
+	// the invoker is shared, so that concurrency limit is applied to all webhook calls to a driver
+	invoker := util.NewWebhookInvokerWithConfig(util.WebhookInvokerConfig{
+		MaxConcurrentCallsPerDriver:    ctx.Cfg.MaxConcurrentCallsPerDriver,
+		SecretLister:                   ctx.SecretLister,
+		ServiceLister:                  ctx.SvcInformer.Lister(),
+		EndpointsLister:                ctx.EndpointsInformer.Lister(),
+		CircuitBreakerFailureThreshold: ctx.Cfg.CircuitBreakerFailureThreshold,
+		CircuitBreakerOpenDuration:     ctx.Cfg.CircuitBreakerOpenDuration,
+		// the CircuitBreakerOpen condition of the driver is updated by driver controller
+		OnCircuitBreakerStateChange: func(driverKey string) {
+			c.driverQueue.Add(driverKey)
+		},
+		MaxExecOutputBytes: ctx.Cfg.MaxExecOutputBytes,
+		ExecDriverDir:      ctx.Cfg.ExecDriverDir,
+		OnExecStderr: func(driver *v1beta1.LoadBalancerDriver, webHookName string, stderr string) {
+			if len(stderr) > maxExecStderrInEvent {
+				stderr = stderr[:maxExecStderrInEvent] + "...(truncated)"
+			}
+			ctx.EventRecorder.Eventf(driver, v1.EventTypeWarning, "ExecStderr", "webhook %s: %s", webHookName, stderr)
+		},
+	})
+	c.driverCtrl = newDriverController(c.context.LbcfClient, c.context.LBDriverInformer.Lister(), invoker)
+	c.lbCtrl = newLoadBalancerController(c.context.LbcfClient, c.context.LBInformer.Lister(), ctx.LBDriverInformer.Lister(), ctx.EventRecorder, invoker)
 	c.backendCtrl = newBackendController(
 		c.context.LbcfClient,
+		c.context.K8sClient,
 		c.context.BRInformer.Lister(),
 		ctx.LBDriverInformer.Lister(),
+		c.context.LBInformer.Lister(),
+		c.context.BGInformer.Lister(),
 		c.context.PodInformer.Lister(),
 		c.context.SvcInformer.Lister(),
 		c.context.NodeInformer.Lister(),
 		c.context.EventRecorder,
-		util.NewWebhookInvoker(),
+		invoker,
 	)
 	c.backendGroupCtrl = newBackendGroupController(
 		c.context.LbcfClient,
+		c.context.K8sClient,
 		c.context.LBInformer.Lister(),
 		c.context.BGInformer.Lister(),
 		c.context.BRInformer.Lister(),
 		c.context.PodInformer.Lister(),
 		c.context.SvcInformer.Lister(),
 		c.context.NodeInformer.Lister(),
+		c.context.EndpointsInformer.Lister(),
+		c.context.EventRecorder,
 	)
 
 	// enqueue backendgroup
@@ -79,6 +112,13 @@ func NewController(ctx *context.Context) *Controller {
 		DeleteFunc: c.deleteService,
 	}, c.context.Cfg.InformerResyncPeriod)
 
+	// enqueue backendgroup
+	c.context.EndpointsInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.addEndpoints,
+		UpdateFunc: c.updateEndpoints,
+		DeleteFunc: c.deleteEndpoints,
+	}, c.context.Cfg.InformerResyncPeriod)
+
 	// control loadBalancer lifecycle
 	c.context.LBInformer.Informer().AddEventHandlerWithResyncPeriod(cache.ResourceEventHandlerFuncs{
 		AddFunc:    c.addLoadBalancer,
@@ -122,19 +162,58 @@ type Controller struct {
 	loadBalancerQueue util.ConditionalRateLimitingInterface
 	backendGroupQueue util.ConditionalRateLimitingInterface
 	backendQueue      util.ConditionalRateLimitingInterface
+
+	// stopCh is closed when the controller is shutting down, no more items will be processed once it is closed
+	stopCh <-chan struct{}
+	// inflight tracks the items being processed
+	inflight sync.WaitGroup
 }
 
 // Start starts controller in a new goroutine
-func (c *Controller) Start() {
+func (c *Controller) Start(stopCh <-chan struct{}) {
+	c.stopCh = stopCh
 	go c.run()
 }
 
+// ShutDown shuts down all queues and waits for in-flight items for at most timeout.
+// It returns false if some items are still being processed after timeout.
+func (c *Controller) ShutDown(timeout time.Duration) bool {
+	c.driverQueue.ShutDown()
+	c.loadBalancerQueue.ShutDown()
+	c.backendGroupQueue.ShutDown()
+	c.backendQueue.ShutDown()
+
+	done := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
 func (c *Controller) run() {
-	c.context.WaitForCacheSync()
-	go wait.Until(c.lbWorker, time.Second, wait.NeverStop)
-	go wait.Until(c.driverWorker, time.Second, wait.NeverStop)
-	go wait.Until(c.backendGroupWorker, time.Second, wait.NeverStop)
-	go wait.Until(c.backendWorker, time.Second, wait.NeverStop)
+	if !c.context.WaitForCacheSync(c.stopCh) {
+		return
+	}
+	startWorkers(c.driverWorker, c.context.Cfg.DriverWorkers, c.stopCh)
+	startWorkers(c.lbWorker, c.context.Cfg.LoadBalancerWorkers, c.stopCh)
+	startWorkers(c.backendGroupWorker, c.context.Cfg.BackendGroupWorkers, c.stopCh)
+	startWorkers(c.backendWorker, c.context.Cfg.BackendRecordWorkers, c.stopCh)
+}
+
+// startWorkers starts the given number of workers, at least 1 worker is started
+func startWorkers(worker func(), workers int, stopCh <-chan struct{}) {
+	if workers < 1 {
+		workers = 1
+	}
+	for i := 0; i < workers; i++ {
+		go wait.Until(worker, time.Second, stopCh)
+	}
 }
 
 func (c *Controller) enqueue(obj interface{}, queue util.ConditionalRateLimitingInterface) {
@@ -175,32 +254,47 @@ func (c *Controller) processNextItem(queue util.ConditionalRateLimitingInterface
 	if quit {
 		return false
 	}
-
-	go func() {
-		defer queue.Done(key)
-
-		klog.V(3).Infof("sync start, key %s", key)
-		startTime := time.Now()
-		result := syncFunc(key.(string))
-
-		if !result.IsFailed() {
-			queue.Forget(key)
-		}
-
-		if result.IsFailed() {
-			klog.Infof("Failed key %s, reason: %v", key, result.GetFailReason())
-			queue.AddAfterMinimumDelay(key, result.GetNextRun())
-		} else if result.IsRunning() {
-			klog.Infof("Async key %s", key)
-			queue.AddAfterMinimumDelay(key, result.GetNextRun())
-		} else if result.IsPeriodic() {
-			klog.Infof("Periodic key %s", key)
-			queue.AddAfterFiltered(key, result.GetNextRun())
-		}
-
-		elapsed := time.Now().Sub(startTime)
-		klog.V(3).Infof("sync finished, key %s, took %s", key, elapsed.String())
-	}()
+	select {
+	case <-c.stopCh:
+		queue.Done(key)
+		return false
+	default:
+	}
+
+	c.inflight.Add(1)
+	defer c.inflight.Done()
+	defer queue.Done(key)
+
+	klog.V(3).Infof("sync start, key %s", key)
+	startTime := time.Now()
+	result := syncFunc(key.(string))
+
+	if !result.IsFailed() {
+		queue.Forget(key)
+	}
+
+	if result.IsFailed() {
+		klog.Infof("Failed key %s, reason: %v", key, result.GetFailReason())
+		metrics.IncSyncResult(queue.Name(), metrics.SyncResultFailed)
+		queue.AddAfterMinimumDelay(key, result.GetNextRun())
+	} else if result.IsRunning() {
+		klog.Infof("Async key %s", key)
+		metrics.IncSyncResult(queue.Name(), metrics.SyncResultAsync)
+		queue.AddAfterMinimumDelay(key, result.GetNextRun())
+	} else if result.IsThrottled() {
+		klog.V(3).Infof("Throttled key %s", key)
+		metrics.IncSyncResult(queue.Name(), metrics.SyncResultThrottled)
+		queue.AddAfterMinimumDelay(key, result.GetNextRun())
+	} else if result.IsPeriodic() {
+		klog.Infof("Periodic key %s", key)
+		metrics.IncSyncResult(queue.Name(), metrics.SyncResultPeriodic)
+		queue.AddAfterFiltered(key, result.GetNextRun())
+	} else {
+		metrics.IncSyncResult(queue.Name(), metrics.SyncResultFinished)
+	}
+
+	elapsed := time.Now().Sub(startTime)
+	klog.V(3).Infof("sync finished, key %s, took %s", key, elapsed.String())
 	return true
 }
 
@@ -219,7 +313,7 @@ func (c *Controller) updatePod(old, cur interface{}) {
 	}
 
 	labelChanged := !reflect.DeepEqual(oldPod.Labels, curPod.Labels)
-	statusChanged := util.PodAvailable(oldPod) != util.PodAvailable(curPod)
+	statusChanged := util.PodStatusChanged(oldPod, curPod)
 
 	if labelChanged || statusChanged {
 		oldGroups := c.backendGroupCtrl.listRelatedBackendGroupsForPod(oldPod)
@@ -292,6 +386,49 @@ func (c *Controller) deleteService(obj interface{}) {
 	c.addService(svc)
 }
 
+func (c *Controller) addEndpoints(obj interface{}) {
+	ep := obj.(*v1.Endpoints)
+	// svc is nil if not found, groups in Endpoints mode are still enqueued
+	svc, _ := c.backendGroupCtrl.serviceLister.Services(ep.Namespace).Get(ep.Name)
+	filter := func(group *v1beta1.BackendGroup) bool {
+		return util.IsEndpointsMatchBackendGroup(group, svc, ep)
+	}
+	keys, err := c.backendGroupCtrl.listRelatedBackendGroups(ep.Namespace, filter)
+	if err != nil {
+		klog.Errorf("skip endpoints(%s/%s) add, list backendgroup failed: %v", ep.Namespace, ep.Name, err)
+	}
+	for key := range keys {
+		c.enqueue(key, c.backendGroupQueue)
+	}
+}
+
+func (c *Controller) updateEndpoints(old, cur interface{}) {
+	oldEp := old.(*v1.Endpoints)
+	curEp := cur.(*v1.Endpoints)
+	if oldEp.ResourceVersion == curEp.ResourceVersion || reflect.DeepEqual(oldEp.Subsets, curEp.Subsets) {
+		return
+	}
+	c.addEndpoints(curEp)
+}
+
+func (c *Controller) deleteEndpoints(obj interface{}) {
+	if _, ok := obj.(*v1.Endpoints); ok {
+		c.addEndpoints(obj)
+		return
+	}
+	tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+	if !ok {
+		klog.Errorf("Couldn't get object from tombstone %#v", obj)
+		return
+	}
+	ep, ok := tombstone.Obj.(*v1.Endpoints)
+	if !ok {
+		klog.Errorf("Tombstone contained object that is not an Endpoints: %#v", obj)
+		return
+	}
+	c.addEndpoints(ep)
+}
+
 func (c *Controller) addBackendGroup(obj interface{}) {
 	c.enqueue(obj, c.backendGroupQueue)
 }
@@ -374,7 +511,36 @@ func (c *Controller) updateLoadBalancerDriver(old, cur interface{}) {
 	if oldDriver.ResourceVersion == curDriver.ResourceVersion {
 		return
 	}
-	c.enqueue(cur, c.driverQueue)
+	if util.NeedEnqueueDriver(oldDriver, curDriver) {
+		c.enqueue(cur, c.driverQueue)
+	}
+	// LoadBalancers and BackendRecords are backed off while the driver is not ready, resume them once it is ready again
+	if !util.IsDriverReady(oldDriver) && util.IsDriverReady(curDriver) {
+		c.enqueueObjectsOfDriver(curDriver)
+	}
+}
+
+func (c *Controller) enqueueObjectsOfDriver(driver *v1beta1.LoadBalancerDriver) {
+	lbs, err := c.context.LBInformer.Lister().List(labels.Everything())
+	if err != nil {
+		klog.Errorf("list LoadBalancers failed: %v", err)
+		return
+	}
+	for _, lb := range lbs {
+		if lb.Spec.LBDriver == driver.Name && util.GetDriverNamespace(lb.Spec.LBDriver, lb.Namespace) == driver.Namespace {
+			c.enqueue(lb, c.loadBalancerQueue)
+		}
+	}
+	backends, err := c.context.BRInformer.Lister().List(labels.Everything())
+	if err != nil {
+		klog.Errorf("list BackendRecords failed: %v", err)
+		return
+	}
+	for _, backend := range backends {
+		if backend.Spec.LBDriver == driver.Name && util.GetDriverNamespace(backend.Spec.LBDriver, backend.Namespace) == driver.Namespace {
+			c.enqueue(backend, c.backendQueue)
+		}
+	}
 }
 
 func (c *Controller) deleteLoadBalancerDriver(obj interface{}) {
@@ -39,6 +39,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/listers/core/v1"
 )
 
@@ -53,6 +54,7 @@ func TestLBCFControllerAddPod(t *testing.T) {
 	bg2 := newFakeBackendGroupOfPods("", "bg-2", "", 80, "tcp", nil, nil, []string{"pod-2"})
 	bgCtrl := newBackendGroupController(
 		fake.NewSimpleClientset(),
+		k8sfake.NewSimpleClientset(),
 		&fakeLBLister{},
 		&fakeBackendGroupLister{
 			list: []*lbcfapi.BackendGroup{bg1, bg2},
@@ -61,6 +63,8 @@ func TestLBCFControllerAddPod(t *testing.T) {
 		&fakePodLister{},
 		&fakeSvcListerWithStore{},
 		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
 	)
 	c := newFakeLBCFController(nil, nil, nil, bgCtrl)
 
@@ -113,6 +117,7 @@ func TestLBCFControllerUpdatePod_PodStatusChange(t *testing.T) {
 
 	bgCtrl := newBackendGroupController(
 		fake.NewSimpleClientset(),
+		k8sfake.NewSimpleClientset(),
 		&fakeLBLister{},
 		&fakeBackendGroupLister{
 			list: []*lbcfapi.BackendGroup{bg1},
@@ -121,6 +126,8 @@ func TestLBCFControllerUpdatePod_PodStatusChange(t *testing.T) {
 		&fakePodLister{},
 		&fakeSvcListerWithStore{},
 		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
 	)
 	c := newFakeLBCFController(nil, nil, nil, bgCtrl)
 
@@ -199,6 +206,7 @@ func TestLBCFControllerUpdatePod_PodLabelChange(t *testing.T) {
 
 	bgCtrl := newBackendGroupController(
 		fake.NewSimpleClientset(),
+		k8sfake.NewSimpleClientset(),
 		&fakeLBLister{},
 		&fakeBackendGroupLister{
 			list: []*lbcfapi.BackendGroup{bg1, bg2},
@@ -207,6 +215,8 @@ func TestLBCFControllerUpdatePod_PodLabelChange(t *testing.T) {
 		&fakePodLister{},
 		&fakeSvcListerWithStore{},
 		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
 	)
 	c := newFakeLBCFController(nil, nil, nil, bgCtrl)
 
@@ -287,6 +297,7 @@ func TestLBCFControllerDeletePod(t *testing.T) {
 
 	bgCtrl := newBackendGroupController(
 		fake.NewSimpleClientset(),
+		k8sfake.NewSimpleClientset(),
 		&fakeLBLister{},
 		&fakeBackendGroupLister{
 			list: []*lbcfapi.BackendGroup{bg1},
@@ -295,6 +306,8 @@ func TestLBCFControllerDeletePod(t *testing.T) {
 		&fakePodLister{},
 		&fakeSvcListerWithStore{},
 		&fakeNodeListerWithStore{},
+		&fakeEndpointsListerWithStore{},
+		&fakeEventRecorder{},
 	)
 	c := newFakeLBCFController(nil, nil, nil, bgCtrl)
 
@@ -328,7 +341,7 @@ func TestLBCFControllerDeletePod(t *testing.T) {
 }
 
 func TestLBCFControllerAddBackendGroup(t *testing.T) {
-	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{})
+	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEndpointsListerWithStore{}, &fakeEventRecorder{})
 	c := newFakeLBCFController(nil, nil, nil, bgCtrl)
 	bg := newFakeBackendGroupOfPods("", "bg", "", 80, "tcp", nil, nil, nil)
 	c.addBackendGroup(bg)
@@ -347,7 +360,7 @@ func TestLBCFControllerAddBackendGroup(t *testing.T) {
 }
 
 func TestLBCFControllerUpdateBackendGroup(t *testing.T) {
-	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{})
+	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEndpointsListerWithStore{}, &fakeEventRecorder{})
 	c := newFakeLBCFController(nil, nil, nil, bgCtrl)
 	oldGroup := newFakeBackendGroupOfPods("", "bg", "", 80, "tcp", nil, nil, nil)
 	curGroup := newFakeBackendGroupOfPods("", "bg", "", 80, "tcp", nil, nil, nil)
@@ -374,7 +387,7 @@ func TestLBCFControllerUpdateBackendGroup(t *testing.T) {
 }
 
 func TestLBCFControllerDeleteBackendGroup(t *testing.T) {
-	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{})
+	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEndpointsListerWithStore{}, &fakeEventRecorder{})
 	c := newFakeLBCFController(nil, nil, nil, bgCtrl)
 	bg := newFakeBackendGroupOfPods("", "bg", "", 80, "tcp", nil, nil, nil)
 	c.deleteBackendGroup(bg)
@@ -413,9 +426,9 @@ func TestLBCFControllerAddService(t *testing.T) {
 	bg := newFakeBackendGroupOfService(svc.Namespace, "bg", "lb", 80, "TCP", svc.Name)
 	bg2 := newFakeBackendGroupOfService(svc.Namespace, "another-bg", "lb", 80, "TCP", "another-svc")
 
-	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
+	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
 		list: []*lbcfapi.BackendGroup{bg, bg2},
-	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{})
+	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEndpointsListerWithStore{}, &fakeEventRecorder{})
 	c := newFakeLBCFController(nil, nil, nil, bgCtrl)
 
 	c.addService(svc)
@@ -442,9 +455,9 @@ func TestLBCFControllerUpdateService(t *testing.T) {
 	bg := newFakeBackendGroupOfService(oldSvc.Namespace, "bg", "lb", 80, "TCP", oldSvc.Name)
 	bg2 := newFakeBackendGroupOfService(oldSvc.Namespace, "another-bg", "lb", 80, "TCP", "another-svc")
 
-	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
+	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
 		list: []*lbcfapi.BackendGroup{bg, bg2},
-	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{})
+	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEndpointsListerWithStore{}, &fakeEventRecorder{})
 	c := newFakeLBCFController(nil, nil, nil, bgCtrl)
 
 	c.updateService(oldSvc, &statusChangedSvc)
@@ -478,9 +491,9 @@ func TestLBCFControllerDeleteService(t *testing.T) {
 	tomestoneKey, _ := controller.KeyFunc(bg)
 	tombstone := cache.DeletedFinalStateUnknown{Key: tomestoneKey, Obj: svc}
 
-	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
+	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
 		list: []*lbcfapi.BackendGroup{bg, bg2},
-	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{})
+	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEndpointsListerWithStore{}, &fakeEventRecorder{})
 	c := newFakeLBCFController(nil, nil, nil, bgCtrl)
 
 	c.deleteService(svc)
@@ -514,15 +527,54 @@ func TestLBCFControllerDeleteService(t *testing.T) {
 	c.backendGroupQueue.Done(groupKey)
 }
 
+func TestLBCFControllerAddEndpoints(t *testing.T) {
+	localSvc := newFakeService("", "local-svc", apiv1.ServiceTypeNodePort)
+	localSvc.Spec.ExternalTrafficPolicy = apiv1.ServiceExternalTrafficPolicyTypeLocal
+	clusterSvc := newFakeService("", "cluster-svc", apiv1.ServiceTypeNodePort)
+	clusterSvc.Spec.ExternalTrafficPolicy = apiv1.ServiceExternalTrafficPolicyTypeCluster
+	bg := newFakeBackendGroupOfService("", "bg", "lb", 80, "TCP", localSvc.Name)
+	bg2 := newFakeBackendGroupOfService("", "another-bg", "lb", 80, "TCP", clusterSvc.Name)
+
+	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
+		list: []*lbcfapi.BackendGroup{bg, bg2},
+	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{
+		store: map[string]*apiv1.Service{
+			localSvc.Name:   localSvc,
+			clusterSvc.Name: clusterSvc,
+		},
+	}, &fakeNodeListerWithStore{}, &fakeEndpointsListerWithStore{}, &fakeEventRecorder{})
+	c := newFakeLBCFController(nil, nil, nil, bgCtrl)
+
+	c.addEndpoints(&apiv1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: clusterSvc.Name}})
+	if c.backendGroupQueue.Len() != 0 {
+		t.Fatalf("queue length should be 0, get %d", c.backendGroupQueue.Len())
+	}
+
+	c.addEndpoints(&apiv1.Endpoints{ObjectMeta: metav1.ObjectMeta{Name: localSvc.Name}})
+	if c.backendGroupQueue.Len() != 1 {
+		t.Fatalf("queue length should be 1, get %d", c.backendGroupQueue.Len())
+	}
+
+	groupKey, done := c.backendGroupQueue.Get()
+	if groupKey == nil || done {
+		t.Error("failed to enqueue BackendGroup")
+	} else if key, ok := groupKey.(string); !ok {
+		t.Error("key is not a string")
+	} else if expectedKey, _ := controller.KeyFunc(bg); expectedKey != key {
+		t.Errorf("expected Backendgroup key %s found %s", expectedKey, key)
+	}
+	c.backendGroupQueue.Done(groupKey)
+}
+
 func TestLBCFControllerAddLoadBalancer(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	bg := newFakeBackendGroupOfPods(lb.Namespace, "bg", lb.Name, 80, "tcp", nil, nil, nil)
 	bg2 := newFakeBackendGroupOfPods(lb.Namespace, "bg", "another-lb", 80, "tcp", nil, nil, nil)
 
 	lbCtrl := newLoadBalancerController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeDriverLister{}, &fakeEventRecorder{}, &fakeSuccInvoker{})
-	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
+	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
 		list: []*lbcfapi.BackendGroup{bg, bg2},
-	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{})
+	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEndpointsListerWithStore{}, &fakeEventRecorder{})
 	c := newFakeLBCFController(nil, lbCtrl, nil, bgCtrl)
 
 	c.addLoadBalancer(lb)
@@ -556,9 +608,9 @@ func TestLBCFControllerAddLoadBalancer(t *testing.T) {
 func TestLBCFControllerUpdateLoadBalancer(t *testing.T) {
 	lbCtrl := newLoadBalancerController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeDriverLister{}, &fakeEventRecorder{}, &fakeSuccInvoker{})
 	bg := newFakeBackendGroupOfPods("", "bg", "lb", 80, "TCP", nil, nil, nil)
-	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
+	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
 		list: []*lbcfapi.BackendGroup{bg},
-	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{})
+	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEndpointsListerWithStore{}, &fakeEventRecorder{})
 	type testCase struct {
 		name          string
 		old           *lbcfapi.LoadBalancer
@@ -763,9 +815,9 @@ func TestLBCFControllerDeleteLoadBalancer(t *testing.T) {
 	tombstone := cache.DeletedFinalStateUnknown{Key: tomestoneKey, Obj: lb}
 
 	lbCtrl := newLoadBalancerController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeDriverLister{}, &fakeEventRecorder{}, &fakeSuccInvoker{})
-	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
+	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
 		list: []*lbcfapi.BackendGroup{bg, bg2},
-	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{})
+	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEndpointsListerWithStore{}, &fakeEventRecorder{})
 	c := newFakeLBCFController(nil, lbCtrl, nil, bgCtrl)
 
 	c.deleteLoadBalancer(lb)
@@ -824,7 +876,7 @@ func TestLBCFControllerDeleteLoadBalancer(t *testing.T) {
 
 func TestLBCFControllerAddDriver(t *testing.T) {
 	driver := newFakeDriver("", "driver")
-	driverCtrl := newDriverController(fake.NewSimpleClientset(), &fakeDriverLister{})
+	driverCtrl := newDriverController(fake.NewSimpleClientset(), &fakeDriverLister{}, &fakeSuccInvoker{})
 	c := newFakeLBCFController(driverCtrl, nil, nil, nil)
 
 	c.addLoadBalancerDriver(driver)
@@ -845,7 +897,7 @@ func TestLBCFControllerAddDriver(t *testing.T) {
 func TestLBCFControllerUpdateDriver(t *testing.T) {
 	oldDriver := newFakeDriver("", "driver")
 	curDriver := newFakeDriver("", "driver")
-	driverCtrl := newDriverController(fake.NewSimpleClientset(), &fakeDriverLister{})
+	driverCtrl := newDriverController(fake.NewSimpleClientset(), &fakeDriverLister{}, &fakeSuccInvoker{})
 	c := newFakeLBCFController(driverCtrl, nil, nil, nil)
 
 	c.updateLoadBalancerDriver(oldDriver, curDriver)
@@ -853,8 +905,16 @@ func TestLBCFControllerUpdateDriver(t *testing.T) {
 		t.Fatalf("queue length should be 0, get %d", c.driverQueue.Len())
 	}
 
+	// status updates are ignored
 	curDriver.ResourceVersion = "2"
 	c.updateLoadBalancerDriver(oldDriver, curDriver)
+	if c.driverQueue.Len() != 0 {
+		t.Fatalf("queue length should be 0, get %d", c.driverQueue.Len())
+	}
+
+	curDriver.ResourceVersion = "3"
+	curDriver.Generation = 2
+	c.updateLoadBalancerDriver(oldDriver, curDriver)
 	if c.driverQueue.Len() != 1 {
 		t.Fatalf("queue length should be 1, get %d", c.driverQueue.Len())
 	}
@@ -871,7 +931,7 @@ func TestLBCFControllerUpdateDriver(t *testing.T) {
 
 func TestLBCFControllerDeleteDriver(t *testing.T) {
 	driver := newFakeDriver("", "driver")
-	driverCtrl := newDriverController(fake.NewSimpleClientset(), &fakeDriverLister{})
+	driverCtrl := newDriverController(fake.NewSimpleClientset(), &fakeDriverLister{}, &fakeSuccInvoker{})
 	tomestoneKey, _ := controller.KeyFunc(driver)
 	tombstone := cache.DeletedFinalStateUnknown{Key: tomestoneKey, Obj: driver}
 
@@ -908,7 +968,7 @@ func TestLBCFControllerDeleteDriver(t *testing.T) {
 
 func TestLBCFControllerAddBackendRecord(t *testing.T) {
 	record := newFakeBackendRecord("", "record")
-	backendCtrl := newBackendController(fake.NewSimpleClientset(), &fakeBackendLister{}, &fakeDriverLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEventRecorder{}, &fakeSuccInvoker{})
+	backendCtrl := newBackendController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeBackendLister{}, &fakeDriverLister{}, &fakeLBLister{}, &fakeBackendGroupLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEventRecorder{}, &fakeSuccInvoker{})
 	c := newFakeLBCFController(nil, nil, backendCtrl, nil)
 
 	c.addBackendRecord(record)
@@ -935,11 +995,11 @@ func TestLBCFControllerUpdateBackendRecord(t *testing.T) {
 		expectQueue   int
 		expectBGQueue int
 	}
-	backendCtrl := newBackendController(fake.NewSimpleClientset(), &fakeBackendLister{}, &fakeDriverLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEventRecorder{}, &fakeSuccInvoker{})
+	backendCtrl := newBackendController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeBackendLister{}, &fakeDriverLister{}, &fakeLBLister{}, &fakeBackendGroupLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEventRecorder{}, &fakeSuccInvoker{})
 	bg := newFakeBackendGroupOfPods("", "bg", "lb", 80, "TCP", nil, nil, nil)
-	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
+	bgCtrl := newBackendGroupController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeLBLister{}, &fakeBackendGroupLister{
 		list: []*lbcfapi.BackendGroup{bg},
-	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{})
+	}, &fakeBackendLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEndpointsListerWithStore{}, &fakeEventRecorder{})
 	cases := []testCase{
 		{
 			name: "periodic-resync",
@@ -1168,8 +1228,8 @@ func TestLBCFControllerUpdateBackendRecord(t *testing.T) {
 func TestLBCFControllerDeleteBackendRecord(t *testing.T) {
 	lb := newFakeLoadBalancer("", "lb", nil, nil)
 	group := newFakeBackendGroupOfPods(lb.Namespace, "group", lb.Name, 80, "tcp", nil, nil, []string{"pod-0"})
-	record := util.ConstructPodBackendRecord(lb, group, newFakePod("", "pod-0", nil, true, false))
-	backendCtrl := newBackendController(fake.NewSimpleClientset(), &fakeBackendLister{}, &fakeDriverLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEventRecorder{}, &fakeSuccInvoker{})
+	record := util.ConstructPodBackendRecord(lb, group, newFakePod("", "pod-0", nil, true, false), util.BackendPorts(group)[0])
+	backendCtrl := newBackendController(fake.NewSimpleClientset(), k8sfake.NewSimpleClientset(), &fakeBackendLister{}, &fakeDriverLister{}, &fakeLBLister{}, &fakeBackendGroupLister{}, &fakePodLister{}, &fakeSvcListerWithStore{}, &fakeNodeListerWithStore{}, &fakeEventRecorder{}, &fakeSuccInvoker{})
 	tomestoneKey, _ := controller.KeyFunc(record)
 	tombstone := cache.DeletedFinalStateUnknown{Key: tomestoneKey, Obj: record}
 	c := newFakeLBCFController(nil, nil, backendCtrl, nil)
@@ -1299,6 +1359,47 @@ func TestLBCFControllerProcessNextItemPeriodic(t *testing.T) {
 	}
 }
 
+func TestLBCFControllerShutDown(t *testing.T) {
+	stopCh := make(chan struct{})
+	ctrl := &Controller{
+		driverQueue:       util.NewConditionalDelayingQueue(nil, time.Second, time.Second, 2*time.Second),
+		loadBalancerQueue: util.NewConditionalDelayingQueue(nil, time.Second, time.Second, 2*time.Second),
+		backendGroupQueue: util.NewConditionalDelayingQueue(nil, time.Second, time.Second, 2*time.Second),
+		backendQueue:      util.NewConditionalDelayingQueue(nil, time.Second, time.Second, 2*time.Second),
+		stopCh:            stopCh,
+	}
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	ctrl.enqueue("running", ctrl.driverQueue)
+	go ctrl.processNextItem(ctrl.driverQueue, func(key string) *util.SyncResult {
+		close(started)
+		<-finished
+		return util.FinishedResult()
+	})
+	<-started
+
+	close(stopCh)
+	called := false
+	ctrl.enqueue("new", ctrl.driverQueue)
+	if ctrl.processNextItem(ctrl.driverQueue, func(key string) *util.SyncResult {
+		called = true
+		return util.FinishedResult()
+	}) {
+		t.Errorf("expect processNextItem returns false after stopCh closed")
+	}
+	if called {
+		t.Errorf("expect no item processed after stopCh closed")
+	}
+
+	if ctrl.ShutDown(100 * time.Millisecond) {
+		t.Errorf("expect timeout waiting for in-flight item")
+	}
+	close(finished)
+	if !ctrl.ShutDown(time.Second) {
+		t.Errorf("expect in-flight item finished")
+	}
+}
+
 func newFakeBackendRecord(namespace, name string) *lbcfapi.BackendRecord {
 	return &lbcfapi.BackendRecord{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1737,6 +1838,33 @@ func (l *fakeSvcListerWithStore) GetPodServices(pod *apiv1.Pod) ([]*apiv1.Servic
 	return services, nil
 }
 
+type fakeEndpointsListerWithStore struct {
+	// map: name -> Endpoints
+	store map[string]*apiv1.Endpoints
+}
+
+func (l *fakeEndpointsListerWithStore) Get(name string) (*apiv1.Endpoints, error) {
+	ep, ok := l.store[name]
+	if !ok {
+		return nil, errors.NewNotFound(schema.GroupResource{
+			Group:    "core/v1",
+			Resource: "Endpoints",
+		}, name)
+	}
+	return ep, nil
+}
+
+func (l *fakeEndpointsListerWithStore) List(selector labels.Selector) (ret []*apiv1.Endpoints, err error) {
+	for _, ep := range l.store {
+		ret = append(ret, ep)
+	}
+	return
+}
+
+func (l *fakeEndpointsListerWithStore) Endpoints(namespace string) v1.EndpointsNamespaceLister {
+	return l
+}
+
 type fakeSuccInvoker struct{}
 
 func (c *fakeSuccInvoker) CallValidateLoadBalancer(driver *lbcfapi.LoadBalancerDriver, req *webhooks.ValidateLoadBalancerRequest) (*webhooks.ValidateLoadBalancerResponse, error) {
@@ -1812,6 +1940,25 @@ func (c *fakeSuccInvoker) CallDeregisterBackend(driver *lbcfapi.LoadBalancerDriv
 	}, nil
 }
 
+func (c *fakeSuccInvoker) CallDrainBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	return &webhooks.BackendOperationResponse{
+		ResponseForFailRetryHooks: webhooks.ResponseForFailRetryHooks{
+			Status: webhooks.StatusSucc,
+			Msg:    "fake succ",
+		},
+	}, nil
+}
+
+func (c *fakeSuccInvoker) CallHealthCheck(driver *lbcfapi.LoadBalancerDriver) error {
+	return nil
+}
+
+func (c *fakeSuccInvoker) GetCircuitBreakerStatus(driver *lbcfapi.LoadBalancerDriver) util.CircuitBreakerStatus {
+	return util.CircuitBreakerStatus{State: util.CircuitClosed}
+}
+
+func (c *fakeSuccInvoker) ForgetDriver(driverKey string) {}
+
 type fakeFailInvoker struct{}
 
 func (c *fakeFailInvoker) CallValidateLoadBalancer(driver *lbcfapi.LoadBalancerDriver, req *webhooks.ValidateLoadBalancerRequest) (*webhooks.ValidateLoadBalancerResponse, error) {
@@ -1886,6 +2033,25 @@ func (c *fakeFailInvoker) CallDeregisterBackend(driver *lbcfapi.LoadBalancerDriv
 	}, nil
 }
 
+func (c *fakeFailInvoker) CallDrainBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	return &webhooks.BackendOperationResponse{
+		ResponseForFailRetryHooks: webhooks.ResponseForFailRetryHooks{
+			Status: webhooks.StatusFail,
+			Msg:    "fake fail",
+		},
+	}, nil
+}
+
+func (c *fakeFailInvoker) CallHealthCheck(driver *lbcfapi.LoadBalancerDriver) error {
+	return fmt.Errorf("fake health check failure")
+}
+
+func (c *fakeFailInvoker) GetCircuitBreakerStatus(driver *lbcfapi.LoadBalancerDriver) util.CircuitBreakerStatus {
+	return util.CircuitBreakerStatus{State: util.CircuitClosed}
+}
+
+func (c *fakeFailInvoker) ForgetDriver(driverKey string) {}
+
 func drainingDriverLister() lbcflister.LoadBalancerDriverLister {
 	return &fakeDriverLister{
 		get: &lbcfapi.LoadBalancerDriver{
@@ -1991,6 +2157,26 @@ func (c *fakeRunningInvoker) CallDeregisterBackend(driver *lbcfapi.LoadBalancerD
 	}, nil
 }
 
+func (c *fakeRunningInvoker) CallDrainBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	return &webhooks.BackendOperationResponse{
+		ResponseForFailRetryHooks: webhooks.ResponseForFailRetryHooks{
+			Status:                 webhooks.StatusRunning,
+			Msg:                    "fake running",
+			MinRetryDelayInSeconds: 60,
+		},
+	}, nil
+}
+
+func (c *fakeRunningInvoker) CallHealthCheck(driver *lbcfapi.LoadBalancerDriver) error {
+	return nil
+}
+
+func (c *fakeRunningInvoker) GetCircuitBreakerStatus(driver *lbcfapi.LoadBalancerDriver) util.CircuitBreakerStatus {
+	return util.CircuitBreakerStatus{State: util.CircuitClosed}
+}
+
+func (c *fakeRunningInvoker) ForgetDriver(driverKey string) {}
+
 type fakeInvalidInvoker struct{}
 
 func (c *fakeInvalidInvoker) CallValidateLoadBalancer(driver *lbcfapi.LoadBalancerDriver, req *webhooks.ValidateLoadBalancerRequest) (*webhooks.ValidateLoadBalancerResponse, error) {
@@ -2071,6 +2257,26 @@ func (c *fakeInvalidInvoker) CallDeregisterBackend(driver *lbcfapi.LoadBalancerD
 	}, nil
 }
 
+func (c *fakeInvalidInvoker) CallDrainBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	return &webhooks.BackendOperationResponse{
+		ResponseForFailRetryHooks: webhooks.ResponseForFailRetryHooks{
+			Status:                 "invalid status",
+			Msg:                    "fake running",
+			MinRetryDelayInSeconds: 60,
+		},
+	}, nil
+}
+
+func (c *fakeInvalidInvoker) CallHealthCheck(driver *lbcfapi.LoadBalancerDriver) error {
+	return nil
+}
+
+func (c *fakeInvalidInvoker) GetCircuitBreakerStatus(driver *lbcfapi.LoadBalancerDriver) util.CircuitBreakerStatus {
+	return util.CircuitBreakerStatus{State: util.CircuitClosed}
+}
+
+func (c *fakeInvalidInvoker) ForgetDriver(driverKey string) {}
+
 type fakeEventRecorder struct {
 	store map[string]string
 }
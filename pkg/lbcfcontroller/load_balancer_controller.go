@@ -84,6 +84,10 @@ func (c *loadBalancerController) createLoadBalancer(lb *lbcfapi.LoadBalancer) *u
 	if err != nil {
 		return util.ErrorResult(fmt.Errorf("retrieve driver %q for LoadBalancer %s failed: %v", lb.Spec.LBDriver, lb.Name, err))
 	}
+	if !util.IsDriverReady(driver) {
+		c.eventRecorder.Eventf(lb, apicore.EventTypeWarning, "DriverNotReady", "driver %s/%s is not ready", driver.Namespace, driver.Name)
+		return util.FailResult(util.GetDuration(driver.Spec.HealthCheck.Period, util.DefaultHealthCheckPeriod), "driver not ready")
+	}
 	req := &webhooks.CreateLoadBalancerRequest{
 		RequestForRetryHooks: webhooks.RequestForRetryHooks{
 			RecordID: fmt.Sprintf("createLoadBalancer(%s)", lb.UID),
@@ -144,6 +148,10 @@ func (c *loadBalancerController) ensureLoadBalancer(lb *lbcfapi.LoadBalancer) *u
 	if err != nil {
 		return util.ErrorResult(fmt.Errorf("retrieve driver %q for LoadBalancer %s failed: %v", lb.Spec.LBDriver, lb.Name, err))
 	}
+	if !util.IsDriverReady(driver) {
+		c.eventRecorder.Eventf(lb, apicore.EventTypeWarning, "DriverNotReady", "driver %s/%s is not ready", driver.Namespace, driver.Name)
+		return util.FailResult(util.GetDuration(driver.Spec.HealthCheck.Period, util.DefaultHealthCheckPeriod), "driver not ready")
+	}
 	req := &webhooks.EnsureLoadBalancerRequest{
 		RequestForRetryHooks: webhooks.RequestForRetryHooks{
 			RecordID: fmt.Sprintf("ensureLoadBalancer(%s)", lb.UID),
@@ -205,6 +213,10 @@ func (c *loadBalancerController) deleteLoadBalancer(lb *lbcfapi.LoadBalancer) *u
 	if err != nil {
 		return util.ErrorResult(fmt.Errorf("retrieve driver %q for LoadBalancer %s failed: %v", lb.Spec.LBDriver, lb.Name, err))
 	}
+	if !util.IsDriverReady(driver) {
+		c.eventRecorder.Eventf(lb, apicore.EventTypeWarning, "DriverNotReady", "driver %s/%s is not ready", driver.Namespace, driver.Name)
+		return util.FailResult(util.GetDuration(driver.Spec.HealthCheck.Period, util.DefaultHealthCheckPeriod), "driver not ready")
+	}
 	req := &webhooks.DeleteLoadBalancerRequest{
 		RequestForRetryHooks: webhooks.RequestForRetryHooks{
 			RecordID: fmt.Sprintf("deleteLoadBalancer(%s)", lb.UID),
@@ -545,3 +545,45 @@ func TestLoadBalancerDeleteInvalid(t *testing.T) {
 		t.Fatalf("expect reason InvalidDeleteLoadBalancer, get %s", reason)
 	}
 }
+
+func TestLoadBalancerCreateDriverNotReady(t *testing.T) {
+	lb := newFakeLoadBalancer("", "test-lb", nil, nil)
+	lb.Spec.LBDriver = "test-driver"
+	driver := newFakeDriver(lb.Namespace, lb.Spec.LBDriver)
+	driver.Spec.HealthCheck = &lbcfapi.DriverHealthCheckConfig{}
+	driver.Status.Conditions = []lbcfapi.LoadBalancerDriverCondition{
+		{
+			Type:   lbcfapi.DriverReady,
+			Status: lbcfapi.ConditionFalse,
+			Reason: lbcfapi.ReasonHealthCheckFailed.String(),
+		},
+	}
+	fakeClient := fake.NewSimpleClientset(lb)
+	store := make(map[string]string)
+	ctrl := newLoadBalancerController(
+		fakeClient,
+		&fakeLBLister{
+			get: lb,
+		},
+		&fakeDriverLister{
+			get: driver,
+		},
+		&fakeEventRecorder{store: store},
+		&fakeSuccInvoker{})
+	key, _ := controller.KeyFunc(lb)
+	result := ctrl.syncLB(key)
+	if !result.IsFailed() {
+		t.Fatalf("expect failed, get %+v", result)
+	} else if result.GetNextRun() != util.DefaultHealthCheckPeriod {
+		t.Fatalf("expect delay %v, get %v", util.DefaultHealthCheckPeriod, result.GetNextRun())
+	}
+	get, _ := fakeClient.LbcfV1beta1().LoadBalancers(lb.Namespace).Get(lb.Name, v1.GetOptions{})
+	if util.LBCreated(get) {
+		t.Errorf("expect LoadBalancer created=false, get status: %#v", get.Status)
+	}
+	if reason, ok := store[lb.Name]; !ok {
+		t.Fatalf("expect event for %s, get %v", lb.Name, store)
+	} else if reason != "DriverNotReady" {
+		t.Fatalf("expect reason DriverNotReady, get %s", reason)
+	}
+}
@@ -0,0 +1,184 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	namespace = "lbcf"
+)
+
+const (
+	// SyncResultFinished indicates the sync method finished successfully
+	SyncResultFinished = "finished"
+
+	// SyncResultFailed indicates the sync method failed and the key is requeued
+	SyncResultFailed = "failed"
+
+	// SyncResultAsync indicates the operation is still running and the key is requeued
+	SyncResultAsync = "async"
+
+	// SyncResultThrottled indicates webhook calls are throttled by driver limits and the key is requeued
+	SyncResultThrottled = "throttled"
+
+	// SyncResultPeriodic indicates the operation finished and will be executed again periodically
+	SyncResultPeriodic = "periodic"
+)
+
+const (
+	// WebhookResultError indicates the webhook is not successfully called, e.g. timeout, http error, invalid json
+	WebhookResultError = "Error"
+
+	// WebhookResultInvalid indicates the webhook returns an unknown status
+	WebhookResultInvalid = "Invalid"
+
+	// WebhookResultThrottled indicates the webhook is not called because of limits declared by the driver
+	WebhookResultThrottled = "Throttled"
+
+	// WebhookResultCircuitOpen indicates the webhook is not called because the circuit breaker of the driver is open
+	WebhookResultCircuitOpen = "CircuitOpen"
+
+	// WebhookResultNotImplemented indicates the webhook is optional and not implemented by the driver
+	WebhookResultNotImplemented = "NotImplemented"
+)
+
+// circuitBreakerStates are values of the state label of circuit breaker metrics
+var circuitBreakerStates = []string{"Closed", "Open", "HalfOpen"}
+
+var (
+	webhookDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "webhook",
+			Name:      "call_duration_seconds",
+			Help:      "Latency of webhook calls to drivers, partitioned by driver, webhook and result.",
+			Buckets:   []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+		},
+		[]string{"driver", "webhook", "result"},
+	)
+
+	queueRetries = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "queue",
+			Name:      "retries_total",
+			Help:      "Total number of items requeued with delay, partitioned by queue.",
+		},
+		[]string{"queue"},
+	)
+
+	syncResults = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "controller",
+			Name:      "sync_results_total",
+			Help:      "Total number of sync results, partitioned by queue and result.",
+		},
+		[]string{"queue", "result"},
+	)
+
+	circuitBreakerState = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "webhook",
+			Name:      "circuit_breaker_state",
+			Help:      "State of the circuit breaker of each driver, the value is 1 for the current state and 0 for others.",
+		},
+		[]string{"driver", "state"},
+	)
+
+	queueDepth = &queueDepthCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "queue", "depth"),
+			"Current number of items waiting to be processed, partitioned by queue.",
+			[]string{"queue"},
+			nil,
+		),
+	}
+)
+
+func init() {
+	prometheus.MustRegister(webhookDuration)
+	prometheus.MustRegister(queueRetries)
+	prometheus.MustRegister(syncResults)
+	prometheus.MustRegister(queueDepth)
+	prometheus.MustRegister(circuitBreakerState)
+}
+
+// Handler returns the http.Handler that exposes all registered metrics
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveWebhookCall records the latency and result of a webhook call
+func ObserveWebhookCall(driver string, webhook string, result string, elapsed time.Duration) {
+	webhookDuration.WithLabelValues(driver, webhook, result).Observe(elapsed.Seconds())
+}
+
+// IncQueueRetries increases the retry counter of the given queue
+func IncQueueRetries(queue string) {
+	queueRetries.WithLabelValues(queue).Inc()
+}
+
+// IncSyncResult increases the counter of the given sync result
+func IncSyncResult(queue string, result string) {
+	syncResults.WithLabelValues(queue, result).Inc()
+}
+
+// SetCircuitBreakerState records the current state of the circuit breaker of driver
+func SetCircuitBreakerState(driver string, state string) {
+	for _, s := range circuitBreakerStates {
+		var value float64
+		if s == state {
+			value = 1
+		}
+		circuitBreakerState.WithLabelValues(driver, s).Set(value)
+	}
+}
+
+// RegisterQueueDepth makes depth of the named queue reported as lbcf_queue_depth.
+// Registering the same name again replaces the previous one.
+func RegisterQueueDepth(queue string, lenFunc func() int) {
+	queueDepth.queues.Store(queue, lenFunc)
+}
+
+type queueDepthCollector struct {
+	desc   *prometheus.Desc
+	queues sync.Map
+}
+
+// Describe implements prometheus.Collector
+func (c *queueDepthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+// Collect implements prometheus.Collector
+func (c *queueDepthCollector) Collect(ch chan<- prometheus.Metric) {
+	c.queues.Range(func(key, value interface{}) bool {
+		lenFunc := value.(func() int)
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(lenFunc()), key.(string))
+		return true
+	})
+}
@@ -0,0 +1,85 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+
+	corev1 "k8s.io/client-go/listers/core/v1"
+)
+
+// driverCredential is read from the Secret referenced by DriverAuthConfig
+type driverCredential struct {
+	authType lbcfapi.DriverAuthType
+
+	// value is the bearer token or the HMAC key
+	value []byte
+}
+
+// getDriverCredential returns nil if auth is not configured in driver spec
+func getDriverCredential(secretLister corev1.SecretLister, driver *lbcfapi.LoadBalancerDriver) (*driverCredential, error) {
+	spec := driver.Spec.Auth
+	if spec == nil {
+		return nil, nil
+	}
+	var key string
+	switch spec.Type {
+	case lbcfapi.BearerTokenAuth:
+		key = lbcfapi.AuthTokenKey
+	case lbcfapi.HMACAuth:
+		key = lbcfapi.AuthHMACKey
+	default:
+		return nil, fmt.Errorf("unknown auth type %q", spec.Type)
+	}
+	if secretLister == nil {
+		return nil, fmt.Errorf("auth is not supported, secret lister is not set")
+	}
+	secret, err := secretLister.Secrets(driver.Namespace).Get(spec.SecretName)
+	if err != nil {
+		return nil, fmt.Errorf("get auth secret %s/%s failed: %v", driver.Namespace, spec.SecretName, err)
+	}
+	value, ok := secret.Data[key]
+	if !ok || len(value) == 0 {
+		return nil, fmt.Errorf("key %q not found in auth secret %s/%s", key, driver.Namespace, spec.SecretName)
+	}
+	return &driverCredential{
+		authType: spec.Type,
+		value:    value,
+	}, nil
+}
+
+// gorequestBody returns the request body sent by gorequest.
+// gorequest decodes JSON objects into a map before sending them, so the body is encoded the same way
+// to make sure HMAC signature is computed over what is actually sent.
+func gorequestBody(payload interface{}) ([]byte, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&m); err != nil {
+		return nil, err
+	}
+	return json.Marshal(m)
+}
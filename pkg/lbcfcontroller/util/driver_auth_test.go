@@ -0,0 +1,91 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks/auth"
+
+	apicorev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestWebhookAuth(t *testing.T) {
+	succ := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"succ":true}`))
+	})
+	cases := []struct {
+		name    string
+		handler http.Handler
+		auth    lbcfapi.DriverAuthConfig
+		data    map[string][]byte
+	}{
+		{
+			name:    "bearer-token",
+			handler: auth.BearerTokenHandler("fake-token", succ),
+			auth:    lbcfapi.DriverAuthConfig{Type: lbcfapi.BearerTokenAuth, SecretName: "driver-auth"},
+			data:    map[string][]byte{lbcfapi.AuthTokenKey: []byte("fake-token")},
+		},
+		{
+			name:    "hmac",
+			handler: auth.HMACHandler([]byte("fake-key"), succ),
+			auth:    lbcfapi.DriverAuthConfig{Type: lbcfapi.HMACAuth, SecretName: "driver-auth"},
+			data:    map[string][]byte{lbcfapi.AuthHMACKey: []byte("fake-key")},
+		},
+	}
+	for _, c := range cases {
+		server := httptest.NewServer(c.handler)
+		u, _ := url.Parse(server.URL)
+		driver := fakeMockDriver(u, 10*time.Second)
+		driver.Namespace = "kube-system"
+
+		store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		store.Add(&apicorev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      c.auth.SecretName,
+				Namespace: driver.Namespace,
+			},
+			Data: c.data,
+		})
+		invoker := NewWebhookInvokerWithConfig(WebhookInvokerConfig{
+			SecretLister: corev1.NewSecretLister(store),
+		})
+
+		req := &webhooks.ValidateLoadBalancerRequest{
+			LBSpec: map[string]string{"b": "2", "a": "1"},
+		}
+		if _, err := invoker.CallValidateLoadBalancer(driver, req); err == nil {
+			t.Errorf("case %s, expect error without auth", c.name)
+		}
+		driver.Spec.Auth = &c.auth
+		if rsp, err := invoker.CallValidateLoadBalancer(driver, req); err != nil {
+			t.Errorf("case %s, expect no error, get %v", c.name, err)
+		} else if !rsp.Succ {
+			t.Errorf("case %s, expect succ", c.name)
+		}
+		server.Close()
+	}
+}
@@ -0,0 +1,193 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/metrics"
+)
+
+// CircuitState is the state of the circuit breaker of a driver
+type CircuitState string
+
+const (
+	// CircuitClosed means webhooks of the driver are called normally
+	CircuitClosed CircuitState = "Closed"
+
+	// CircuitOpen means webhooks of the driver are not called, a CircuitOpenError is returned instead
+	CircuitOpen CircuitState = "Open"
+
+	// CircuitHalfOpen means a single trial call is allowed to find out whether the driver has recovered
+	CircuitHalfOpen CircuitState = "HalfOpen"
+)
+
+// CircuitOpenError is returned by WebhookInvoker if the circuit breaker of the driver is open
+type CircuitOpenError struct {
+	Driver string
+	// RetryAfter is the duration after which the circuit breaker turns half-open
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker of driver %s is open, retry after %s", e.Driver, e.RetryAfter.String())
+}
+
+// IsCircuitOpenError returns true if err is a CircuitOpenError
+func IsCircuitOpenError(err error) bool {
+	_, ok := err.(*CircuitOpenError)
+	return ok
+}
+
+// unavailableError means the driver is not reachable or responds with a server error,
+// only such errors are counted by the circuit breaker
+type unavailableError struct {
+	error
+}
+
+func isUnavailableError(err error) bool {
+	_, ok := err.(*unavailableError)
+	return ok
+}
+
+// CircuitBreakerStatus is the state of the circuit breaker of a driver
+type CircuitBreakerStatus struct {
+	State CircuitState
+	// ConsecutiveFailures is the number of consecutive failed calls
+	ConsecutiveFailures int32
+	// LastError is the error of the last failed call
+	LastError string
+}
+
+// driverBreaker opens the circuit of a driver after failureThreshold consecutive failed calls,
+// and turns half-open after openDuration so that one call is allowed to try the driver again
+type driverBreaker struct {
+	failureThreshold int32
+	openDuration     time.Duration
+	// onStateChange is called with the driver key without holding lock
+	onStateChange func(driverKey string)
+
+	lock     sync.Mutex
+	circuits map[string]*circuit
+}
+
+type circuit struct {
+	CircuitBreakerStatus
+	openedAt time.Time
+	// probing is true if the trial call of half-open state is in flight
+	probing bool
+}
+
+func newDriverBreaker(failureThreshold int32, openDuration time.Duration, onStateChange func(string)) *driverBreaker {
+	return &driverBreaker{
+		failureThreshold: failureThreshold,
+		openDuration:     openDuration,
+		onStateChange:    onStateChange,
+		circuits:         make(map[string]*circuit),
+	}
+}
+
+// allow returns a CircuitOpenError if the call should not be made
+func (b *driverBreaker) allow(driver *lbcfapi.LoadBalancerDriver) error {
+	driverKey := driver.Namespace + "/" + driver.Name
+	changed, err := b.doAllow(driverKey)
+	if changed {
+		b.stateChanged(driverKey, CircuitHalfOpen)
+	}
+	return err
+}
+
+func (b *driverBreaker) doAllow(driverKey string) (bool, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	c, ok := b.circuits[driverKey]
+	if !ok {
+		return false, nil
+	}
+	switch c.State {
+	case CircuitOpen:
+		if elapsed := time.Since(c.openedAt); elapsed < b.openDuration {
+			return false, &CircuitOpenError{Driver: driverKey, RetryAfter: b.openDuration - elapsed}
+		}
+		c.State = CircuitHalfOpen
+		c.probing = true
+		return true, nil
+	case CircuitHalfOpen:
+		if c.probing {
+			return false, &CircuitOpenError{Driver: driverKey, RetryAfter: b.openDuration}
+		}
+		c.probing = true
+	}
+	return false, nil
+}
+
+// record updates the circuit with the result of a call allowed by allow
+func (b *driverBreaker) record(driver *lbcfapi.LoadBalancerDriver, err error) {
+	driverKey := driver.Namespace + "/" + driver.Name
+	if state, changed := b.doRecord(driverKey, err); changed {
+		b.stateChanged(driverKey, state)
+	}
+}
+
+func (b *driverBreaker) doRecord(driverKey string, err error) (CircuitState, bool) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	c, ok := b.circuits[driverKey]
+	if err == nil || !isUnavailableError(err) {
+		if !ok {
+			return CircuitClosed, false
+		}
+		delete(b.circuits, driverKey)
+		return CircuitClosed, c.State != CircuitClosed
+	}
+
+	if !ok {
+		c = &circuit{CircuitBreakerStatus: CircuitBreakerStatus{State: CircuitClosed}}
+		b.circuits[driverKey] = c
+	}
+	c.ConsecutiveFailures++
+	c.LastError = err.Error()
+	c.probing = false
+	if c.State == CircuitHalfOpen || c.ConsecutiveFailures >= b.failureThreshold {
+		changed := c.State != CircuitOpen
+		c.State = CircuitOpen
+		c.openedAt = time.Now()
+		return CircuitOpen, changed
+	}
+	return c.State, false
+}
+
+// status returns the state of the circuit of driver
+func (b *driverBreaker) status(driver *lbcfapi.LoadBalancerDriver) CircuitBreakerStatus {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if c, ok := b.circuits[driver.Namespace+"/"+driver.Name]; ok {
+		return c.CircuitBreakerStatus
+	}
+	return CircuitBreakerStatus{State: CircuitClosed}
+}
+
+func (b *driverBreaker) stateChanged(driverKey string, state CircuitState) {
+	metrics.SetCircuitBreakerState(driverKey, string(state))
+	if b.onStateChange != nil {
+		b.onStateChange(driverKey)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDriverBreaker(t *testing.T) {
+	driver := &lbcfapi.LoadBalancerDriver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "driver",
+			Namespace: "kube-system",
+		},
+	}
+	var changes []string
+	b := newDriverBreaker(2, 50*time.Millisecond, func(driverKey string) {
+		changes = append(changes, driverKey)
+	})
+	failure := &unavailableError{fmt.Errorf("fake error")}
+
+	// errors not caused by unavailable driver are ignored
+	for i := 0; i < 3; i++ {
+		if err := b.allow(driver); err != nil {
+			t.Fatalf("expect no error, get %v", err)
+		}
+		b.record(driver, fmt.Errorf("decode webhook response err"))
+	}
+	if err := b.allow(driver); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	b.record(driver, failure)
+	if status := b.status(driver); status.State != CircuitClosed || status.ConsecutiveFailures != 1 {
+		t.Fatalf("expect closed circuit with 1 failure, get %#v", status)
+	}
+	b.record(driver, failure)
+	if status := b.status(driver); status.State != CircuitOpen {
+		t.Fatalf("expect open circuit, get %#v", status)
+	}
+	if err := b.allow(driver); !IsCircuitOpenError(err) {
+		t.Fatalf("expect CircuitOpenError, get %v", err)
+	}
+
+	// only one trial call is allowed when half-open, the circuit opens again if it fails
+	time.Sleep(50 * time.Millisecond)
+	if err := b.allow(driver); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	if status := b.status(driver); status.State != CircuitHalfOpen {
+		t.Fatalf("expect half-open circuit, get %#v", status)
+	}
+	if err := b.allow(driver); !IsCircuitOpenError(err) {
+		t.Fatalf("expect CircuitOpenError, get %v", err)
+	}
+	b.record(driver, failure)
+	if status := b.status(driver); status.State != CircuitOpen {
+		t.Fatalf("expect open circuit, get %#v", status)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	if err := b.allow(driver); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	b.record(driver, nil)
+	if status := b.status(driver); status.State != CircuitClosed || status.ConsecutiveFailures != 0 {
+		t.Fatalf("expect closed circuit, get %#v", status)
+	}
+	// open, half-open, open, half-open, closed
+	if len(changes) != 5 {
+		t.Fatalf("expect 5 state changes, get %d", len(changes))
+	}
+}
+
+func TestWebhookCircuitBreaker(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	driver := fakeMockDriver(u, 10*time.Second)
+	driver.Namespace = "kube-system"
+
+	invoker := NewWebhookInvokerWithConfig(WebhookInvokerConfig{
+		CircuitBreakerFailureThreshold: 2,
+		CircuitBreakerOpenDuration:     time.Minute,
+	})
+	req := &webhooks.ValidateLoadBalancerRequest{}
+	for i := 0; i < 2; i++ {
+		if _, err := invoker.CallValidateLoadBalancer(driver, req); err == nil || IsCircuitOpenError(err) {
+			t.Fatalf("expect http error, get %v", err)
+		}
+	}
+	_, err := invoker.CallValidateLoadBalancer(driver, req)
+	if !IsCircuitOpenError(err) {
+		t.Fatalf("expect CircuitOpenError, get %v", err)
+	}
+	if calls != 2 {
+		t.Fatalf("expect 2 calls, get %d", calls)
+	}
+	if status := invoker.GetCircuitBreakerStatus(driver); status.State != CircuitOpen {
+		t.Fatalf("expect open circuit, get %#v", status)
+	}
+	if result := ErrorResult(err); !result.IsFailed() || result.GetNextRun() <= 0 {
+		t.Fatalf("expect failed result retried after the circuit breaker turns half-open, get %#v", result)
+	}
+}
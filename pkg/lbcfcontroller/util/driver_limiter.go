@@ -0,0 +1,165 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ThrottledError is returned by WebhookInvoker if calling the webhook exceeds limits declared in LoadBalancerDriverSpec
+type ThrottledError struct {
+	Driver  string
+	Webhook string
+	// RetryAfter is the suggested delay before calling the webhook again
+	RetryAfter time.Duration
+}
+
+func (e *ThrottledError) Error() string {
+	return fmt.Sprintf("calling webhook %s of driver %s is throttled, retry after %s", e.Webhook, e.Driver, e.RetryAfter.String())
+}
+
+// IsThrottledError returns true if err is a ThrottledError
+func IsThrottledError(err error) bool {
+	_, ok := err.(*ThrottledError)
+	return ok
+}
+
+// driverLimiter enforces maxConcurrentCalls and rateLimit declared in LoadBalancerDriverSpec
+type driverLimiter struct {
+	lock    sync.Mutex
+	drivers map[string]*driverLimitState
+}
+
+// driverLimitState is rebuilt if the driver is recreated or its maxConcurrentCalls is changed,
+// calls in flight are released to the state they were counted in
+type driverLimitState struct {
+	uid                types.UID
+	maxConcurrentCalls int32
+	inflight           int32
+	// limiters is keyed by webhook name
+	limiters map[string]*webhookRateLimiter
+}
+
+type webhookRateLimiter struct {
+	config  lbcfapi.RateLimitConfig
+	limiter *rate.Limiter
+}
+
+func newDriverLimiter() *driverLimiter {
+	return &driverLimiter{
+		drivers: make(map[string]*driverLimitState),
+	}
+}
+
+// acquire returns a ThrottledError if the call is not allowed, otherwise the returned function must be called after the call finished.
+// Calls of AdmissionWebhooks are always allowed and not counted
+func (l *driverLimiter) acquire(driver *lbcfapi.LoadBalancerDriver, webHookName string) (func(), error) {
+	if webhooks.AdmissionWebhooks.Has(webHookName) {
+		return func() {}, nil
+	}
+	driverKey := driver.Namespace + "/" + driver.Name
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	state := l.getState(driverKey, driver)
+	limitConcurrency := state.maxConcurrentCalls > 0
+	if limitConcurrency && state.inflight >= state.maxConcurrentCalls {
+		return nil, &ThrottledError{Driver: driverKey, Webhook: webHookName}
+	}
+
+	if limiter := state.getRateLimiter(driver, webHookName); limiter != nil {
+		r := limiter.Reserve()
+		if delay := r.Delay(); delay > 0 {
+			r.Cancel()
+			return nil, &ThrottledError{Driver: driverKey, Webhook: webHookName, RetryAfter: delay}
+		}
+	}
+
+	if !limitConcurrency {
+		return func() {}, nil
+	}
+	state.inflight++
+	return func() {
+		l.lock.Lock()
+		defer l.lock.Unlock()
+		state.inflight--
+	}, nil
+}
+
+// forget drops the state of the driver
+func (l *driverLimiter) forget(driverKey string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	delete(l.drivers, driverKey)
+}
+
+// getState returns the state of the driver, it is rebuilt if the driver is recreated or maxConcurrentCalls is changed.
+// l.lock must be held
+func (l *driverLimiter) getState(driverKey string, driver *lbcfapi.LoadBalancerDriver) *driverLimitState {
+	var maxConcurrentCalls int32
+	if driver.Spec.MaxConcurrentCalls != nil {
+		maxConcurrentCalls = *driver.Spec.MaxConcurrentCalls
+	}
+	if cur, ok := l.drivers[driverKey]; ok && cur.uid == driver.UID && cur.maxConcurrentCalls == maxConcurrentCalls {
+		return cur
+	}
+	state := &driverLimitState{
+		uid:                driver.UID,
+		maxConcurrentCalls: maxConcurrentCalls,
+		limiters:           make(map[string]*webhookRateLimiter),
+	}
+	l.drivers[driverKey] = state
+	return state
+}
+
+// getRateLimiter returns nil if rateLimit is not configured, the limiter is recreated if rateLimit is changed
+func (s *driverLimitState) getRateLimiter(driver *lbcfapi.LoadBalancerDriver, webHookName string) *rate.Limiter {
+	var config *lbcfapi.RateLimitConfig
+	for _, h := range driver.Spec.Webhooks {
+		if h.Name == webHookName {
+			config = h.RateLimit
+			break
+		}
+	}
+	if config == nil || config.QPS <= 0 {
+		delete(s.limiters, webHookName)
+		return nil
+	}
+	if cur, ok := s.limiters[webHookName]; ok && cur.config == *config {
+		return cur.limiter
+	}
+	burst := config.Burst
+	if burst <= 0 {
+		burst = config.QPS
+	}
+	limiter := rate.NewLimiter(rate.Limit(config.QPS), int(burst))
+	s.limiters[webHookName] = &webhookRateLimiter{
+		config:  *config,
+		limiter: limiter,
+	}
+	return limiter
+}
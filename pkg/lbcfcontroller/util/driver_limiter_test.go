@@ -0,0 +1,185 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"testing"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDriverLimiterMaxConcurrentCalls(t *testing.T) {
+	max := int32(2)
+	driver := &lbcfapi.LoadBalancerDriver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "driver",
+			Namespace: "kube-system",
+		},
+		Spec: lbcfapi.LoadBalancerDriverSpec{
+			MaxConcurrentCalls: &max,
+		},
+	}
+	l := newDriverLimiter()
+	release1, err := l.acquire(driver, webhooks.EnsureBackend)
+	if err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	if _, err := l.acquire(driver, webhooks.EnsureBackend); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	if _, err := l.acquire(driver, webhooks.EnsureBackend); !IsThrottledError(err) {
+		t.Fatalf("expect ThrottledError, get %v", err)
+	}
+	release1()
+	if _, err := l.acquire(driver, webhooks.EnsureBackend); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+}
+
+func TestDriverLimiterForgetAndRebuild(t *testing.T) {
+	max := int32(1)
+	driver := &lbcfapi.LoadBalancerDriver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "driver",
+			Namespace: "kube-system",
+			UID:       "uid-1",
+		},
+		Spec: lbcfapi.LoadBalancerDriverSpec{
+			MaxConcurrentCalls: &max,
+		},
+	}
+	driverKey := driver.Namespace + "/" + driver.Name
+	l := newDriverLimiter()
+	releaseOld, err := l.acquire(driver, webhooks.EnsureBackend)
+	if err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+
+	l.forget(driverKey)
+	if len(l.drivers) != 0 {
+		t.Fatalf("expect no driver states, get %d", len(l.drivers))
+	}
+
+	// the driver is recreated, calls to the deleted driver are not counted
+	driver.UID = "uid-2"
+	if _, err := l.acquire(driver, webhooks.EnsureBackend); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	releaseOld()
+	if _, err := l.acquire(driver, webhooks.EnsureBackend); !IsThrottledError(err) {
+		t.Fatalf("expect ThrottledError, get %v", err)
+	}
+
+	// the state is rebuilt with the new maxConcurrentCalls
+	newMax := int32(2)
+	driver.Spec.MaxConcurrentCalls = &newMax
+	for i := 0; i < 2; i++ {
+		if _, err := l.acquire(driver, webhooks.EnsureBackend); err != nil {
+			t.Fatalf("call %d, expect no error, get %v", i, err)
+		}
+	}
+	if _, err := l.acquire(driver, webhooks.EnsureBackend); !IsThrottledError(err) {
+		t.Fatalf("expect ThrottledError, get %v", err)
+	}
+}
+
+func TestDriverLimiterRateLimit(t *testing.T) {
+	driver := &lbcfapi.LoadBalancerDriver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "driver",
+			Namespace: "kube-system",
+		},
+		Spec: lbcfapi.LoadBalancerDriverSpec{
+			Webhooks: []lbcfapi.WebhookConfig{
+				{
+					Name: webhooks.EnsureBackend,
+					RateLimit: &lbcfapi.RateLimitConfig{
+						QPS:   1,
+						Burst: 1,
+					},
+				},
+			},
+		},
+	}
+	l := newDriverLimiter()
+	if _, err := l.acquire(driver, webhooks.EnsureBackend); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	_, err := l.acquire(driver, webhooks.EnsureBackend)
+	if !IsThrottledError(err) {
+		t.Fatalf("expect ThrottledError, get %v", err)
+	} else if err.(*ThrottledError).RetryAfter <= 0 {
+		t.Fatalf("expect positive RetryAfter, get %v", err.(*ThrottledError).RetryAfter)
+	}
+
+	// webhooks without rateLimit are not limited
+	for i := 0; i < 5; i++ {
+		if _, err := l.acquire(driver, webhooks.DeregBackend); err != nil {
+			t.Fatalf("expect no error, get %v", err)
+		}
+	}
+}
+
+func TestDriverLimiterAdmissionWebhooks(t *testing.T) {
+	max := int32(1)
+	rateLimit := &lbcfapi.RateLimitConfig{QPS: 1, Burst: 1}
+	driver := &lbcfapi.LoadBalancerDriver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "driver",
+			Namespace: "kube-system",
+		},
+		Spec: lbcfapi.LoadBalancerDriverSpec{
+			MaxConcurrentCalls: &max,
+			Webhooks: []lbcfapi.WebhookConfig{
+				{Name: webhooks.ValidateLoadBalancer, RateLimit: rateLimit},
+				{Name: webhooks.ValidateBackend, RateLimit: rateLimit},
+			},
+		},
+	}
+	l := newDriverLimiter()
+	// admission calls are neither limited nor counted
+	for i := 0; i < 3; i++ {
+		for _, webhook := range []string{webhooks.ValidateLoadBalancer, webhooks.ValidateBackend} {
+			if _, err := l.acquire(driver, webhook); err != nil {
+				t.Fatalf("call %d of %s, expect no error, get %v", i, webhook, err)
+			}
+		}
+	}
+	if _, err := l.acquire(driver, webhooks.EnsureBackend); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	if _, err := l.acquire(driver, webhooks.EnsureBackend); !IsThrottledError(err) {
+		t.Fatalf("expect ThrottledError, get %v", err)
+	}
+	if _, err := l.acquire(driver, webhooks.ValidateBackend); err != nil {
+		t.Fatalf("expect no error while maxConcurrentCalls is reached, get %v", err)
+	}
+}
+
+func TestErrorResultOfThrottledError(t *testing.T) {
+	result := ErrorResult(&ThrottledError{RetryAfter: 1})
+	if !result.IsThrottled() || result.IsFailed() || result.IsFinished() {
+		t.Fatalf("expect throttled result")
+	}
+	if result := ErrorResult(fmt.Errorf("fake error")); result.IsThrottled() || !result.IsFailed() {
+		t.Fatalf("expect failed result")
+	}
+}
@@ -0,0 +1,128 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks/auth"
+
+	"github.com/parnurzeal/gorequest"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+)
+
+// CallHealthCheck probes the health endpoint of driver, limits declared in driver spec are not applied to health checks
+func (w *WebhookInvokerImpl) CallHealthCheck(driver *lbcfapi.LoadBalancerDriver) error {
+	callCfg, err := w.getCallConfig(driver)
+	if err != nil {
+		return err
+	}
+	var cfg lbcfapi.DriverHealthCheckConfig
+	if driver.Spec.HealthCheck != nil {
+		cfg = *driver.Spec.HealthCheck
+	}
+	timeout := GetDuration(cfg.Timeout, DefaultHealthCheckTimeout)
+
+	if lbcfapi.DriverType(driver.Spec.DriverType) == lbcfapi.ExecDriver {
+		return probeExec(driver, callCfg.execDriverDir)
+	}
+	if lbcfapi.DriverType(driver.Spec.DriverType) == lbcfapi.GRPCDriver {
+		targets := []string{driver.Spec.Url}
+		if driver.Spec.Service != nil {
+			targets = callCfg.serviceAddrs
+			grpcConns.evictServiceTargets(driver.Namespace+"/"+driver.Name, callCfg.allServiceAddrs)
+		}
+		for i, target := range targets {
+			err = probeGRPC(target, callCfg, timeout)
+			if err == nil || i == len(targets)-1 {
+				break
+			}
+		}
+		return err
+	}
+
+	healthPath := cfg.Path
+	if healthPath == "" {
+		healthPath = DefaultHealthCheckPath
+	}
+	urls, err := getWebhookURLs(driver, callCfg, healthPath)
+	if err != nil {
+		return fmt.Errorf("invalid url: %v", err)
+	}
+	for i, u := range urls {
+		err = probeHTTP(u, callCfg, timeout)
+		if err == nil || i == len(urls)-1 {
+			break
+		}
+	}
+	return err
+}
+
+func probeHTTP(u *url.URL, callCfg driverCallConfig, timeout time.Duration) error {
+	request := gorequest.New().Timeout(timeout)
+	if callCfg.tls != nil {
+		request = request.TLSClientConfig(callCfg.tls.config)
+	}
+	request = request.Get(u.String())
+	if callCfg.credential != nil {
+		switch callCfg.credential.authType {
+		case lbcfapi.BearerTokenAuth:
+			request = request.Set(auth.AuthorizationHeader, auth.BearerToken(string(callCfg.credential.value)))
+		case lbcfapi.HMACAuth:
+			timestamp := time.Now().Unix()
+			request = request.Set(auth.TimestampHeader, strconv.FormatInt(timestamp, 10)).
+				Set(auth.SignatureHeader, auth.Sign(callCfg.credential.value, timestamp, http.MethodGet, u.Path, nil))
+		}
+	}
+	response, body, errs := request.EndBytes()
+	if len(errs) > 0 {
+		return fmt.Errorf("health check err: %v", errs)
+	}
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("health check http status code: %d, body: %s", response.StatusCode, body)
+	}
+	return nil
+}
+
+func probeGRPC(target string, callCfg driverCallConfig, timeout time.Duration) error {
+	conn, err := grpcConns.get(target, callCfg.tls)
+	if err != nil {
+		return fmt.Errorf("dial %s err: %v", target, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if callCfg.credential != nil && callCfg.credential.authType == lbcfapi.BearerTokenAuth {
+		ctx = metadata.AppendToOutgoingContext(ctx, strings.ToLower(auth.AuthorizationHeader), auth.BearerToken(string(callCfg.credential.value)))
+	}
+	rsp, err := grpc_health_v1.NewHealthClient(conn).Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return fmt.Errorf("health check err: %v", err)
+	}
+	if rsp.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return fmt.Errorf("health check status: %s", rsp.Status.String())
+	}
+	return nil
+}
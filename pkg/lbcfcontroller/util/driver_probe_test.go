@@ -0,0 +1,63 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+)
+
+func TestCallHealthCheck(t *testing.T) {
+	healthy := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/ping" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if !healthy {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	driver := fakeMockDriver(u, 10*time.Second)
+	driver.Namespace = "kube-system"
+	driver.Spec.HealthCheck = &lbcfapi.DriverHealthCheckConfig{
+		Path: "/ping",
+	}
+
+	invoker := NewWebhookInvoker()
+	if err := invoker.CallHealthCheck(driver); err != nil {
+		t.Fatalf("expect healthy, get %v", err)
+	}
+	healthy = false
+	if err := invoker.CallHealthCheck(driver); err == nil {
+		t.Fatalf("expect unhealthy")
+	}
+	driver.Spec.HealthCheck.Path = ""
+	healthy = true
+	if err := invoker.CallHealthCheck(driver); err == nil {
+		t.Fatalf("expect error when path %s is not served", DefaultHealthCheckPath)
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+
+	apicorev1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/client-go/listers/core/v1"
+)
+
+// maxServiceEndpointAttempts is the maximum number of endpoints tried in a single webhook call
+const maxServiceEndpointAttempts = 3
+
+// resolveServiceAddrs returns all addresses in the form of host:port of the Service referenced by the driver.
+// Ready endpoints are returned in random order so that calls are spread among them,
+// and the ClusterIP is returned if there is no ready endpoint or endpointsLister is nil.
+func resolveServiceAddrs(svcLister corev1.ServiceLister, endpointsLister corev1.EndpointsLister, ref *lbcfapi.DriverServiceReference) ([]string, error) {
+	if svcLister == nil {
+		return nil, fmt.Errorf("service reference is not supported, service lister is not set")
+	}
+	svc, err := svcLister.Services(ref.Namespace).Get(ref.Name)
+	if err != nil {
+		return nil, fmt.Errorf("get service %s/%s failed: %v", ref.Namespace, ref.Name, err)
+	}
+	var svcPort *apicorev1.ServicePort
+	for i := range svc.Spec.Ports {
+		if svc.Spec.Ports[i].Port == ref.Port {
+			svcPort = &svc.Spec.Ports[i]
+			break
+		}
+	}
+	if svcPort == nil {
+		return nil, fmt.Errorf("port %d not found in service %s/%s", ref.Port, ref.Namespace, ref.Name)
+	}
+
+	var addrs []string
+	if endpointsLister != nil {
+		if ep, err := endpointsLister.Endpoints(ref.Namespace).Get(ref.Name); err == nil {
+			for _, subset := range ep.Subsets {
+				for _, port := range subset.Ports {
+					if port.Name != svcPort.Name {
+						continue
+					}
+					for _, addr := range subset.Addresses {
+						addrs = append(addrs, net.JoinHostPort(addr.IP, strconv.Itoa(int(port.Port))))
+					}
+				}
+			}
+		}
+	}
+	if len(addrs) > 0 {
+		rand.Shuffle(len(addrs), func(i, j int) {
+			addrs[i], addrs[j] = addrs[j], addrs[i]
+		})
+		return addrs, nil
+	}
+
+	if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == apicorev1.ClusterIPNone {
+		return nil, fmt.Errorf("no ready endpoint found for service %s/%s", ref.Namespace, ref.Name)
+	}
+	return []string{net.JoinHostPort(svc.Spec.ClusterIP, strconv.Itoa(int(ref.Port)))}, nil
+}
@@ -0,0 +1,215 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+
+	apicorev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestResolveServiceAddrs(t *testing.T) {
+	svcStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	epStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	svcStore.Add(fakeDriverService("10.0.0.1"))
+	ref := &lbcfapi.DriverServiceReference{
+		Namespace: "kube-system",
+		Name:      "driver",
+		Port:      80,
+	}
+
+	addrs, err := resolveServiceAddrs(corev1.NewServiceLister(svcStore), corev1.NewEndpointsLister(epStore), ref)
+	if err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	} else if len(addrs) != 1 || addrs[0] != "10.0.0.1:80" {
+		t.Fatalf("expect ClusterIP, get %v", addrs)
+	}
+
+	epStore.Add(fakeDriverEndpoints(8080, "192.168.0.1", "192.168.0.2"))
+	addrs, err = resolveServiceAddrs(corev1.NewServiceLister(svcStore), corev1.NewEndpointsLister(epStore), ref)
+	if err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	} else if len(addrs) != 2 {
+		t.Fatalf("expect 2 endpoints, get %v", addrs)
+	}
+	for _, addr := range addrs {
+		if addr != "192.168.0.1:8080" && addr != "192.168.0.2:8080" {
+			t.Fatalf("unexpected addr %s", addr)
+		}
+	}
+
+	ref.Port = 443
+	if _, err := resolveServiceAddrs(corev1.NewServiceLister(svcStore), corev1.NewEndpointsLister(epStore), ref); err == nil {
+		t.Fatalf("expect error for unknown port")
+	}
+}
+
+func TestWebhookServiceFailover(t *testing.T) {
+	var calledPath string
+	live := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calledPath = r.URL.Path
+		w.Write([]byte(`{"succ":true}`))
+	}))
+	defer live.Close()
+	dead := httptest.NewServer(http.NotFoundHandler())
+	dead.Close()
+
+	liveURL, _ := url.Parse(live.URL)
+	deadURL, _ := url.Parse(dead.URL)
+	liveHost, livePort, _ := net.SplitHostPort(liveURL.Host)
+	deadHost, deadPort, _ := net.SplitHostPort(deadURL.Host)
+	svcStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	epStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	svcStore.Add(fakeDriverService("10.0.0.1"))
+	ep := &apicorev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "driver",
+			Namespace: "kube-system",
+		},
+	}
+	for _, addr := range [][2]string{{liveHost, livePort}, {deadHost, deadPort}} {
+		port, _ := strconv.Atoi(addr[1])
+		ep.Subsets = append(ep.Subsets, fakeDriverEndpoints(int32(port), addr[0]).Subsets...)
+	}
+	epStore.Add(ep)
+
+	invoker := NewWebhookInvokerWithConfig(WebhookInvokerConfig{
+		ServiceLister:   corev1.NewServiceLister(svcStore),
+		EndpointsLister: corev1.NewEndpointsLister(epStore),
+	})
+	driver := fakeMockDriver(&url.URL{}, 10*time.Second)
+	driver.Namespace = "kube-system"
+	driver.Spec.Url = ""
+	driver.Spec.Service = &lbcfapi.DriverServiceReference{
+		Namespace: "kube-system",
+		Name:      "driver",
+		Port:      80,
+		Path:      "/lbcf",
+	}
+	// endpoints are shuffled, so the dead endpoint is tried first in some of the calls
+	for i := 0; i < 5; i++ {
+		rsp, err := invoker.CallValidateLoadBalancer(driver, &webhooks.ValidateLoadBalancerRequest{})
+		if err != nil {
+			t.Fatalf("expect no error, get %v", err)
+		} else if !rsp.Succ {
+			t.Fatalf("expect succ")
+		}
+		if calledPath != "/lbcf/"+webhooks.ValidateLoadBalancer {
+			t.Fatalf("expect path prefixed, get %s", calledPath)
+		}
+	}
+}
+
+func TestWebhookServiceNoFailoverOnTimeout(t *testing.T) {
+	var calls int32
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.Sleep(200 * time.Millisecond)
+		w.Write([]byte(`{"succ":true}`))
+	})
+	svcStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	epStore := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	svcStore.Add(fakeDriverService("10.0.0.1"))
+	ep := &apicorev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "driver",
+			Namespace: "kube-system",
+		},
+	}
+	for i := 0; i < 2; i++ {
+		server := httptest.NewServer(slow)
+		defer server.Close()
+		u, _ := url.Parse(server.URL)
+		host, portStr, _ := net.SplitHostPort(u.Host)
+		port, _ := strconv.Atoi(portStr)
+		ep.Subsets = append(ep.Subsets, fakeDriverEndpoints(int32(port), host).Subsets...)
+	}
+	epStore.Add(ep)
+
+	invoker := NewWebhookInvokerWithConfig(WebhookInvokerConfig{
+		ServiceLister:   corev1.NewServiceLister(svcStore),
+		EndpointsLister: corev1.NewEndpointsLister(epStore),
+	})
+	driver := fakeMockDriver(&url.URL{}, 50*time.Millisecond)
+	driver.Namespace = "kube-system"
+	driver.Spec.Url = ""
+	driver.Spec.Service = &lbcfapi.DriverServiceReference{
+		Namespace: "kube-system",
+		Name:      "driver",
+		Port:      80,
+	}
+	if _, err := invoker.CallValidateLoadBalancer(driver, &webhooks.ValidateLoadBalancerRequest{}); err == nil {
+		t.Fatalf("expect timeout error")
+	}
+	if get := atomic.LoadInt32(&calls); get != 1 {
+		t.Fatalf("expect the timed out call not retried on another endpoint, get %d calls", get)
+	}
+}
+
+func fakeDriverService(clusterIP string) *apicorev1.Service {
+	return &apicorev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "driver",
+			Namespace: "kube-system",
+		},
+		Spec: apicorev1.ServiceSpec{
+			ClusterIP: clusterIP,
+			Ports: []apicorev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       80,
+					TargetPort: intstr.FromInt(8080),
+				},
+			},
+		},
+	}
+}
+
+func fakeDriverEndpoints(port int32, ips ...string) *apicorev1.Endpoints {
+	subset := apicorev1.EndpointSubset{
+		Ports: []apicorev1.EndpointPort{
+			{
+				Name: "http",
+				Port: port,
+			},
+		},
+	}
+	for _, ip := range ips {
+		subset.Addresses = append(subset.Addresses, apicorev1.EndpointAddress{IP: ip})
+	}
+	return &apicorev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "driver",
+			Namespace: "kube-system",
+		},
+		Subsets: []apicorev1.EndpointSubset{subset},
+	}
+}
@@ -0,0 +1,206 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package util
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+
+	"k8s.io/klog"
+)
+
+// DefaultMaxExecOutputBytes is the default limit of stdout and stderr of Exec drivers
+const DefaultMaxExecOutputBytes = 1 << 20
+
+// execEnv is the environment of Exec drivers, the environment of lbcf-controller is not inherited
+var execEnv = []string{"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"}
+
+// ResolveExecCommand returns the real path of command after following symlinks,
+// an error is returned if Exec drivers are disabled, or the real path is not inside dir
+func ResolveExecCommand(dir string, command string) (string, error) {
+	if dir == "" {
+		return "", fmt.Errorf("exec drivers are disabled, --exec-driver-dir is not set")
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("invalid exec driver dir %s: %v", dir, err)
+	}
+	realDir, err := filepath.EvalSymlinks(absDir)
+	if err != nil {
+		return "", fmt.Errorf("invalid exec driver dir %s: %v", dir, err)
+	}
+	realCommand, err := filepath.EvalSymlinks(command)
+	if err != nil {
+		return "", fmt.Errorf("invalid command %s: %v", command, err)
+	}
+	rel, err := filepath.Rel(realDir, realCommand)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("command %s is not inside exec driver dir %s", command, dir)
+	}
+	return realCommand, nil
+}
+
+// callExecWebhook runs the executable of driver with the webhook name as the last argument,
+// the request is written to stdin and the response is read from stdout
+func callExecWebhook(driver *lbcfapi.LoadBalancerDriver, callCfg driverCallConfig, webHookName string, payload interface{}, rsp interface{}) error {
+	if driver.Spec.Exec == nil {
+		e := fmt.Errorf("exec is not specified")
+		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", e, driver.Name, webHookName)
+		return e
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		e := fmt.Errorf("encode webhook request err: %v", err)
+		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", e, driver.Name, webHookName)
+		return e
+	}
+
+	command, err := ResolveExecCommand(callCfg.execDriverDir, driver.Spec.Exec.Command)
+	if err != nil {
+		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", err, driver.Name, webHookName)
+		return err
+	}
+
+	timeout := getWebhookTimeout(driver, webHookName)
+	args := append(append([]string{}, driver.Spec.Exec.Args...), webHookName)
+	cmd := exec.Command(command, args...)
+	cmd.Env = execEnv
+	setProcessGroup(cmd)
+	cmd.Stdin = bytes.NewReader(body)
+	stdout := &limitedBuffer{limit: callCfg.maxExecOutputBytes}
+	stderr := &limitedBuffer{limit: callCfg.maxExecOutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	klog.V(3).Infof("callwebhook, exec %s %s, stdin: %s", driver.Spec.Exec.Command, strings.Join(args, " "), body)
+
+	timedOut, err := runCommand(cmd, timeout)
+	if stderr.buf.Len() > 0 && callCfg.onExecStderr != nil {
+		callCfg.onExecStderr(webHookName, stderr.String())
+	}
+	if timedOut {
+		e := fmt.Errorf("exec timeout after %s", timeout.String())
+		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", e, driver.Name, webHookName)
+		return &unavailableError{e}
+	}
+	if err != nil {
+		exitErr, ok := err.(*exec.ExitError)
+		if !ok {
+			// the executable can not be started
+			e := fmt.Errorf("exec err: %v", err)
+			klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", e, driver.Name, webHookName)
+			return &unavailableError{e}
+		}
+		e := fmt.Errorf("exec err: %v, stderr: %s", err, stderr.String())
+		if exitErr.ExitCode() == webhooks.ExecExitCodeNotImplemented {
+			// logged by the caller, because it is not an error if the webhook is optional
+			return &notImplementedError{e}
+		}
+		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", e, driver.Name, webHookName)
+		return e
+	}
+	if stdout.truncated {
+		e := fmt.Errorf("stdout exceeds %d bytes", stdout.limit)
+		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", e, driver.Name, webHookName)
+		return e
+	}
+	if err := webhooks.DecodeResponse(driver.Spec.ProtocolVersion, webHookName, stdout.buf.Bytes(), rsp); err != nil {
+		e := fmt.Errorf("decode webhook response err: %v, raw: %s", err, stdout.buf.String())
+		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", e, driver.Name, webHookName)
+		return e
+	}
+	return nil
+}
+
+// runCommand runs cmd and kills its process group if it does not exit within timeout,
+// so that children forked by the executable can not keep stdout open after the timeout
+func runCommand(cmd *exec.Cmd, timeout time.Duration) (timedOut bool, err error) {
+	if err := cmd.Start(); err != nil {
+		return false, err
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- cmd.Wait()
+	}()
+	if timeout <= 0 {
+		return false, <-done
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return false, err
+	case <-timer.C:
+		killProcessGroup(cmd)
+		<-done
+		return true, nil
+	}
+}
+
+// probeExec checks that the executable of driver is inside execDriverDir and is executable
+func probeExec(driver *lbcfapi.LoadBalancerDriver, execDriverDir string) error {
+	if driver.Spec.Exec == nil {
+		return fmt.Errorf("exec is not specified")
+	}
+	command, err := ResolveExecCommand(execDriverDir, driver.Spec.Exec.Command)
+	if err != nil {
+		return fmt.Errorf("health check err: %v", err)
+	}
+	info, err := os.Stat(command)
+	if err != nil {
+		return fmt.Errorf("health check err: %v", err)
+	}
+	if info.IsDir() || info.Mode()&0111 == 0 {
+		return fmt.Errorf("health check err: %s is not executable", driver.Spec.Exec.Command)
+	}
+	return nil
+}
+
+// limitedBuffer keeps at most limit bytes, the rest are discarded instead of failing the write,
+// so that the executable is not killed by a broken pipe
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); len(p) > room {
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		b.truncated = true
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *limitedBuffer) String() string {
+	if b.truncated {
+		return b.buf.String() + "...(truncated)"
+	}
+	return b.buf.String()
+}
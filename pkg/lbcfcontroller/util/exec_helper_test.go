@@ -0,0 +1,206 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newExecDriver(t *testing.T, dir string, script string, timeout time.Duration) *lbcfapi.LoadBalancerDriver {
+	command := filepath.Join(dir, "driver.sh")
+	if err := ioutil.WriteFile(command, []byte("#!/bin/sh\n"+script), 0755); err != nil {
+		t.Fatalf("write script failed: %v", err)
+	}
+	driver := &lbcfapi.LoadBalancerDriver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "exec-driver",
+			Namespace: "kube-system",
+		},
+		Spec: lbcfapi.LoadBalancerDriverSpec{
+			DriverType: string(lbcfapi.ExecDriver),
+			Exec: &lbcfapi.DriverExecConfig{
+				Command: command,
+				Args:    []string{"--fake"},
+			},
+		},
+	}
+	for known := range webhooks.KnownWebhooks {
+		driver.Spec.Webhooks = append(driver.Spec.Webhooks, lbcfapi.WebhookConfig{
+			Name:     known,
+			Timeout:  lbcfapi.Duration{Duration: timeout},
+			Optional: webhooks.OptionalWebhooks.Has(known),
+		})
+	}
+	return driver
+}
+
+func TestExecWebhook(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lbcf-exec")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var stderrs []string
+	invoker := NewWebhookInvokerWithConfig(WebhookInvokerConfig{
+		OnExecStderr: func(driver *lbcfapi.LoadBalancerDriver, webHookName string, stderr string) {
+			stderrs = append(stderrs, webHookName+": "+stderr)
+		},
+		ExecDriverDir: dir,
+	})
+
+	// the request is read from stdin, and args are followed by the webhook name
+	driver := newExecDriver(t, dir, `
+read req
+echo "debug info" >&2
+echo "{\"status\":\"Succ\",\"msg\":\"$1 $2\",\"backendAddr\":\"$(echo $req | grep -o 10.0.0.1)\"}"
+`, 5*time.Second)
+	rsp, err := invoker.CallGenerateBackendAddr(driver, &webhooks.GenerateBackendAddrRequest{
+		RequestForRetryHooks: webhooks.RequestForRetryHooks{RecordID: "10.0.0.1"},
+	})
+	if err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	if rsp.Status != webhooks.StatusSucc || rsp.BackendAddr != "10.0.0.1" {
+		t.Fatalf("expect status %s with backendAddr 10.0.0.1, get %+v", webhooks.StatusSucc, rsp)
+	}
+	if rsp.Msg != "--fake "+webhooks.GenerateBackendAddr {
+		t.Fatalf("expect args --fake %s, get %s", webhooks.GenerateBackendAddr, rsp.Msg)
+	}
+	if len(stderrs) != 1 || stderrs[0] != webhooks.GenerateBackendAddr+": debug info\n" {
+		t.Fatalf("expect stderr captured, get %v", stderrs)
+	}
+
+	// optional webhooks are treated as success if the executable exits with ExecExitCodeNotImplemented
+	driver = newExecDriver(t, dir, "exit 3\n", 5*time.Second)
+	if rsp, err := invoker.CallEnsureLoadBalancer(driver, &webhooks.EnsureLoadBalancerRequest{}); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	} else if rsp.Status != webhooks.StatusSucc {
+		t.Fatalf("expect status %s, get %s", webhooks.StatusSucc, rsp.Status)
+	}
+	if _, err := invoker.CallEnsureBackend(driver, &webhooks.BackendOperationRequest{}); err == nil {
+		t.Fatalf("expect error")
+	}
+
+	driver = newExecDriver(t, dir, "echo failed >&2\nexit 1\n", 5*time.Second)
+	if _, err := invoker.CallEnsureBackend(driver, &webhooks.BackendOperationRequest{}); err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Fatalf("expect error with stderr, get %v", err)
+	}
+
+	driver = newExecDriver(t, dir, "exec sleep 5\n", 100*time.Millisecond)
+	if _, err := invoker.CallEnsureBackend(driver, &webhooks.BackendOperationRequest{}); !isUnavailableError(err) {
+		t.Fatalf("expect timeout, get %v", err)
+	}
+
+	// children forked by the executable hold stdout, they are killed together with the executable
+	driver = newExecDriver(t, dir, "sleep 5 &\nsleep 5\n", 100*time.Millisecond)
+	start := time.Now()
+	if _, err := invoker.CallEnsureBackend(driver, &webhooks.BackendOperationRequest{}); !isUnavailableError(err) {
+		t.Fatalf("expect timeout, get %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expect the process group killed on timeout, the call took %s", elapsed)
+	}
+
+	// the environment of lbcf-controller is not inherited
+	os.Setenv("LBCF_EXEC_TEST_SECRET", "secret")
+	defer os.Unsetenv("LBCF_EXEC_TEST_SECRET")
+	driver = newExecDriver(t, dir, `echo "{\"status\":\"Succ\",\"msg\":\"$LBCF_EXEC_TEST_SECRET\"}"`, 5*time.Second)
+	if rsp, err := invoker.CallEnsureBackend(driver, &webhooks.BackendOperationRequest{}); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	} else if rsp.Msg != "" {
+		t.Fatalf("expect environment not inherited, get %s", rsp.Msg)
+	}
+}
+
+func TestResolveExecCommand(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lbcf-exec")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	driverDir := filepath.Join(dir, "drivers")
+	if err := os.Mkdir(driverDir, 0755); err != nil {
+		t.Fatalf("create driver dir failed: %v", err)
+	}
+	inside := filepath.Join(driverDir, "driver")
+	outside := filepath.Join(dir, "sh")
+	for _, f := range []string{inside, outside} {
+		if err := ioutil.WriteFile(f, []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatalf("write file failed: %v", err)
+		}
+	}
+	if err := os.Symlink(outside, filepath.Join(driverDir, "out")); err != nil {
+		t.Fatalf("create symlink failed: %v", err)
+	}
+	if err := os.Symlink(inside, filepath.Join(dir, "in")); err != nil {
+		t.Fatalf("create symlink failed: %v", err)
+	}
+
+	cases := []struct {
+		name     string
+		dir      string
+		command  string
+		expectOK bool
+	}{
+		{"inside", driverDir, inside, true},
+		{"symlink-from-outside-to-inside", driverDir, filepath.Join(dir, "in"), true},
+		{"disabled", "", inside, false},
+		{"outside", driverDir, outside, false},
+		{"symlink-to-outside", driverDir, filepath.Join(driverDir, "out"), false},
+		{"dot-dot", driverDir, filepath.Join(driverDir, "..", "sh"), false},
+		{"dir-itself", driverDir, driverDir, false},
+		{"not-exist", driverDir, filepath.Join(driverDir, "not-exist"), false},
+	}
+	for _, c := range cases {
+		if _, err := ResolveExecCommand(c.dir, c.command); (err == nil) != c.expectOK {
+			t.Errorf("case %s: expect ok %v, get err %v", c.name, c.expectOK, err)
+		}
+	}
+}
+
+func TestExecWebhookOutputLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lbcf-exec")
+	if err != nil {
+		t.Fatalf("create temp dir failed: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	invoker := NewWebhookInvokerWithConfig(WebhookInvokerConfig{MaxExecOutputBytes: 16, ExecDriverDir: dir})
+	driver := newExecDriver(t, dir, `echo '{"status":"Succ","msg":"a message longer than the limit"}'`, 5*time.Second)
+	if _, err := invoker.CallEnsureBackend(driver, &webhooks.BackendOperationRequest{}); err == nil || !strings.Contains(err.Error(), "exceeds") {
+		t.Fatalf("expect output limit error, get %v", err)
+	}
+
+	if err := invoker.CallHealthCheck(driver); err != nil {
+		t.Fatalf("expect healthy, get %v", err)
+	}
+	driver.Spec.Exec.Command = filepath.Join(dir, "not-exist")
+	if err := invoker.CallHealthCheck(driver); err == nil {
+		t.Fatalf("expect unhealthy")
+	}
+}
@@ -0,0 +1,253 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks/auth"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks/driverpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/klog"
+)
+
+// grpcConns caches connections to drivers whose driverType is GRPC, connections are shared by all WebhookInvokers
+var grpcConns = newGRPCConnCache()
+
+// grpcConnCloseGracePeriod is longer than the max timeout of webhooks,
+// so that calls in flight on a replaced connection are not interrupted
+var grpcConnCloseGracePeriod = 2 * time.Minute
+
+type grpcConnCache struct {
+	lock  sync.Mutex
+	conns map[grpcConnKey]*grpc.ClientConn
+
+	// serviceTargets are addresses of Services referenced by drivers, keyed by driver
+	serviceTargets map[string]sets.String
+}
+
+func newGRPCConnCache() *grpcConnCache {
+	return &grpcConnCache{
+		conns:          make(map[grpcConnKey]*grpc.ClientConn),
+		serviceTargets: make(map[string]sets.String),
+	}
+}
+
+// grpcConnKey identifies a connection, connections to the same target with different TLS configurations are not shared
+type grpcConnKey struct {
+	target string
+
+	// tlsFingerprint is empty if the connection is insecure
+	tlsFingerprint string
+}
+
+// get returns the connection to target, a new connection is created if not exist or the TLS configuration is changed.
+// Connections to target with the previous TLS configuration are closed after grpcConnCloseGracePeriod
+func (c *grpcConnCache) get(target string, tlsConfig *driverTLSConfig) (*grpc.ClientConn, error) {
+	key := grpcConnKey{target: target}
+	dialOpt := grpc.WithInsecure()
+	if tlsConfig != nil {
+		key.tlsFingerprint = tlsConfig.fingerprint
+		dialOpt = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig.config))
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if conn, ok := c.conns[key]; ok {
+		return conn, nil
+	}
+	conn, err := grpc.Dial(target, dialOpt)
+	if err != nil {
+		return nil, err
+	}
+	for k, old := range c.conns {
+		if k.target == target {
+			delete(c.conns, k)
+			closeGRPCConnLater(old)
+		}
+	}
+	c.conns[key] = conn
+	return conn, nil
+}
+
+// evictServiceTargets records targets as the addresses of the Service referenced by the driver,
+// connections to previous addresses of the Service, which are not used by other drivers, are closed after grpcConnCloseGracePeriod
+func (c *grpcConnCache) evictServiceTargets(driverKey string, targets []string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	previous := c.serviceTargets[driverKey]
+	current := sets.NewString(targets...)
+	c.serviceTargets[driverKey] = current
+	stale := previous.Difference(current)
+	for key, others := range c.serviceTargets {
+		if key != driverKey {
+			stale = stale.Difference(others)
+		}
+	}
+	if stale.Len() == 0 {
+		return
+	}
+	for k, conn := range c.conns {
+		if stale.Has(k.target) {
+			delete(c.conns, k)
+			closeGRPCConnLater(conn)
+		}
+	}
+}
+
+func closeGRPCConnLater(conn *grpc.ClientConn) {
+	time.AfterFunc(grpcConnCloseGracePeriod, func() {
+		conn.Close()
+	})
+}
+
+// callGRPCWebhook calls the gRPC method equivalent to webHookName, payload and rsp are the same as HTTP webhooks
+func callGRPCWebhook(driver *lbcfapi.LoadBalancerDriver, callCfg driverCallConfig, webHookName string, payload interface{}, rsp interface{}) error {
+	if callCfg.credential != nil && callCfg.credential.authType != lbcfapi.BearerTokenAuth {
+		e := fmt.Errorf("auth type %s is not supported by GRPC driver", callCfg.credential.authType)
+		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", e, driver.Name, webHookName)
+		return e
+	}
+	targets := []string{driver.Spec.Url}
+	if driver.Spec.Service != nil {
+		targets = callCfg.serviceAddrs
+		grpcConns.evictServiceTargets(driver.Namespace+"/"+driver.Name, callCfg.allServiceAddrs)
+	}
+	var err error
+	for i, target := range targets {
+		err = callGRPCTarget(target, driver, callCfg, webHookName, payload, rsp)
+		if err == nil || status.Code(err) != codes.Unavailable || i == len(targets)-1 {
+			break
+		}
+		klog.Warningf("callwebhook failed, try next endpoint. driver: %s, webhookName: %s", driver.Name, webHookName)
+	}
+	if err != nil {
+		e := fmt.Errorf("grpc err: %v", err)
+		if status.Code(err) == codes.Unimplemented {
+			// logged by the caller, because it is not an error if the webhook is optional
+			return &notImplementedError{e}
+		}
+		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", e, driver.Name, webHookName)
+		switch status.Code(err) {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.Internal:
+			return &unavailableError{e}
+		}
+		return e
+	}
+	return nil
+}
+
+func callGRPCTarget(target string, driver *lbcfapi.LoadBalancerDriver, callCfg driverCallConfig, webHookName string, payload interface{}, rsp interface{}) error {
+	conn, err := grpcConns.get(target, callCfg.tls)
+	if err != nil {
+		return fmt.Errorf("dial %s err: %v", target, err)
+	}
+	client := driverpb.NewDriverClient(conn)
+
+	ctx := context.Background()
+	if timeout := getWebhookTimeout(driver, webHookName); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if callCfg.credential != nil {
+		ctx = metadata.AppendToOutgoingContext(ctx, strings.ToLower(auth.AuthorizationHeader), auth.BearerToken(string(callCfg.credential.value)))
+	}
+	if version := driver.Spec.ProtocolVersion; version != "" && version != webhooks.ProtocolV1 {
+		ctx = metadata.AppendToOutgoingContext(ctx, webhooks.ProtocolVersionMetadataKey, version)
+	}
+	return invokeGRPC(ctx, client, webHookName, payload, rsp)
+}
+
+func invokeGRPC(ctx context.Context, client driverpb.DriverClient, webHookName string, payload interface{}, rsp interface{}) error {
+	switch webHookName {
+	case webhooks.ValidateLoadBalancer:
+		out, err := client.ValidateLoadBalancer(ctx, driverpb.FromValidateLoadBalancerRequest(payload.(*webhooks.ValidateLoadBalancerRequest)))
+		if err != nil {
+			return err
+		}
+		*rsp.(*webhooks.ValidateLoadBalancerResponse) = *driverpb.ToValidateLoadBalancerResponse(out)
+	case webhooks.CreateLoadBalancer:
+		out, err := client.CreateLoadBalancer(ctx, driverpb.FromCreateLoadBalancerRequest(payload.(*webhooks.CreateLoadBalancerRequest)))
+		if err != nil {
+			return err
+		}
+		*rsp.(*webhooks.CreateLoadBalancerResponse) = *driverpb.ToCreateLoadBalancerResponse(out)
+	case webhooks.EnsureLoadBalancer:
+		out, err := client.EnsureLoadBalancer(ctx, driverpb.FromEnsureLoadBalancerRequest(payload.(*webhooks.EnsureLoadBalancerRequest)))
+		if err != nil {
+			return err
+		}
+		*rsp.(*webhooks.EnsureLoadBalancerResponse) = *driverpb.ToEnsureLoadBalancerResponse(out)
+	case webhooks.DeleteLoadBalancer:
+		out, err := client.DeleteLoadBalancer(ctx, driverpb.FromDeleteLoadBalancerRequest(payload.(*webhooks.DeleteLoadBalancerRequest)))
+		if err != nil {
+			return err
+		}
+		*rsp.(*webhooks.DeleteLoadBalancerResponse) = *driverpb.ToDeleteLoadBalancerResponse(out)
+	case webhooks.ValidateBackend:
+		out, err := client.ValidateBackend(ctx, driverpb.FromValidateBackendRequest(payload.(*webhooks.ValidateBackendRequest)))
+		if err != nil {
+			return err
+		}
+		*rsp.(*webhooks.ValidateBackendResponse) = *driverpb.ToValidateBackendResponse(out)
+	case webhooks.GenerateBackendAddr:
+		in, err := driverpb.FromGenerateBackendAddrRequest(payload.(*webhooks.GenerateBackendAddrRequest))
+		if err != nil {
+			return err
+		}
+		out, err := client.GenerateBackendAddr(ctx, in)
+		if err != nil {
+			return err
+		}
+		*rsp.(*webhooks.GenerateBackendAddrResponse) = *driverpb.ToGenerateBackendAddrResponse(out)
+	case webhooks.EnsureBackend:
+		out, err := client.EnsureBackend(ctx, driverpb.FromBackendOperationRequest(payload.(*webhooks.BackendOperationRequest)))
+		if err != nil {
+			return err
+		}
+		*rsp.(*webhooks.BackendOperationResponse) = *driverpb.ToBackendOperationResponse(out)
+	case webhooks.DeregBackend:
+		out, err := client.DeregisterBackend(ctx, driverpb.FromBackendOperationRequest(payload.(*webhooks.BackendOperationRequest)))
+		if err != nil {
+			return err
+		}
+		*rsp.(*webhooks.BackendOperationResponse) = *driverpb.ToBackendOperationResponse(out)
+	case webhooks.DrainBackend:
+		out, err := client.DrainBackend(ctx, driverpb.FromBackendOperationRequest(payload.(*webhooks.BackendOperationRequest)))
+		if err != nil {
+			return err
+		}
+		*rsp.(*webhooks.BackendOperationResponse) = *driverpb.ToBackendOperationResponse(out)
+	default:
+		return fmt.Errorf("unknown webhook %s", webHookName)
+	}
+	return nil
+}
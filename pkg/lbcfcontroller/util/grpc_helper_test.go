@@ -0,0 +1,224 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks/driverpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGRPCWebhooks(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	server := grpc.NewServer()
+	driverpb.RegisterDriverServer(server, &fakeGRPCDriver{})
+	go server.Serve(listener)
+	defer server.Stop()
+
+	driver := fakeMockDriver(&url.URL{}, 10*time.Second)
+	driver.Spec.DriverType = string(lbcfapi.GRPCDriver)
+	driver.Spec.Url = listener.Addr().String()
+	invoker := NewWebhookInvoker()
+
+	validateRsp, err := invoker.CallValidateLoadBalancer(driver, &webhooks.ValidateLoadBalancerRequest{
+		LBSpec: map[string]string{"key": "value"},
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	} else if !validateRsp.Succ {
+		t.Errorf("expect succ, get msg %s", validateRsp.Msg)
+	}
+
+	generateRsp, err := invoker.CallGenerateBackendAddr(driver, &webhooks.GenerateBackendAddrRequest{
+		PodBackend: &webhooks.PodBackendInGenerateAddrRequest{
+			Pod: v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pod-0",
+				},
+			},
+			Port: lbcfapi.PortSelector{
+				PortNumber: 80,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	} else if generateRsp.Status != webhooks.StatusSucc || generateRsp.BackendAddr != "pod-0:80" {
+		t.Errorf("expect %s pod-0:80, get %s %s", webhooks.StatusSucc, generateRsp.Status, generateRsp.BackendAddr)
+	}
+
+	ensureRsp, err := invoker.CallEnsureBackend(driver, &webhooks.BackendOperationRequest{
+		InjectedInfo: map[string]string{"key": "value"},
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	} else if ensureRsp.Status != webhooks.StatusRunning || ensureRsp.InjectedInfo["key"] != "value" || ensureRsp.MinRetryDelayInSeconds != 5 {
+		t.Errorf("unexpected response %+v", ensureRsp)
+	}
+}
+
+func TestGRPCConnCache(t *testing.T) {
+	old := grpcConnCloseGracePeriod
+	grpcConnCloseGracePeriod = 50 * time.Millisecond
+	defer func() {
+		grpcConnCloseGracePeriod = old
+	}()
+
+	cache := newGRPCConnCache()
+	target := "127.0.0.1:1"
+	tls1 := &driverTLSConfig{config: &tls.Config{}, fingerprint: "1"}
+	tls2 := &driverTLSConfig{config: &tls.Config{}, fingerprint: "2"}
+	conn1, err := cache.get(target, tls1)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if get, _ := cache.get(target, tls1); get != conn1 {
+		t.Fatalf("expect connection reused")
+	}
+
+	conn2, err := cache.get(target, tls2)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if conn2 == conn1 {
+		t.Fatalf("expect a new connection for the changed TLS configuration")
+	}
+	if len(cache.conns) != 1 {
+		t.Fatalf("expect 1 cached connection, get %d", len(cache.conns))
+	}
+	if conn1.GetState() == connectivity.Shutdown {
+		t.Fatalf("expect the replaced connection not closed immediately")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if conn1.GetState() != connectivity.Shutdown {
+		t.Fatalf("expect the replaced connection closed after the grace period, get %s", conn1.GetState())
+	}
+	if conn2.GetState() == connectivity.Shutdown {
+		t.Fatalf("expect the current connection not closed")
+	}
+	conn2.Close()
+}
+
+func TestGRPCConnCacheEvictServiceTargets(t *testing.T) {
+	old := grpcConnCloseGracePeriod
+	grpcConnCloseGracePeriod = 0
+	defer func() {
+		grpcConnCloseGracePeriod = old
+	}()
+
+	cache := newGRPCConnCache()
+	conns := make(map[string]*grpc.ClientConn)
+	for _, target := range []string{"127.0.0.1:1", "127.0.0.1:2", "127.0.0.1:3"} {
+		conn, err := cache.get(target, nil)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		conns[target] = conn
+	}
+	cache.evictServiceTargets("kube-system/driver-1", []string{"127.0.0.1:1", "127.0.0.1:2"})
+	cache.evictServiceTargets("kube-system/driver-2", []string{"127.0.0.1:2", "127.0.0.1:3"})
+
+	// 127.0.0.1:2 is removed from the endpoints of driver-1, but it is still used by driver-2
+	cache.evictServiceTargets("kube-system/driver-1", []string{"127.0.0.1:1"})
+	if len(cache.conns) != 3 {
+		t.Fatalf("expect 3 cached connections, get %d", len(cache.conns))
+	}
+
+	cache.evictServiceTargets("kube-system/driver-2", []string{"127.0.0.1:3"})
+	if len(cache.conns) != 2 {
+		t.Fatalf("expect 2 cached connections, get %d", len(cache.conns))
+	}
+	if _, ok := cache.conns[grpcConnKey{target: "127.0.0.1:2"}]; ok {
+		t.Fatalf("expect connection to 127.0.0.1:2 evicted")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if conns["127.0.0.1:2"].GetState() != connectivity.Shutdown {
+		t.Fatalf("expect connection to 127.0.0.1:2 closed, get %s", conns["127.0.0.1:2"].GetState())
+	}
+	for _, target := range []string{"127.0.0.1:1", "127.0.0.1:3"} {
+		if conns[target].GetState() == connectivity.Shutdown {
+			t.Fatalf("expect connection to %s not closed", target)
+		}
+		conns[target].Close()
+	}
+}
+
+type fakeGRPCDriver struct{}
+
+func (d *fakeGRPCDriver) ValidateLoadBalancer(ctx context.Context, req *driverpb.ValidateLoadBalancerRequest) (*driverpb.ValidateLoadBalancerResponse, error) {
+	return &driverpb.ValidateLoadBalancerResponse{Succ: req.LbSpec["key"] == "value", Msg: "fake-msg"}, nil
+}
+
+func (d *fakeGRPCDriver) CreateLoadBalancer(ctx context.Context, req *driverpb.CreateLoadBalancerRequest) (*driverpb.CreateLoadBalancerResponse, error) {
+	return &driverpb.CreateLoadBalancerResponse{Status: webhooks.StatusSucc}, nil
+}
+
+func (d *fakeGRPCDriver) EnsureLoadBalancer(ctx context.Context, req *driverpb.EnsureLoadBalancerRequest) (*driverpb.EnsureLoadBalancerResponse, error) {
+	return &driverpb.EnsureLoadBalancerResponse{Status: webhooks.StatusSucc}, nil
+}
+
+func (d *fakeGRPCDriver) DeleteLoadBalancer(ctx context.Context, req *driverpb.DeleteLoadBalancerRequest) (*driverpb.DeleteLoadBalancerResponse, error) {
+	return &driverpb.DeleteLoadBalancerResponse{Status: webhooks.StatusSucc}, nil
+}
+
+func (d *fakeGRPCDriver) ValidateBackend(ctx context.Context, req *driverpb.ValidateBackendRequest) (*driverpb.ValidateBackendResponse, error) {
+	return &driverpb.ValidateBackendResponse{Succ: true}, nil
+}
+
+func (d *fakeGRPCDriver) GenerateBackendAddr(ctx context.Context, req *driverpb.GenerateBackendAddrRequest) (*driverpb.GenerateBackendAddrResponse, error) {
+	pod := &v1.Pod{}
+	if err := json.Unmarshal(req.PodBackend.Pod, pod); err != nil {
+		return nil, err
+	}
+	return &driverpb.GenerateBackendAddrResponse{
+		Status:      webhooks.StatusSucc,
+		BackendAddr: fmt.Sprintf("%s:%d", pod.Name, req.PodBackend.Port.PortNumber),
+	}, nil
+}
+
+func (d *fakeGRPCDriver) EnsureBackend(ctx context.Context, req *driverpb.BackendOperationRequest) (*driverpb.BackendOperationResponse, error) {
+	return &driverpb.BackendOperationResponse{
+		Status:                 webhooks.StatusRunning,
+		MinRetryDelayInSeconds: 5,
+		InjectedInfo:           req.InjectedInfo,
+	}, nil
+}
+
+func (d *fakeGRPCDriver) DeregisterBackend(ctx context.Context, req *driverpb.BackendOperationRequest) (*driverpb.BackendOperationResponse, error) {
+	return &driverpb.BackendOperationResponse{Status: webhooks.StatusSucc}, nil
+}
+
+func (d *fakeGRPCDriver) DrainBackend(ctx context.Context, req *driverpb.BackendOperationRequest) (*driverpb.BackendOperationResponse, error) {
+	return &driverpb.BackendOperationResponse{Status: webhooks.StatusRunning}, nil
+}
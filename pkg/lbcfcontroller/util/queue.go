@@ -23,6 +23,7 @@ import (
 	"k8s.io/klog"
 	"time"
 	"tkestack.io/lb-controlling-framework/pkg/client-go/listers/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/metrics"
 
 	"golang.org/x/time/rate"
 	"k8s.io/client-go/util/workqueue"
@@ -35,16 +36,23 @@ type ConditionalRateLimitingInterface interface {
 	Forget(item interface{})
 	AddAfterFiltered(item interface{}, duration time.Duration)
 	LenWaitingForFilter() int
+	Name() string
 }
 
 // NewConditionalDelayingQueue returns a new instance of ConditionalRateLimitingInterface. If minDelay is less than step, the real minimum delay is step.
 func NewConditionalDelayingQueue(filter QueueFilter, minDelay time.Duration, step time.Duration, maxDelay time.Duration) ConditionalRateLimitingInterface {
+	return NewNamedConditionalDelayingQueue("", filter, minDelay, step, maxDelay)
+}
+
+// NewNamedConditionalDelayingQueue is the same as NewConditionalDelayingQueue, but metrics of the returned queue are reported with the given name
+func NewNamedConditionalDelayingQueue(name string, filter QueueFilter, minDelay time.Duration, step time.Duration, maxDelay time.Duration) ConditionalRateLimitingInterface {
 	rateLimiter := workqueue.NewMaxOfRateLimiter(
 		workqueue.NewItemExponentialFailureRateLimiter(step, maxDelay),
 		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(10), 100)},
 		&fixedRateDelayLimiter{duration: minDelay},
 	)
 	q := &conditionalRateLimitingQueue{
+		name:                   name,
 		DelayingInterface:      workqueue.NewDelayingQueue(),
 		rateLimiter:            rateLimiter,
 		waitingWithFilterQueue: workqueue.NewDelayingQueue(),
@@ -52,12 +60,17 @@ func NewConditionalDelayingQueue(filter QueueFilter, minDelay time.Duration, ste
 		minDelay:               minDelay,
 	}
 
+	if name != "" {
+		metrics.RegisterQueueDepth(name, q.Len)
+	}
+
 	go q.run()
 
 	return q
 }
 
 type conditionalRateLimitingQueue struct {
+	name string
 	workqueue.DelayingInterface
 	rateLimiter            workqueue.RateLimiter
 	waitingWithFilterQueue workqueue.DelayingInterface
@@ -71,9 +84,23 @@ func (q *conditionalRateLimitingQueue) AddAfterMinimumDelay(item interface{}, mi
 	if minDelay.Nanoseconds() > delay.Nanoseconds() {
 		delay = minDelay
 	}
+	if q.name != "" {
+		metrics.IncQueueRetries(q.name)
+	}
 	q.DelayingInterface.AddAfter(item, delay)
 }
 
+// ShutDown shuts down the queue as well as the queue waiting for filter
+func (q *conditionalRateLimitingQueue) ShutDown() {
+	q.waitingWithFilterQueue.ShutDown()
+	q.DelayingInterface.ShutDown()
+}
+
+// Name returns the name of the queue
+func (q *conditionalRateLimitingQueue) Name() string {
+	return q.name
+}
+
 // Forget indicates that an item is finished being retried
 func (q *conditionalRateLimitingQueue) Forget(item interface{}) {
 	q.rateLimiter.Forget(item)
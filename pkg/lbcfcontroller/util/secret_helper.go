@@ -0,0 +1,110 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package util
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	apicorev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	corev1 "k8s.io/client-go/listers/core/v1"
+)
+
+// DefaultSecretCacheTTL is how long a Secret got by the lister returned by NewSecretLister is cached
+const DefaultSecretCacheTTL = 30 * time.Second
+
+// NewSecretLister returns a SecretLister that gets Secrets referenced by drivers from the apiserver directly,
+// so that Secrets of the whole cluster are not cached in memory as an informer does.
+// A Secret is cached for ttl after it is got, only Get is supported.
+func NewSecretLister(client kubernetes.Interface, ttl time.Duration) corev1.SecretLister {
+	return &ttlSecretLister{
+		client:  client,
+		ttl:     ttl,
+		secrets: make(map[types.NamespacedName]*cachedSecret),
+	}
+}
+
+type ttlSecretLister struct {
+	client kubernetes.Interface
+	ttl    time.Duration
+
+	lock    sync.Mutex
+	secrets map[types.NamespacedName]*cachedSecret
+}
+
+type cachedSecret struct {
+	secret   *apicorev1.Secret
+	expireAt time.Time
+}
+
+func (l *ttlSecretLister) List(selector labels.Selector) ([]*apicorev1.Secret, error) {
+	return nil, fmt.Errorf("list is not supported, Secrets are got one by one")
+}
+
+func (l *ttlSecretLister) Secrets(namespace string) corev1.SecretNamespaceLister {
+	return &ttlSecretNamespaceLister{
+		lister:    l,
+		namespace: namespace,
+	}
+}
+
+func (l *ttlSecretLister) get(namespace string, name string) (*apicorev1.Secret, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	now := time.Now()
+	l.lock.Lock()
+	for k, cached := range l.secrets {
+		if !now.Before(cached.expireAt) {
+			delete(l.secrets, k)
+		}
+	}
+	cached, ok := l.secrets[key]
+	l.lock.Unlock()
+	if ok {
+		return cached.secret, nil
+	}
+
+	secret, err := l.client.CoreV1().Secrets(namespace).Get(name, v1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	l.lock.Lock()
+	l.secrets[key] = &cachedSecret{
+		secret:   secret,
+		expireAt: now.Add(l.ttl),
+	}
+	l.lock.Unlock()
+	return secret, nil
+}
+
+type ttlSecretNamespaceLister struct {
+	lister    *ttlSecretLister
+	namespace string
+}
+
+func (l *ttlSecretNamespaceLister) List(selector labels.Selector) ([]*apicorev1.Secret, error) {
+	return l.lister.List(selector)
+}
+
+func (l *ttlSecretNamespaceLister) Get(name string) (*apicorev1.Secret, error) {
+	return l.lister.get(l.namespace, name)
+}
@@ -0,0 +1,67 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"testing"
+	"time"
+
+	apicorev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSecretLister(t *testing.T) {
+	secret := &apicorev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "kube-system",
+			Name:      "auth",
+		},
+		Data: map[string][]byte{"token": []byte("token")},
+	}
+	client := k8sfake.NewSimpleClientset(secret)
+	lister := NewSecretLister(client, 20*time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		get, err := lister.Secrets(secret.Namespace).Get(secret.Name)
+		if err != nil {
+			t.Fatalf("expect no error, get %v", err)
+		}
+		if string(get.Data["token"]) != "token" {
+			t.Fatalf("expect token, get %v", get.Data)
+		}
+	}
+	if len(client.Actions()) != 1 {
+		t.Fatalf("expect 1 request to apiserver, get %d", len(client.Actions()))
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if _, err := lister.Secrets(secret.Namespace).Get(secret.Name); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	if len(client.Actions()) != 2 {
+		t.Fatalf("expect Secret got again after ttl, get %d requests", len(client.Actions()))
+	}
+
+	if _, err := lister.Secrets("default").Get(secret.Name); !errors.IsNotFound(err) {
+		t.Fatalf("expect NotFound, get %v", err)
+	}
+	if _, err := lister.List(nil); err == nil {
+		t.Fatalf("expect List not supported")
+	}
+}
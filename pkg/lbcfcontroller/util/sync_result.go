@@ -26,8 +26,16 @@ func FinishedResult() *SyncResult {
 	return &SyncResult{}
 }
 
-// ErrorResult returns a new SyncResult that call IsError() on it will return true
+// ErrorResult returns a new SyncResult that call IsError() on it will return true.
+// If err is a ThrottledError, the returned SyncResult is the same as ThrottledResult.
+// If err is a CircuitOpenError, the operation is retried after the circuit breaker turns half-open
 func ErrorResult(err error) *SyncResult {
+	if throttled, ok := err.(*ThrottledError); ok {
+		return ThrottledResult(throttled.RetryAfter)
+	}
+	if circuitOpen, ok := err.(*CircuitOpenError); ok {
+		return FailResult(circuitOpen.RetryAfter, circuitOpen.Error())
+	}
 	return &SyncResult{
 		faild: &failedOp{
 			reason: err.Error(),
@@ -55,6 +63,15 @@ func AsyncResult(period time.Duration) *SyncResult {
 	}
 }
 
+// ThrottledResult returns a new SyncResult that call IsThrottled() on it will return true
+func ThrottledResult(delay time.Duration) *SyncResult {
+	return &SyncResult{
+		throttled: &throttledOp{
+			nextRetryDelay: delay,
+		},
+	}
+}
+
 // PeriodicResult returns a new SyncResult that call IsPeriodic() on it will return true
 func PeriodicResult(period time.Duration) *SyncResult {
 	return &SyncResult{
@@ -66,14 +83,15 @@ func PeriodicResult(period time.Duration) *SyncResult {
 
 // SyncResult stores result for sync method of controllers
 type SyncResult struct {
-	faild    *failedOp
-	async    *asyncOp
-	periodic *periodicOp
+	faild     *failedOp
+	async     *asyncOp
+	periodic  *periodicOp
+	throttled *throttledOp
 }
 
 // IsFinished indicates the operation is successfully finished
 func (s *SyncResult) IsFinished() bool {
-	return !s.IsFailed() && !s.IsRunning() && !s.IsPeriodic()
+	return !s.IsFailed() && !s.IsRunning() && !s.IsPeriodic() && !s.IsThrottled()
 }
 
 // IsFailed indicates no error occured during operation, but the operation failed
@@ -91,6 +109,11 @@ func (s *SyncResult) IsPeriodic() bool {
 	return s.periodic != nil
 }
 
+// IsThrottled indicates the operation is not executed because of limits declared by the driver
+func (s *SyncResult) IsThrottled() bool {
+	return s.throttled != nil
+}
+
 // GetFailReason returns the error stored in SyncResult
 func (s *SyncResult) GetFailReason() string {
 	if s.faild == nil {
@@ -107,6 +130,8 @@ func (s *SyncResult) GetNextRun() time.Duration {
 		return s.async.nextCheckDelay
 	} else if s.periodic != nil {
 		return s.periodic.nextRunDelay
+	} else if s.throttled != nil {
+		return s.throttled.nextRetryDelay
 	}
 	return 0
 }
@@ -124,3 +149,7 @@ type asyncOp struct {
 type periodicOp struct {
 	nextRunDelay time.Duration
 }
+
+type throttledOp struct {
+	nextRetryDelay time.Duration
+}
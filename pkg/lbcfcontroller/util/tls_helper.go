@@ -0,0 +1,129 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package util
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"sync"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+
+	apicorev1 "k8s.io/api/core/v1"
+	corev1 "k8s.io/client-go/listers/core/v1"
+)
+
+// driverTLSConfig is the tls.Config built from DriverTLSConfig of a driver
+type driverTLSConfig struct {
+	config *tls.Config
+
+	// fingerprint changes if the CA bundle, server name or the client certificate Secret is changed
+	fingerprint string
+}
+
+// tlsConfigCache caches tls.Config of drivers, so that certificates are parsed only when they are changed
+type tlsConfigCache struct {
+	secretLister corev1.SecretLister
+
+	lock    sync.Mutex
+	configs map[string]*driverTLSConfig
+}
+
+func newTLSConfigCache(secretLister corev1.SecretLister) *tlsConfigCache {
+	return &tlsConfigCache{
+		secretLister: secretLister,
+		configs:      make(map[string]*driverTLSConfig),
+	}
+}
+
+// get returns nil if TLS is not configured in driver spec, a new tls.Config is built if the configuration is changed
+func (c *tlsConfigCache) get(driver *lbcfapi.LoadBalancerDriver) (*driverTLSConfig, error) {
+	driverKey := driver.Namespace + "/" + driver.Name
+	spec := driver.Spec.TLS
+	if spec == nil {
+		c.lock.Lock()
+		delete(c.configs, driverKey)
+		c.lock.Unlock()
+		return nil, nil
+	}
+
+	var secret *apicorev1.Secret
+	if spec.ClientCertSecretName != "" {
+		if c.secretLister == nil {
+			return nil, fmt.Errorf("client certificate is not supported, secret lister is not set")
+		}
+		var err error
+		secret, err = c.secretLister.Secrets(driver.Namespace).Get(spec.ClientCertSecretName)
+		if err != nil {
+			return nil, fmt.Errorf("get client certificate secret %s/%s failed: %v", driver.Namespace, spec.ClientCertSecretName, err)
+		}
+	}
+	serverName := spec.ServerName
+	if serverName == "" && driver.Spec.Service != nil {
+		// the driver is called through endpoint IPs, so the hostname of the Service is verified
+		serverName = fmt.Sprintf("%s.%s.svc", driver.Spec.Service.Name, driver.Spec.Service.Namespace)
+	}
+	fingerprint := tlsFingerprint(spec.CABundle, serverName, secret)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if cur, ok := c.configs[driverKey]; ok && cur.fingerprint == fingerprint {
+		return cur, nil
+	}
+	config, err := buildTLSConfig(spec.CABundle, serverName, secret)
+	if err != nil {
+		return nil, err
+	}
+	cur := &driverTLSConfig{
+		config:      config,
+		fingerprint: fingerprint,
+	}
+	c.configs[driverKey] = cur
+	return cur, nil
+}
+
+func tlsFingerprint(caBundle []byte, serverName string, secret *apicorev1.Secret) string {
+	fingerprint := fmt.Sprintf("%x/%s", sha256.Sum256(caBundle), serverName)
+	if secret != nil {
+		fingerprint += fmt.Sprintf("/%s/%s", secret.UID, secret.ResourceVersion)
+	}
+	return fingerprint
+}
+
+func buildTLSConfig(caBundle []byte, serverName string, secret *apicorev1.Secret) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName: serverName,
+	}
+	if len(caBundle) > 0 {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("no certificate found in caBundle")
+		}
+		config.RootCAs = pool
+	}
+	if secret != nil {
+		cert, err := tls.X509KeyPair(secret.Data[apicorev1.TLSCertKey], secret.Data[apicorev1.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate in secret %s/%s: %v", secret.Namespace, secret.Name, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
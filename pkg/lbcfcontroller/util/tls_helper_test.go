@@ -0,0 +1,143 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package util
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+
+	apicorev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/cert"
+)
+
+func TestWebhookTLS(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rsp := &webhooks.ValidateLoadBalancerResponse{}
+		rsp.Succ = len(r.TLS.PeerCertificates) > 0
+		b, _ := json.Marshal(rsp)
+		w.Write(b)
+	}))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAnyClientCert,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL)
+	driver := fakeMockDriver(u, 10*time.Second)
+	driver.Namespace = "kube-system"
+	driver.Spec.TLS = &lbcfapi.DriverTLSConfig{
+		CABundle: pem.EncodeToMemory(&pem.Block{
+			Type:  "CERTIFICATE",
+			Bytes: server.Certificate().Raw,
+		}),
+		ClientCertSecretName: "client-cert",
+	}
+
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	invoker := NewWebhookInvokerWithConfig(WebhookInvokerConfig{
+		SecretLister: corev1.NewSecretLister(store),
+	})
+	if _, err := invoker.CallValidateLoadBalancer(driver, &webhooks.ValidateLoadBalancerRequest{}); err == nil {
+		t.Fatalf("expect error when client certificate secret not found")
+	}
+
+	store.Add(fakeClientCertSecret(t, driver.Namespace, "client-cert", "1"))
+	rsp, err := invoker.CallValidateLoadBalancer(driver, &webhooks.ValidateLoadBalancerRequest{})
+	if err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	} else if !rsp.Succ {
+		t.Fatalf("expect client certificate sent")
+	}
+
+	withoutTLS := driver.DeepCopy()
+	withoutTLS.Spec.TLS = nil
+	if _, err := invoker.CallValidateLoadBalancer(withoutTLS, &webhooks.ValidateLoadBalancerRequest{}); err == nil {
+		t.Fatalf("expect error when server certificate can not be verified")
+	}
+}
+
+func TestTLSConfigCacheReload(t *testing.T) {
+	store := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	store.Add(fakeClientCertSecret(t, "kube-system", "client-cert", "1"))
+	c := newTLSConfigCache(corev1.NewSecretLister(store))
+	driver := &lbcfapi.LoadBalancerDriver{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "driver",
+			Namespace: "kube-system",
+		},
+		Spec: lbcfapi.LoadBalancerDriverSpec{
+			TLS: &lbcfapi.DriverTLSConfig{
+				ClientCertSecretName: "client-cert",
+			},
+		},
+	}
+
+	first, err := c.get(driver)
+	if err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	if cur, _ := c.get(driver); cur != first {
+		t.Fatalf("expect cached tls config")
+	}
+
+	store.Update(fakeClientCertSecret(t, "kube-system", "client-cert", "2"))
+	if cur, _ := c.get(driver); cur == first {
+		t.Fatalf("expect tls config reloaded after secret updated")
+	}
+
+	driver.Spec.TLS.CABundle = []byte("invalid")
+	if _, err := c.get(driver); err == nil {
+		t.Fatalf("expect error for invalid caBundle")
+	}
+
+	driver.Spec.TLS = nil
+	if cur, err := c.get(driver); err != nil || cur != nil {
+		t.Fatalf("expect nil tls config, get %v, err: %v", cur, err)
+	}
+}
+
+func fakeClientCertSecret(t *testing.T, namespace string, name string, resourceVersion string) *apicorev1.Secret {
+	crt, key, err := cert.GenerateSelfSignedCertKey("lbcf-controller", nil, nil)
+	if err != nil {
+		t.Fatalf("generate certificate failed: %v", err)
+	}
+	return &apicorev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			ResourceVersion: resourceVersion,
+		},
+		Type: apicorev1.SecretTypeTLS,
+		Data: map[string][]byte{
+			apicorev1.TLSCertKey:       crt,
+			apicorev1.TLSPrivateKeyKey: key,
+		},
+	}
+}
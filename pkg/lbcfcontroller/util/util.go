@@ -41,11 +41,97 @@ const (
 
 	// DefaultEnsurePeriod is the default minimum interval for ensureLoadBalancer and ensureBackendRecord
 	DefaultEnsurePeriod = 1 * time.Minute
+
+	// DefaultHealthCheckPath is the default path probed on Webhook drivers
+	DefaultHealthCheckPath = "/healthz"
+
+	// DefaultHealthCheckPeriod is the default interval between driver health checks
+	DefaultHealthCheckPeriod = 10 * time.Second
+
+	// DefaultHealthCheckTimeout is the default timeout of driver health checks
+	DefaultHealthCheckTimeout = 3 * time.Second
+
+	// DefaultHealthCheckFailureThreshold is the default number of consecutive failures for a driver to be considered not ready
+	DefaultHealthCheckFailureThreshold = 3
 )
 
 // PodAvailable indicates the given pod is ready to bind to load balancers
 func PodAvailable(obj *v1.Pod) bool {
-	return obj.Status.PodIP != "" && obj.DeletionTimestamp == nil && pod.IsPodReady(obj)
+	if obj.Status.PodIP == "" || obj.DeletionTimestamp != nil {
+		return false
+	}
+	// pods with the readiness gate of LBCF are not Ready until they are registered,
+	// so only containers are checked for them
+	if HasBackendsRegisteredGate(obj) {
+		return containersReady(obj)
+	}
+	return pod.IsPodReady(obj)
+}
+
+// PodRunning indicates the given pod is running and not being deleted
+func PodRunning(obj *v1.Pod) bool {
+	return obj.Status.PodIP != "" && obj.DeletionTimestamp == nil && obj.Status.Phase == v1.PodRunning
+}
+
+// KeepPodBackend returns true if the registered backend of the given pod should not be deregistered according to policy.
+//
+// Pods are registered only if they are available, the policy determines when they are deregistered.
+// Pods in phase Succeeded or Failed, or without PodIP, are deregistered under every policy
+func KeepPodBackend(obj *v1.Pod, policy lbcfapi.DeregisterPolicy) bool {
+	if obj.Status.PodIP == "" || podTerminated(obj) {
+		return false
+	}
+	switch policy {
+	case lbcfapi.DeregisterIfNotRunning:
+		return PodRunning(obj)
+	case lbcfapi.DeregisterOnDelete:
+		return obj.DeletionTimestamp == nil
+	default:
+		return PodAvailable(obj)
+	}
+}
+
+func podTerminated(obj *v1.Pod) bool {
+	return obj.Status.Phase == v1.PodSucceeded || obj.Status.Phase == v1.PodFailed
+}
+
+// PodStatusChanged returns true if the change of pod status may trigger registering or deregistering
+func PodStatusChanged(old *v1.Pod, cur *v1.Pod) bool {
+	return PodAvailable(old) != PodAvailable(cur) ||
+		PodRunning(old) != PodRunning(cur) ||
+		(old.DeletionTimestamp == nil) != (cur.DeletionTimestamp == nil)
+}
+
+// HasBackendsRegisteredGate returns true if the readiness gate of LBCF is declared in pod
+func HasBackendsRegisteredGate(obj *v1.Pod) bool {
+	for _, gate := range obj.Spec.ReadinessGates {
+		if gate.ConditionType == lbcfapi.PodConditionBackendsRegistered {
+			return true
+		}
+	}
+	return false
+}
+
+// PodBackendsRegistered returns true if the readiness gate condition of LBCF is True
+func PodBackendsRegistered(obj *v1.Pod) bool {
+	_, cond := pod.GetPodCondition(&obj.Status, v1.PodConditionType(lbcfapi.PodConditionBackendsRegistered))
+	return cond != nil && cond.Status == v1.ConditionTrue
+}
+
+func containersReady(obj *v1.Pod) bool {
+	if _, cond := pod.GetPodCondition(&obj.Status, v1.ContainersReady); cond != nil {
+		return cond.Status == v1.ConditionTrue
+	}
+	// ContainersReady is not reported by old kubelets
+	if len(obj.Status.ContainerStatuses) == 0 {
+		return false
+	}
+	for _, status := range obj.Status.ContainerStatuses {
+		if !status.Ready {
+			return false
+		}
+	}
+	return true
 }
 
 // LBCreated indicates the given LoadBalancer is successfully created by webhook createLoadBalancer
@@ -92,6 +178,37 @@ func AddLBCondition(lbStatus *lbcfapi.LoadBalancerStatus, expectCondition lbcfap
 	}
 }
 
+// GetDriverCondition is an helper function to get specific LoadBalancerDriver condition
+func GetDriverCondition(status *lbcfapi.LoadBalancerDriverStatus, conditionType lbcfapi.LoadBalancerDriverConditionType) *lbcfapi.LoadBalancerDriverCondition {
+	for i := range status.Conditions {
+		if status.Conditions[i].Type == conditionType {
+			return &status.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// AddDriverCondition is an helper function to add specific LoadBalancerDriver condition.
+// If a condition with same type exists, the existing one will be overwritten, otherwise, a new condition will be inserted.
+func AddDriverCondition(driverStatus *lbcfapi.LoadBalancerDriverStatus, expectCondition lbcfapi.LoadBalancerDriverCondition) {
+	for i := range driverStatus.Conditions {
+		if driverStatus.Conditions[i].Type == expectCondition.Type {
+			driverStatus.Conditions[i] = expectCondition
+			return
+		}
+	}
+	driverStatus.Conditions = append(driverStatus.Conditions, expectCondition)
+}
+
+// IsDriverReady returns false only if health check is configured and the driver failed it
+func IsDriverReady(driver *lbcfapi.LoadBalancerDriver) bool {
+	if driver.Spec.HealthCheck == nil {
+		return true
+	}
+	condition := GetDriverCondition(&driver.Status, lbcfapi.DriverReady)
+	return condition == nil || condition.Status != lbcfapi.ConditionFalse
+}
+
 // GetBackendRecordCondition is an helper function to get specific BackendRecord condition
 func GetBackendRecordCondition(status *lbcfapi.BackendRecordStatus, conditionType lbcfapi.BackendRecordConditionType) *lbcfapi.BackendRecordCondition {
 	for i := range status.Conditions {
@@ -162,6 +279,22 @@ func IsDriverDraining(driver *lbcfapi.LoadBalancerDriver) bool {
 	return true
 }
 
+// IsWebhookConfigured returns true if webHookName is configured in driver spec
+func IsWebhookConfigured(driver *lbcfapi.LoadBalancerDriver, webHookName string) bool {
+	for _, h := range driver.Spec.Webhooks {
+		if h.Name == webHookName {
+			return true
+		}
+	}
+	return false
+}
+
+// BackendDraining returns true if backend is being drained before deregistered
+func BackendDraining(backend *lbcfapi.BackendRecord) bool {
+	cond := GetBackendRecordCondition(&backend.Status, lbcfapi.BackendDraining)
+	return cond != nil && cond.Status == lbcfapi.ConditionTrue
+}
+
 // CalculateRetryInterval converts userValueInSeconds to time.Duration,
 // it returns DefaultRetryInterval if userValueInSeconds is not specified
 func CalculateRetryInterval(userValueInSeconds int32) time.Duration {
@@ -261,12 +394,76 @@ func MakeBackendLabels(driverName, lbName, groupName, svcName, podName string) m
 	return ret
 }
 
-// ConstructPodBackendRecord constructs a new BackendRecord
-func ConstructPodBackendRecord(lb *lbcfapi.LoadBalancer, group *lbcfapi.BackendGroup, pod *v1.Pod) *lbcfapi.BackendRecord {
+// BackendPorts returns the ports of pods or service in group, port is used if ports is not specified
+func BackendPorts(group *lbcfapi.BackendGroup) []lbcfapi.BackendPort {
+	var port lbcfapi.PortSelector
+	var ports []lbcfapi.BackendPort
+	if group.Spec.Pods != nil {
+		port, ports = group.Spec.Pods.Port, group.Spec.Pods.Ports
+	} else if group.Spec.Service != nil {
+		port, ports = group.Spec.Service.Port, group.Spec.Service.Ports
+	} else {
+		return nil
+	}
+	if len(ports) > 0 {
+		return ports
+	}
+	return []lbcfapi.BackendPort{{PortSelector: port}}
+}
+
+// BackendParameters returns the parameters used to register port, parameters of port override those of group
+func BackendParameters(group *lbcfapi.BackendGroup, port lbcfapi.BackendPort) map[string]string {
+	if len(port.Parameters) == 0 {
+		return group.Spec.Parameters
+	}
+	merged := make(map[string]string, len(group.Spec.Parameters)+len(port.Parameters))
+	for k, v := range group.Spec.Parameters {
+		merged[k] = v
+	}
+	for k, v := range port.Parameters {
+		merged[k] = v
+	}
+	return merged
+}
+
+// ResolvePodPort resolves portName in selector from the container ports of pod,
+// the returned selector carries the container port number and no portName.
+// It returns false if portName is specified but not exposed by pod
+func ResolvePodPort(pod *v1.Pod, selector lbcfapi.PortSelector) (lbcfapi.PortSelector, bool) {
+	if selector.PortName == "" {
+		return selector, true
+	}
+	wantedProtocol := selector.Protocol
+	if wantedProtocol == "" {
+		wantedProtocol = string(v1.ProtocolTCP)
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			protocol := string(port.Protocol)
+			if protocol == "" {
+				protocol = string(v1.ProtocolTCP)
+			}
+			if port.Name == selector.PortName && protocol == wantedProtocol {
+				resolved := selector
+				resolved.PortNumber = port.ContainerPort
+				resolved.PortName = ""
+				return resolved, true
+			}
+		}
+	}
+	return lbcfapi.PortSelector{}, false
+}
+
+// ConstructPodBackendRecord constructs a new BackendRecord, nil is returned if the port is not exposed by pod
+func ConstructPodBackendRecord(lb *lbcfapi.LoadBalancer, group *lbcfapi.BackendGroup, pod *v1.Pod, backendPort lbcfapi.BackendPort) *lbcfapi.BackendRecord {
+	port, ok := ResolvePodPort(pod, backendPort.PortSelector)
+	if !ok {
+		return nil
+	}
 	valueTrue := true
 	return &lbcfapi.BackendRecord{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      MakePodBackendName(lb.Name, group.Name, pod.UID, group.Spec.Pods.Port),
+			Name:      MakePodBackendName(lb.Name, group.Name, pod.UID, port),
 			Namespace: group.Namespace,
 			Labels:    MakeBackendLabels(lb.Spec.LBDriver, lb.Name, group.Name, "", pod.Name),
 			Finalizers: []string{
@@ -290,24 +487,19 @@ func ConstructPodBackendRecord(lb *lbcfapi.LoadBalancer, group *lbcfapi.BackendG
 			LBAttributes: lb.Spec.Attributes,
 			PodBackendInfo: &lbcfapi.PodBackendRecord{
 				Name: pod.Name,
-				Port: group.Spec.Pods.Port,
+				Port: port,
 			},
-			Parameters:   group.Spec.Parameters,
+			Parameters:   BackendParameters(group, backendPort),
 			EnsurePolicy: group.Spec.EnsurePolicy,
+			DrainPolicy:  group.Spec.DrainPolicy,
 		},
 	}
 }
 
 // ConstructServiceBackendRecord constructs a new BackendRecord of type service
-func ConstructServiceBackendRecord(lb *lbcfapi.LoadBalancer, group *lbcfapi.BackendGroup, svc *v1.Service, node *v1.Node) *lbcfapi.BackendRecord {
-	var selectedSvcPort *v1.ServicePort
-	wantedPort := group.Spec.Service.Port
-	for i, svcPort := range svc.Spec.Ports {
-		if svcPort.Port == wantedPort.PortNumber && string(svcPort.Protocol) == wantedPort.Protocol {
-			selectedSvcPort = &svc.Spec.Ports[i]
-			break
-		}
-	}
+func ConstructServiceBackendRecord(lb *lbcfapi.LoadBalancer, group *lbcfapi.BackendGroup, svc *v1.Service, node *v1.Node, backendPort lbcfapi.BackendPort) *lbcfapi.BackendRecord {
+	wantedPort := backendPort.PortSelector
+	selectedSvcPort := findServicePort(svc, wantedPort)
 	if selectedSvcPort == nil || selectedSvcPort.NodePort == 0 {
 		return nil
 	}
@@ -339,16 +531,92 @@ func ConstructServiceBackendRecord(lb *lbcfapi.LoadBalancer, group *lbcfapi.Back
 			LBAttributes: lb.Spec.Attributes,
 			ServiceBackendInfo: &lbcfapi.ServiceBackendRecord{
 				Name:     svc.Name,
-				Port:     group.Spec.Service.Port,
+				Port:     wantedPort,
 				NodePort: selectedSvcPort.NodePort,
 				NodeName: node.Name,
 			},
-			Parameters:   group.Spec.Parameters,
+			Parameters:   BackendParameters(group, backendPort),
 			EnsurePolicy: group.Spec.EnsurePolicy,
+			DrainPolicy:  group.Spec.DrainPolicy,
 		},
 	}
 }
 
+// ConstructEndpointsBackendRecords constructs BackendRecords for the ready endpoints of svc.
+// Each endpoint is registered as a pod backend, endpoints not backed by pods are ignored
+func ConstructEndpointsBackendRecords(lb *lbcfapi.LoadBalancer, group *lbcfapi.BackendGroup, svc *v1.Service, ep *v1.Endpoints, backendPort lbcfapi.BackendPort) []*lbcfapi.BackendRecord {
+	svcPort := findServicePort(svc, backendPort.PortSelector)
+	if svcPort == nil {
+		return nil
+	}
+	valueTrue := true
+	var records []*lbcfapi.BackendRecord
+	for _, subset := range ep.Subsets {
+		var epPort *v1.EndpointPort
+		for i := range subset.Ports {
+			if subset.Ports[i].Name == svcPort.Name && subset.Ports[i].Protocol == svcPort.Protocol {
+				epPort = &subset.Ports[i]
+				break
+			}
+		}
+		if epPort == nil {
+			continue
+		}
+		port := lbcfapi.PortSelector{
+			PortNumber: epPort.Port,
+			Protocol:   string(epPort.Protocol),
+		}
+		for _, addr := range subset.Addresses {
+			if addr.TargetRef == nil || addr.TargetRef.Kind != "Pod" {
+				continue
+			}
+			records = append(records, &lbcfapi.BackendRecord{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      MakePodBackendName(lb.Name, group.Name, addr.TargetRef.UID, port),
+					Namespace: group.Namespace,
+					Labels:    MakeBackendLabels(lb.Spec.LBDriver, lb.Name, group.Name, svc.Name, addr.TargetRef.Name),
+					Finalizers: []string{
+						lbcfapi.FinalizerDeregisterBackend,
+					},
+					OwnerReferences: []metav1.OwnerReference{
+						{
+							APIVersion:         lbcfapi.ApiVersion,
+							BlockOwnerDeletion: &valueTrue,
+							Controller:         &valueTrue,
+							Kind:               "BackendGroup",
+							Name:               group.Name,
+							UID:                group.UID,
+						},
+					},
+				},
+				Spec: lbcfapi.BackendRecordSpec{
+					LBName:       lb.Name,
+					LBDriver:     lb.Spec.LBDriver,
+					LBInfo:       lb.Status.LBInfo,
+					LBAttributes: lb.Spec.Attributes,
+					PodBackendInfo: &lbcfapi.PodBackendRecord{
+						Name: addr.TargetRef.Name,
+						Port: port,
+					},
+					Parameters:   BackendParameters(group, backendPort),
+					EnsurePolicy: group.Spec.EnsurePolicy,
+					DrainPolicy:  group.Spec.DrainPolicy,
+				},
+			})
+		}
+	}
+	return records
+}
+
+func findServicePort(svc *v1.Service, selector lbcfapi.PortSelector) *v1.ServicePort {
+	for i, svcPort := range svc.Spec.Ports {
+		if svcPort.Port == selector.PortNumber && string(svcPort.Protocol) == selector.Protocol {
+			return &svc.Spec.Ports[i]
+		}
+	}
+	return nil
+}
+
 // ConstructStaticBackend constructs BackendRecords of type service
 func ConstructStaticBackend(lb *lbcfapi.LoadBalancer, group *lbcfapi.BackendGroup, staticAddr string) *lbcfapi.BackendRecord {
 	valueTrue := true
@@ -378,6 +646,7 @@ func ConstructStaticBackend(lb *lbcfapi.LoadBalancer, group *lbcfapi.BackendGrou
 			LBAttributes: lb.Spec.Attributes,
 			Parameters:   group.Spec.Parameters,
 			EnsurePolicy: group.Spec.EnsurePolicy,
+			DrainPolicy:  group.Spec.DrainPolicy,
 			StaticAddr:   &staticAddr,
 		},
 	}
@@ -393,6 +662,9 @@ func needUpdateRecord(curObj *lbcfapi.BackendRecord, expectObj *lbcfapi.BackendR
 	if !reflect.DeepEqual(curObj.Spec.EnsurePolicy, expectObj.Spec.EnsurePolicy) {
 		return true
 	}
+	if !reflect.DeepEqual(curObj.Spec.DrainPolicy, expectObj.Spec.DrainPolicy) {
+		return true
+	}
 	return false
 }
 
@@ -432,6 +704,14 @@ func FilterBackendGroup(all []*lbcfapi.BackendGroup, filter func(*lbcfapi.Backen
 	return ret
 }
 
+// PodSelector converts byLabel into a label selector, labelSelector takes precedence over selector
+func PodSelector(byLabel *lbcfapi.SelectPodByLabel) (k8slabel.Selector, error) {
+	if byLabel.LabelSelector != nil {
+		return metav1.LabelSelectorAsSelector(byLabel.LabelSelector)
+	}
+	return k8slabel.SelectorFromSet(k8slabel.Set(byLabel.Selector)), nil
+}
+
 // IsPodMatchBackendGroup returns true if pod is included in group
 func IsPodMatchBackendGroup(group *lbcfapi.BackendGroup, pod *v1.Pod) bool {
 	if group.Namespace != pod.Namespace {
@@ -446,13 +726,67 @@ func IsPodMatchBackendGroup(group *lbcfapi.BackendGroup, pod *v1.Pod) bool {
 		if except.Has(pod.Name) {
 			return false
 		}
-		selector := k8slabel.SelectorFromSet(k8slabel.Set(group.Spec.Pods.ByLabel.Selector))
+		selector, err := PodSelector(group.Spec.Pods.ByLabel)
+		if err != nil {
+			klog.Errorf("invalid pod selector in BackendGroup %s/%s: %v", group.Namespace, group.Name, err)
+			return false
+		}
 		return selector.Matches(k8slabel.Set(pod.Labels))
 	}
 	included := sets.NewString(group.Spec.Pods.ByName...)
 	return included.Has(pod.Name)
 }
 
+// IsEndpointsMatchBackendGroup returns true if backends of group are determined by the endpoints,
+// that is group is in Endpoints mode, or svc is a NodePort service with externalTrafficPolicy Local.
+// svc may be nil if the service is not found
+func IsEndpointsMatchBackendGroup(group *lbcfapi.BackendGroup, svc *v1.Service, ep *v1.Endpoints) bool {
+	if group.Spec.Service == nil || group.Namespace != ep.Namespace || group.Spec.Service.Name != ep.Name {
+		return false
+	}
+	if group.Spec.Service.Mode == lbcfapi.ServiceModeEndpoints {
+		return true
+	}
+	return svc != nil && IsLocalTrafficService(svc)
+}
+
+// IsLocalTrafficService returns true if svc only routes external traffic to endpoints on the receiving node
+func IsLocalTrafficService(svc *v1.Service) bool {
+	return svc.Spec.ExternalTrafficPolicy == v1.ServiceExternalTrafficPolicyTypeLocal
+}
+
+// EndpointsNodeNames returns names of nodes hosting ready endpoints of the service port selected by port.
+// Only subsets carrying the service port are taken into account, since endpoints of a multi-port service
+// may expose different ports
+func EndpointsNodeNames(ep *v1.Endpoints, svc *v1.Service, port lbcfapi.PortSelector) sets.String {
+	nodes := sets.NewString()
+	svcPort := findServicePort(svc, port)
+	if svcPort == nil {
+		return nodes
+	}
+	for _, subset := range ep.Subsets {
+		if !subsetHasPort(subset, svcPort) {
+			continue
+		}
+		for _, addr := range subset.Addresses {
+			if addr.NodeName != nil && *addr.NodeName != "" {
+				nodes.Insert(*addr.NodeName)
+			}
+		}
+	}
+	return nodes
+}
+
+// subsetHasPort returns true if subset carries svcPort, endpoint ports are named after service ports
+func subsetHasPort(subset v1.EndpointSubset, svcPort *v1.ServicePort) bool {
+	for _, p := range subset.Ports {
+		if p.Name == svcPort.Name && p.Protocol == svcPort.Protocol {
+			return true
+		}
+	}
+	return false
+}
+
 // IsLBMatchBackendGroup returns true if group is connected to lb
 func IsLBMatchBackendGroup(group *lbcfapi.BackendGroup, lb *lbcfapi.LoadBalancer) bool {
 	if group.Namespace == lb.Namespace && group.Spec.LBName == lb.Name {
@@ -546,6 +880,17 @@ func NeedEnqueueLB(old *lbcfapi.LoadBalancer, cur *lbcfapi.LoadBalancer) bool {
 	return false
 }
 
+// NeedEnqueueDriver determines if the given LoadBalancerDriver should be enqueue, updates to status are ignored
+func NeedEnqueueDriver(old *lbcfapi.LoadBalancerDriver, cur *lbcfapi.LoadBalancerDriver) bool {
+	if old.DeletionTimestamp == nil && cur.DeletionTimestamp != nil {
+		return true
+	}
+	if old.Generation != cur.Generation {
+		return true
+	}
+	return !reflect.DeepEqual(old.Labels, cur.Labels)
+}
+
 // NeedEnqueueBackend determines if the given BackendRecord should be enqueue
 func NeedEnqueueBackend(old *lbcfapi.BackendRecord, cur *lbcfapi.BackendRecord) bool {
 	if old.DeletionTimestamp == nil && cur.DeletionTimestamp != nil {
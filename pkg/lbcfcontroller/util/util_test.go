@@ -50,6 +50,43 @@ func TestPodAvailable(t *testing.T) {
 				},
 			},
 		},
+		// readiness gate is not satisfied, but containers are ready
+		{
+			Spec: v1.PodSpec{
+				ReadinessGates: []v1.PodReadinessGate{
+					{ConditionType: lbcfapi.PodConditionBackendsRegistered},
+				},
+			},
+			Status: v1.PodStatus{
+				PodIP: "1.1.1.1",
+				Conditions: []v1.PodCondition{
+					{
+						Type:   v1.PodReady,
+						Status: v1.ConditionFalse,
+					},
+					{
+						Type:   v1.ContainersReady,
+						Status: v1.ConditionTrue,
+					},
+				},
+			},
+		},
+		// readiness gate without ContainersReady condition
+		{
+			Spec: v1.PodSpec{
+				ReadinessGates: []v1.PodReadinessGate{
+					{ConditionType: lbcfapi.PodConditionBackendsRegistered},
+				},
+			},
+			Status: v1.PodStatus{
+				PodIP: "1.1.1.1",
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Ready: true,
+					},
+				},
+			},
+		},
 	}
 	shouldNotBind := []v1.Pod{
 		// deletionTimestamp is set
@@ -106,6 +143,28 @@ func TestPodAvailable(t *testing.T) {
 				PodIP: "1.1.1.1",
 			},
 		},
+		// readiness gate with containers not ready
+		{
+			Spec: v1.PodSpec{
+				ReadinessGates: []v1.PodReadinessGate{
+					{ConditionType: lbcfapi.PodConditionBackendsRegistered},
+				},
+			},
+			Status: v1.PodStatus{
+				PodIP: "1.1.1.1",
+				Conditions: []v1.PodCondition{
+					{
+						Type:   v1.ContainersReady,
+						Status: v1.ConditionFalse,
+					},
+				},
+				ContainerStatuses: []v1.ContainerStatus{
+					{
+						Ready: true,
+					},
+				},
+			},
+		},
 	}
 	for _, pod := range shouldBind {
 		if !PodAvailable(&pod) {
@@ -119,6 +178,143 @@ func TestPodAvailable(t *testing.T) {
 	}
 }
 
+func TestKeepPodBackend(t *testing.T) {
+	notReady := &v1.Pod{
+		Status: v1.PodStatus{
+			PodIP: "1.1.1.1",
+			Phase: v1.PodRunning,
+		},
+	}
+	failed := &v1.Pod{
+		Status: v1.PodStatus{
+			PodIP: "1.1.1.1",
+			Phase: v1.PodFailed,
+		},
+	}
+	succeeded := &v1.Pod{
+		Status: v1.PodStatus{
+			PodIP: "1.1.1.1",
+			Phase: v1.PodSucceeded,
+		},
+	}
+	noIP := &v1.Pod{
+		Status: v1.PodStatus{
+			Phase: v1.PodRunning,
+		},
+	}
+	deleting := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+		Status: v1.PodStatus{
+			PodIP: "1.1.1.1",
+			Phase: v1.PodRunning,
+		},
+	}
+	cases := []struct {
+		name   string
+		pod    *v1.Pod
+		policy lbcfapi.DeregisterPolicy
+		expect bool
+	}{
+		{"default-not-ready", notReady, "", false},
+		{"if-not-ready", notReady, lbcfapi.DeregisterIfNotReady, false},
+		{"if-not-running-not-ready", notReady, lbcfapi.DeregisterIfNotRunning, true},
+		{"if-not-running-failed", failed, lbcfapi.DeregisterIfNotRunning, false},
+		{"on-delete-not-ready", notReady, lbcfapi.DeregisterOnDelete, true},
+		{"on-delete-failed", failed, lbcfapi.DeregisterOnDelete, false},
+		{"on-delete-succeeded", succeeded, lbcfapi.DeregisterOnDelete, false},
+		{"on-delete-no-ip", noIP, lbcfapi.DeregisterOnDelete, false},
+		{"on-delete-deleting", deleting, lbcfapi.DeregisterOnDelete, false},
+	}
+	for _, c := range cases {
+		if get := KeepPodBackend(c.pod, c.policy); get != c.expect {
+			t.Errorf("case %s, expect %v, get %v", c.name, c.expect, get)
+		}
+	}
+}
+
+func TestResolvePodPort(t *testing.T) {
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Ports: []v1.ContainerPort{
+						{Name: "metrics", ContainerPort: 9090},
+					},
+				},
+				{
+					Ports: []v1.ContainerPort{
+						{Name: "http", ContainerPort: 8080},
+						{Name: "dns", ContainerPort: 53, Protocol: v1.ProtocolUDP},
+					},
+				},
+			},
+		},
+	}
+	cases := []struct {
+		name       string
+		selector   lbcfapi.PortSelector
+		expectPort int32
+		expectOK   bool
+	}{
+		{"by-number", lbcfapi.PortSelector{PortNumber: 80, Protocol: "TCP"}, 80, true},
+		{"by-name", lbcfapi.PortSelector{PortName: "http", Protocol: "TCP"}, 8080, true},
+		{"by-name-default-protocol", lbcfapi.PortSelector{PortName: "http"}, 8080, true},
+		{"by-name-udp", lbcfapi.PortSelector{PortName: "dns", Protocol: "UDP"}, 53, true},
+		{"protocol-not-match", lbcfapi.PortSelector{PortName: "http", Protocol: "UDP"}, 0, false},
+		{"name-not-found", lbcfapi.PortSelector{PortName: "https", Protocol: "TCP"}, 0, false},
+	}
+	for _, c := range cases {
+		get, ok := ResolvePodPort(pod, c.selector)
+		if ok != c.expectOK || get.PortNumber != c.expectPort {
+			t.Errorf("case %s, expect %d, %v, get %d, %v", c.name, c.expectPort, c.expectOK, get.PortNumber, ok)
+		}
+		if get.PortName != "" {
+			t.Errorf("case %s, expect portName cleared, get %q", c.name, get.PortName)
+		}
+	}
+}
+
+func TestBackendPorts(t *testing.T) {
+	group := &lbcfapi.BackendGroup{
+		Spec: lbcfapi.BackendGroupSpec{
+			Pods: &lbcfapi.PodBackend{
+				Port: lbcfapi.PortSelector{PortNumber: 80, Protocol: "TCP"},
+			},
+			Parameters: map[string]string{"weight": "10", "scheme": "http"},
+		},
+	}
+	ports := BackendPorts(group)
+	if len(ports) != 1 || ports[0].PortNumber != 80 {
+		t.Fatalf("expect port 80, get %+v", ports)
+	}
+	if params := BackendParameters(group, ports[0]); !reflect.DeepEqual(params, group.Spec.Parameters) {
+		t.Fatalf("expect parameters of group, get %v", params)
+	}
+
+	group.Spec.Pods.Port = lbcfapi.PortSelector{}
+	group.Spec.Pods.Ports = []lbcfapi.BackendPort{
+		{PortSelector: lbcfapi.PortSelector{PortNumber: 80, Protocol: "TCP"}},
+		{PortSelector: lbcfapi.PortSelector{PortNumber: 443, Protocol: "TCP"}, Parameters: map[string]string{"scheme": "https"}},
+	}
+	ports = BackendPorts(group)
+	if len(ports) != 2 || ports[0].PortNumber != 80 || ports[1].PortNumber != 443 {
+		t.Fatalf("expect port 80 and 443, get %+v", ports)
+	}
+	expect := map[string]string{"weight": "10", "scheme": "https"}
+	if params := BackendParameters(group, ports[1]); !reflect.DeepEqual(params, expect) {
+		t.Fatalf("expect %v, get %v", expect, params)
+	}
+	if group.Spec.Parameters["scheme"] != "http" {
+		t.Fatalf("parameters of group should not be modified")
+	}
+
+	if ports := BackendPorts(&lbcfapi.BackendGroup{Spec: lbcfapi.BackendGroupSpec{Static: []string{"1.1.1.1:80"}}}); len(ports) != 0 {
+		t.Fatalf("expect no port for static backends, get %+v", ports)
+	}
+}
+
 func TestLBCreated(t *testing.T) {
 	created := []*lbcfapi.LoadBalancer{
 		{
@@ -951,6 +1147,75 @@ func TestIsPodMatchBackendGroup(t *testing.T) {
 			},
 			expect: true,
 		},
+		{
+			name: "byLabel-labelSelector-match",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					Pods: &lbcfapi.PodBackend{
+						ByLabel: &lbcfapi.SelectPodByLabel{
+							LabelSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{
+									"app": "web",
+								},
+								MatchExpressions: []metav1.LabelSelectorRequirement{
+									{
+										Key:      "track",
+										Operator: metav1.LabelSelectorOpIn,
+										Values:   []string{"stable", "canary"},
+									},
+									{
+										Key:      "drain",
+										Operator: metav1.LabelSelectorOpNotIn,
+										Values:   []string{"true"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app":   "web",
+						"track": "canary",
+					},
+				},
+			},
+			expect: true,
+		},
+		{
+			name: "byLabel-labelSelector-not-match",
+			group: &lbcfapi.BackendGroup{
+				Spec: lbcfapi.BackendGroupSpec{
+					Pods: &lbcfapi.PodBackend{
+						ByLabel: &lbcfapi.SelectPodByLabel{
+							LabelSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{
+									"app": "web",
+								},
+								MatchExpressions: []metav1.LabelSelectorRequirement{
+									{
+										Key:      "drain",
+										Operator: metav1.LabelSelectorOpNotIn,
+										Values:   []string{"true"},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			pod: &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{
+						"app":   "web",
+						"drain": "true",
+					},
+				},
+			},
+			expect: false,
+		},
 		{
 			name: "byLabel-not-match",
 			group: &lbcfapi.BackendGroup{
@@ -1177,6 +1442,109 @@ func TestIsSvcMatchBackendGroup(t *testing.T) {
 	}
 }
 
+func TestIsEndpointsMatchBackendGroup(t *testing.T) {
+	newGroup := func(mode lbcfapi.ServiceBackendMode) *lbcfapi.BackendGroup {
+		return &lbcfapi.BackendGroup{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "group",
+				Namespace: "test-ns",
+			},
+			Spec: lbcfapi.BackendGroupSpec{
+				Service: &lbcfapi.ServiceBackend{
+					Name: "test-svc",
+					Port: lbcfapi.PortSelector{
+						PortNumber: 80,
+						Protocol:   "TCP",
+					},
+					Mode: mode,
+				},
+			},
+		}
+	}
+	ep := &v1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-svc",
+			Namespace: "test-ns",
+		},
+	}
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-svc",
+			Namespace: "test-ns",
+		},
+		Spec: v1.ServiceSpec{
+			Type:                  v1.ServiceTypeNodePort,
+			ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeCluster,
+		},
+	}
+	if !IsEndpointsMatchBackendGroup(newGroup(lbcfapi.ServiceModeEndpoints), nil, ep) {
+		t.Fatalf("expect match in Endpoints mode")
+	}
+	if IsEndpointsMatchBackendGroup(newGroup(""), svc, ep) {
+		t.Fatalf("expect no match in NodePort mode with externalTrafficPolicy Cluster")
+	}
+	svc.Spec.ExternalTrafficPolicy = v1.ServiceExternalTrafficPolicyTypeLocal
+	if !IsEndpointsMatchBackendGroup(newGroup(""), svc, ep) {
+		t.Fatalf("expect match in NodePort mode with externalTrafficPolicy Local")
+	}
+	ep.Name = "another-svc"
+	if IsEndpointsMatchBackendGroup(newGroup(lbcfapi.ServiceModeEndpoints), svc, ep) {
+		t.Fatalf("expect no match for endpoints of another service")
+	}
+}
+
+func TestEndpointsNodeNames(t *testing.T) {
+	node1, node2, node3, node4 := "node1", "node2", "node3", "node4"
+	svc := &v1.Service{
+		Spec: v1.ServiceSpec{
+			Ports: []v1.ServicePort{
+				{Name: "http", Port: 80, Protocol: v1.ProtocolTCP},
+				{Name: "https", Port: 443, Protocol: v1.ProtocolTCP},
+			},
+		},
+	}
+	ep := &v1.Endpoints{
+		Subsets: []v1.EndpointSubset{
+			{
+				Addresses: []v1.EndpointAddress{
+					{IP: "1.1.1.1", NodeName: &node1},
+					{IP: "1.1.1.2", NodeName: &node2},
+					{IP: "1.1.1.3"},
+				},
+				NotReadyAddresses: []v1.EndpointAddress{
+					{IP: "1.1.1.4", NodeName: &node3},
+				},
+				Ports: []v1.EndpointPort{
+					{Name: "http", Port: 8080, Protocol: v1.ProtocolTCP},
+					{Name: "https", Port: 8443, Protocol: v1.ProtocolTCP},
+				},
+			},
+			{
+				Addresses: []v1.EndpointAddress{
+					{IP: "1.1.1.5", NodeName: &node4},
+				},
+				Ports: []v1.EndpointPort{
+					{Name: "https", Port: 8443, Protocol: v1.ProtocolTCP},
+				},
+			},
+		},
+	}
+	cases := []struct {
+		name   string
+		port   lbcfapi.PortSelector
+		expect sets.String
+	}{
+		{"http", lbcfapi.PortSelector{PortNumber: 80, Protocol: "TCP"}, sets.NewString(node1, node2)},
+		{"https", lbcfapi.PortSelector{PortNumber: 443, Protocol: "TCP"}, sets.NewString(node1, node2, node4)},
+		{"port-not-found", lbcfapi.PortSelector{PortNumber: 80, Protocol: "UDP"}, sets.NewString()},
+	}
+	for _, c := range cases {
+		if get := EndpointsNodeNames(ep, svc, c.port); !get.Equal(c.expect) {
+			t.Errorf("case %s, expect %v, get %v", c.name, c.expect.List(), get.List())
+		}
+	}
+}
+
 func TestCompareBackendRecords(t *testing.T) {
 	expectAdd := &lbcfapi.BackendRecord{
 		ObjectMeta: metav1.ObjectMeta{
@@ -18,17 +18,22 @@
 package util
 
 import (
-	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
+	"sync"
 	"time"
 
 	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/metrics"
 	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks/auth"
 
 	"github.com/parnurzeal/gorequest"
+	corev1 "k8s.io/client-go/listers/core/v1"
 	"k8s.io/klog"
 )
 
@@ -49,6 +54,15 @@ type WebhookInvoker interface {
 	CallEnsureBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error)
 
 	CallDeregisterBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error)
+
+	CallDrainBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error)
+
+	CallHealthCheck(driver *lbcfapi.LoadBalancerDriver) error
+
+	GetCircuitBreakerStatus(driver *lbcfapi.LoadBalancerDriver) CircuitBreakerStatus
+
+	// ForgetDriver drops the states kept for the driver, it is called after the driver is deleted
+	ForgetDriver(driverKey string)
 }
 
 // NewWebhookInvoker creates a new instance of WebhookInvoker
@@ -56,13 +70,210 @@ func NewWebhookInvoker() WebhookInvoker {
 	return &WebhookInvokerImpl{}
 }
 
+// WebhookInvokerConfig configures a WebhookInvoker created by NewWebhookInvokerWithConfig
+type WebhookInvokerConfig struct {
+	// MaxConcurrentCallsPerDriver is the maximum number of in-flight calls to each driver, 0 means no limit
+	MaxConcurrentCallsPerDriver int
+
+	// SecretLister is used to get client certificates and auth secrets of drivers,
+	// drivers with tls.clientCertSecretName or auth can not be called if it is nil
+	SecretLister corev1.SecretLister
+
+	// ServiceLister and EndpointsLister are used to resolve drivers referenced by Service,
+	// such drivers can not be called if ServiceLister is nil
+	ServiceLister   corev1.ServiceLister
+	EndpointsLister corev1.EndpointsLister
+
+	// CircuitBreakerFailureThreshold is the number of consecutive transport errors or 5xx responses
+	// after which calls to the driver are short-circuited, 0 means the circuit breaker is disabled
+	CircuitBreakerFailureThreshold int
+
+	// CircuitBreakerOpenDuration is how long the circuit stays open before a trial call is allowed
+	CircuitBreakerOpenDuration time.Duration
+
+	// OnCircuitBreakerStateChange is called with the key of the driver whose circuit breaker changed state
+	OnCircuitBreakerStateChange func(driverKey string)
+
+	// MaxExecOutputBytes limits stdout and stderr of Exec drivers, DefaultMaxExecOutputBytes is used if it is not greater than 0
+	MaxExecOutputBytes int
+
+	// OnExecStderr is called if an Exec driver writes to stderr
+	OnExecStderr func(driver *lbcfapi.LoadBalancerDriver, webHookName string, stderr string)
+
+	// ExecDriverDir is the directory that executables of Exec drivers must be in, Exec drivers are disabled if it is empty
+	ExecDriverDir string
+}
+
+// NewWebhookInvokerWithConfig creates a new instance of WebhookInvoker with the given config
+func NewWebhookInvokerWithConfig(cfg WebhookInvokerConfig) WebhookInvoker {
+	w := &WebhookInvokerImpl{
+		maxConcurrentCallsPerDriver: cfg.MaxConcurrentCallsPerDriver,
+		secretLister:                cfg.SecretLister,
+		serviceLister:               cfg.ServiceLister,
+		endpointsLister:             cfg.EndpointsLister,
+		tlsConfigs:                  newTLSConfigCache(cfg.SecretLister),
+		maxExecOutputBytes:          cfg.MaxExecOutputBytes,
+		onExecStderr:                cfg.OnExecStderr,
+		execDriverDir:               cfg.ExecDriverDir,
+	}
+	if cfg.CircuitBreakerFailureThreshold > 0 {
+		w.breaker = newDriverBreaker(int32(cfg.CircuitBreakerFailureThreshold), cfg.CircuitBreakerOpenDuration, cfg.OnCircuitBreakerStateChange)
+	}
+	return w
+}
+
 // WebhookInvokerImpl is an implementation of WebhookInvoker
-type WebhookInvokerImpl struct{}
+type WebhookInvokerImpl struct {
+	maxConcurrentCallsPerDriver int
+	secretLister                corev1.SecretLister
+	serviceLister               corev1.ServiceLister
+	endpointsLister             corev1.EndpointsLister
+
+	lock          sync.Mutex
+	driverSlots   map[string]chan struct{}
+	driverLimiter *driverLimiter
+	tlsConfigs    *tlsConfigCache
+
+	// breaker is nil if the circuit breaker is disabled
+	breaker *driverBreaker
+
+	maxExecOutputBytes int
+	onExecStderr       func(driver *lbcfapi.LoadBalancerDriver, webHookName string, stderr string)
+	execDriverDir      string
+}
+
+// callWebhook returns a ThrottledError if limits declared in driver spec are exceeded,
+// or a CircuitOpenError if the circuit breaker of the driver is open,
+// otherwise it blocks until there is a free slot for the driver, and then calls the webhook
+func (w *WebhookInvokerImpl) callWebhook(driver *lbcfapi.LoadBalancerDriver, webHookName string, payload interface{}, rsp interface{}) error {
+	release, err := w.getDriverLimiter().acquire(driver, webHookName)
+	if err != nil {
+		klog.V(3).Infof("callwebhook throttled: %v", err)
+		metrics.ObserveWebhookCall(driver.Namespace+"/"+driver.Name, webHookName, metrics.WebhookResultThrottled, 0)
+		return err
+	}
+	defer release()
+
+	callCfg, err := w.getCallConfig(driver)
+	if err != nil {
+		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", err, driver.Name, webHookName)
+		metrics.ObserveWebhookCall(driver.Namespace+"/"+driver.Name, webHookName, metrics.WebhookResultError, 0)
+		return err
+	}
+
+	if w.breaker != nil {
+		if err := w.breaker.allow(driver); err != nil {
+			klog.V(3).Infof("callwebhook short-circuited: %v", err)
+			metrics.ObserveWebhookCall(driver.Namespace+"/"+driver.Name, webHookName, metrics.WebhookResultCircuitOpen, 0)
+			return err
+		}
+	}
+
+	if w.maxConcurrentCallsPerDriver > 0 {
+		slots := w.getDriverSlots(driver.Namespace + "/" + driver.Name)
+		slots <- struct{}{}
+		defer func() {
+			<-slots
+		}()
+	}
+	err = callWebhook(driver, callCfg, webHookName, payload, rsp)
+	if w.breaker != nil {
+		w.breaker.record(driver, err)
+	}
+	return err
+}
+
+// GetCircuitBreakerStatus returns the state of the circuit breaker of driver, it is always closed if the circuit breaker is disabled
+func (w *WebhookInvokerImpl) GetCircuitBreakerStatus(driver *lbcfapi.LoadBalancerDriver) CircuitBreakerStatus {
+	if w.breaker == nil {
+		return CircuitBreakerStatus{State: CircuitClosed}
+	}
+	return w.breaker.status(driver)
+}
+
+func (w *WebhookInvokerImpl) getCallConfig(driver *lbcfapi.LoadBalancerDriver) (driverCallConfig, error) {
+	tlsConfig, err := w.getTLSConfigCache().get(driver)
+	if err != nil {
+		return driverCallConfig{}, err
+	}
+	credential, err := getDriverCredential(w.secretLister, driver)
+	if err != nil {
+		return driverCallConfig{}, err
+	}
+	var allServiceAddrs, serviceAddrs []string
+	if driver.Spec.Service != nil {
+		allServiceAddrs, err = resolveServiceAddrs(w.serviceLister, w.endpointsLister, driver.Spec.Service)
+		if err != nil {
+			return driverCallConfig{}, err
+		}
+		serviceAddrs = allServiceAddrs
+		if len(serviceAddrs) > maxServiceEndpointAttempts {
+			serviceAddrs = serviceAddrs[:maxServiceEndpointAttempts]
+		}
+	}
+	callCfg := driverCallConfig{
+		tls:                tlsConfig,
+		credential:         credential,
+		serviceAddrs:       serviceAddrs,
+		allServiceAddrs:    allServiceAddrs,
+		maxExecOutputBytes: w.maxExecOutputBytes,
+		execDriverDir:      w.execDriverDir,
+	}
+	if callCfg.maxExecOutputBytes <= 0 {
+		callCfg.maxExecOutputBytes = DefaultMaxExecOutputBytes
+	}
+	if w.onExecStderr != nil {
+		callCfg.onExecStderr = func(webHookName string, stderr string) {
+			w.onExecStderr(driver, webHookName, stderr)
+		}
+	}
+	return callCfg, nil
+}
+
+func (w *WebhookInvokerImpl) getDriverLimiter() *driverLimiter {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.driverLimiter == nil {
+		w.driverLimiter = newDriverLimiter()
+	}
+	return w.driverLimiter
+}
+
+func (w *WebhookInvokerImpl) getTLSConfigCache() *tlsConfigCache {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.tlsConfigs == nil {
+		w.tlsConfigs = newTLSConfigCache(nil)
+	}
+	return w.tlsConfigs
+}
+
+func (w *WebhookInvokerImpl) getDriverSlots(driverKey string) chan struct{} {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.driverSlots == nil {
+		w.driverSlots = make(map[string]chan struct{})
+	}
+	slots, ok := w.driverSlots[driverKey]
+	if !ok {
+		slots = make(chan struct{}, w.maxConcurrentCallsPerDriver)
+		w.driverSlots[driverKey] = slots
+	}
+	return slots
+}
+
+// ForgetDriver drops the states kept for the driver, calls in flight release their slots to the dropped states
+func (w *WebhookInvokerImpl) ForgetDriver(driverKey string) {
+	w.getDriverLimiter().forget(driverKey)
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	delete(w.driverSlots, driverKey)
+}
 
 // CallValidateLoadBalancer calls webhook validateLoadBalancer on driver
 func (w *WebhookInvokerImpl) CallValidateLoadBalancer(driver *lbcfapi.LoadBalancerDriver, req *webhooks.ValidateLoadBalancerRequest) (*webhooks.ValidateLoadBalancerResponse, error) {
 	rsp := &webhooks.ValidateLoadBalancerResponse{}
-	if err := callWebhook(driver, webhooks.ValidateLoadBalancer, req, rsp); err != nil {
+	if err := w.callWebhook(driver, webhooks.ValidateLoadBalancer, req, rsp); err != nil {
 		return nil, err
 	}
 	return rsp, nil
@@ -71,7 +282,7 @@ func (w *WebhookInvokerImpl) CallValidateLoadBalancer(driver *lbcfapi.LoadBalanc
 // CallCreateLoadBalancer calls webhook createLoadBalancer on driver
 func (w *WebhookInvokerImpl) CallCreateLoadBalancer(driver *lbcfapi.LoadBalancerDriver, req *webhooks.CreateLoadBalancerRequest) (*webhooks.CreateLoadBalancerResponse, error) {
 	rsp := &webhooks.CreateLoadBalancerResponse{}
-	if err := callWebhook(driver, webhooks.CreateLoadBalancer, req, rsp); err != nil {
+	if err := w.callWebhook(driver, webhooks.CreateLoadBalancer, req, rsp); err != nil {
 		return nil, err
 	}
 	return rsp, nil
@@ -80,7 +291,7 @@ func (w *WebhookInvokerImpl) CallCreateLoadBalancer(driver *lbcfapi.LoadBalancer
 // CallEnsureLoadBalancer calls webhook ensureLoadBalancer on driver
 func (w *WebhookInvokerImpl) CallEnsureLoadBalancer(driver *lbcfapi.LoadBalancerDriver, req *webhooks.EnsureLoadBalancerRequest) (*webhooks.EnsureLoadBalancerResponse, error) {
 	rsp := &webhooks.EnsureLoadBalancerResponse{}
-	if err := callWebhook(driver, webhooks.EnsureLoadBalancer, req, rsp); err != nil {
+	if err := w.callWebhook(driver, webhooks.EnsureLoadBalancer, req, rsp); err != nil {
 		return nil, err
 	}
 	return rsp, nil
@@ -89,7 +300,7 @@ func (w *WebhookInvokerImpl) CallEnsureLoadBalancer(driver *lbcfapi.LoadBalancer
 // CallDeleteLoadBalancer calls webhook deleteLoadBalancer on driver
 func (w *WebhookInvokerImpl) CallDeleteLoadBalancer(driver *lbcfapi.LoadBalancerDriver, req *webhooks.DeleteLoadBalancerRequest) (*webhooks.DeleteLoadBalancerResponse, error) {
 	rsp := &webhooks.DeleteLoadBalancerResponse{}
-	if err := callWebhook(driver, webhooks.DeleteLoadBalancer, req, rsp); err != nil {
+	if err := w.callWebhook(driver, webhooks.DeleteLoadBalancer, req, rsp); err != nil {
 		return nil, err
 	}
 	return rsp, nil
@@ -98,7 +309,7 @@ func (w *WebhookInvokerImpl) CallDeleteLoadBalancer(driver *lbcfapi.LoadBalancer
 // CallValidateBackend calls webhook validateBackend on driver
 func (w *WebhookInvokerImpl) CallValidateBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.ValidateBackendRequest) (*webhooks.ValidateBackendResponse, error) {
 	rsp := &webhooks.ValidateBackendResponse{}
-	if err := callWebhook(driver, webhooks.ValidateBackend, req, rsp); err != nil {
+	if err := w.callWebhook(driver, webhooks.ValidateBackend, req, rsp); err != nil {
 		return nil, err
 	}
 	return rsp, nil
@@ -107,7 +318,7 @@ func (w *WebhookInvokerImpl) CallValidateBackend(driver *lbcfapi.LoadBalancerDri
 // CallGenerateBackendAddr calls webhook generateBackendAddr on driver
 func (w *WebhookInvokerImpl) CallGenerateBackendAddr(driver *lbcfapi.LoadBalancerDriver, req *webhooks.GenerateBackendAddrRequest) (*webhooks.GenerateBackendAddrResponse, error) {
 	rsp := &webhooks.GenerateBackendAddrResponse{}
-	if err := callWebhook(driver, webhooks.GenerateBackendAddr, req, rsp); err != nil {
+	if err := w.callWebhook(driver, webhooks.GenerateBackendAddr, req, rsp); err != nil {
 		return nil, err
 	}
 	return rsp, nil
@@ -116,7 +327,7 @@ func (w *WebhookInvokerImpl) CallGenerateBackendAddr(driver *lbcfapi.LoadBalance
 // CallEnsureBackend calls webhook ensureBackend on driver
 func (w *WebhookInvokerImpl) CallEnsureBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
 	rsp := &webhooks.BackendOperationResponse{}
-	if err := callWebhook(driver, webhooks.EnsureBackend, req, rsp); err != nil {
+	if err := w.callWebhook(driver, webhooks.EnsureBackend, req, rsp); err != nil {
 		return nil, err
 	}
 	return rsp, nil
@@ -125,46 +336,248 @@ func (w *WebhookInvokerImpl) CallEnsureBackend(driver *lbcfapi.LoadBalancerDrive
 // CallDeregisterBackend calls webhook deregisterBackend on driver
 func (w *WebhookInvokerImpl) CallDeregisterBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
 	rsp := &webhooks.BackendOperationResponse{}
-	if err := callWebhook(driver, webhooks.DeregBackend, req, rsp); err != nil {
+	if err := w.callWebhook(driver, webhooks.DeregBackend, req, rsp); err != nil {
+		return nil, err
+	}
+	return rsp, nil
+}
+
+// CallDrainBackend calls webhook drainBackend on driver
+func (w *WebhookInvokerImpl) CallDrainBackend(driver *lbcfapi.LoadBalancerDriver, req *webhooks.BackendOperationRequest) (*webhooks.BackendOperationResponse, error) {
+	rsp := &webhooks.BackendOperationResponse{}
+	if err := w.callWebhook(driver, webhooks.DrainBackend, req, rsp); err != nil {
 		return nil, err
 	}
 	return rsp, nil
 }
 
-func callWebhook(driver *lbcfapi.LoadBalancerDriver, webHookName string, payload interface{}, rsp interface{}) error {
-	u, err := url.Parse(driver.Spec.Url)
+// driverCallConfig is built from the Secrets referenced by the driver
+type driverCallConfig struct {
+	// tls is nil if TLS is not configured
+	tls *driverTLSConfig
+
+	// credential is nil if auth is not configured
+	credential *driverCredential
+
+	// serviceAddrs are addresses to try in order if the driver is referenced by Service
+	serviceAddrs []string
+
+	// allServiceAddrs are all addresses of the Service, connections to other addresses of the Service are evicted
+	allServiceAddrs []string
+
+	// maxExecOutputBytes limits stdout and stderr of Exec drivers
+	maxExecOutputBytes int
+
+	// onExecStderr is nil if stderr of Exec drivers is not handled
+	onExecStderr func(webHookName string, stderr string)
+
+	// execDriverDir is the directory that executables of Exec drivers must be in
+	execDriverDir string
+}
+
+// callWebhook calls the webhook through the transport determined by driverType
+func callWebhook(driver *lbcfapi.LoadBalancerDriver, callCfg driverCallConfig, webHookName string, payload interface{}, rsp interface{}) error {
+	start := time.Now()
+	wireReq, err := webhooks.ConvertRequest(driver.Spec.ProtocolVersion, webHookName, payload)
+	if err != nil {
+		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", err, driver.Name, webHookName)
+		metrics.ObserveWebhookCall(driver.Namespace+"/"+driver.Name, webHookName, metrics.WebhookResultError, 0)
+		return err
+	}
+	switch lbcfapi.DriverType(driver.Spec.DriverType) {
+	case lbcfapi.GRPCDriver:
+		// protobuf messages are converted from payload, the protocol version is carried in GRPC metadata
+		err = callGRPCWebhook(driver, callCfg, webHookName, payload, rsp)
+	case lbcfapi.ExecDriver:
+		err = callExecWebhook(driver, callCfg, webHookName, wireReq, rsp)
+	default:
+		err = callHTTPWebhook(driver, callCfg, webHookName, wireReq, rsp)
+	}
+	if isNotImplementedError(err) {
+		if isWebhookOptional(driver, webHookName) && setNotImplementedResponse(rsp) {
+			klog.V(3).Infof("optional webhook %s is not implemented by driver %s, treated as success", webHookName, driver.Name)
+			metrics.ObserveWebhookCall(driver.Namespace+"/"+driver.Name, webHookName, metrics.WebhookResultNotImplemented, time.Since(start))
+			return nil
+		}
+		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", err, driver.Name, webHookName)
+	}
+	metrics.ObserveWebhookCall(driver.Namespace+"/"+driver.Name, webHookName, webhookResult(rsp, err), time.Since(start))
+	return err
+}
+
+// webhookResult returns the result label used in metrics
+func webhookResult(rsp interface{}, err error) string {
+	if err != nil {
+		return metrics.WebhookResultError
+	}
+	getter, ok := rsp.(webhooks.StatusGetter)
+	if !ok {
+		return metrics.WebhookResultInvalid
+	}
+	switch status := getter.GetStatus(); status {
+	case webhooks.StatusSucc, webhooks.StatusFail, webhooks.StatusRunning:
+		return status
+	}
+	return metrics.WebhookResultInvalid
+}
+
+func callHTTPWebhook(driver *lbcfapi.LoadBalancerDriver, callCfg driverCallConfig, webHookName string, payload interface{}, rsp interface{}) error {
+	urls, err := getWebhookURLs(driver, callCfg, webHookName)
 	if err != nil {
 		e := fmt.Errorf("invalid url: %v", err)
 		klog.Errorf("callwebhook failed: %v. driver: %s, webhookName: %s", e, driver.Name, webHookName)
 		return e
 	}
-	u.Path = path.Join(webHookName)
-	var timeout time.Duration
-	for _, h := range driver.Spec.Webhooks {
-		if h.Name == webHookName {
-			timeout = h.Timeout.Duration
-			break
+	for i, u := range urls {
+		retriable, err := postWebhook(u, driver, callCfg, webHookName, payload, rsp)
+		if err == nil || !retriable || i == len(urls)-1 {
+			return err
+		}
+		klog.Warningf("callwebhook failed, try next endpoint. driver: %s, webhookName: %s", driver.Name, webHookName)
+	}
+	return nil
+}
+
+// getWebhookURLs returns the URLs to call in order, there are more than one URL only if
+// the driver is referenced by Service and the Service has multiple ready endpoints
+func getWebhookURLs(driver *lbcfapi.LoadBalancerDriver, callCfg driverCallConfig, webHookName string) ([]*url.URL, error) {
+	if driver.Spec.Service == nil {
+		u, err := url.Parse(driver.Spec.Url)
+		if err != nil {
+			return nil, err
+		}
+		u.Path = path.Join(webHookName)
+		return []*url.URL{u}, nil
+	}
+	scheme := "http"
+	if driver.Spec.TLS != nil {
+		scheme = "https"
+	}
+	var urls []*url.URL
+	for _, addr := range callCfg.serviceAddrs {
+		urls = append(urls, &url.URL{
+			Scheme: scheme,
+			Host:   addr,
+			Path:   path.Join("/", driver.Spec.Service.Path, webHookName),
+		})
+	}
+	return urls, nil
+}
+
+// postWebhook returns true if the driver is not reached, so that the webhook can be retried on another endpoint.
+// Timeouts are not retried, otherwise a single call may take up to maxServiceEndpointAttempts times the timeout
+func postWebhook(u *url.URL, driver *lbcfapi.LoadBalancerDriver, callCfg driverCallConfig, webHookName string, payload interface{}, rsp interface{}) (bool, error) {
+	request := gorequest.New().Timeout(getWebhookTimeout(driver, webHookName))
+	if callCfg.tls != nil {
+		request = request.TLSClientConfig(callCfg.tls.config)
+	}
+	request = request.Post(u.String())
+	if callCfg.credential != nil && callCfg.credential.authType == lbcfapi.HMACAuth {
+		body, err := gorequestBody(payload)
+		if err != nil {
+			e := fmt.Errorf("encode webhook request err: %v", err)
+			klog.Errorf("callwebhook failed: %v. url: %s", e, u.String())
+			return false, e
+		}
+		request = request.Send(string(body))
+		debugInfo, _ := request.AsCurlCommand()
+		klog.V(3).Infof("callwebhook, %s", debugInfo)
+		timestamp := time.Now().Unix()
+		request = request.Set(auth.TimestampHeader, strconv.FormatInt(timestamp, 10)).
+			Set(auth.SignatureHeader, auth.Sign(callCfg.credential.value, timestamp, http.MethodPost, u.Path, body))
+	} else {
+		request = request.Send(payload)
+		debugInfo, _ := request.AsCurlCommand()
+		klog.V(3).Infof("callwebhook, %s", debugInfo)
+		if callCfg.credential != nil {
+			request = request.Set(auth.AuthorizationHeader, auth.BearerToken(string(callCfg.credential.value)))
 		}
 	}
-	request := gorequest.New().Timeout(timeout).Post(u.String()).Send(payload)
-	debugInfo, _ := request.AsCurlCommand()
-	klog.V(3).Infof("callwebhook, %s", debugInfo)
 
 	response, body, errs := request.EndBytes()
 	if len(errs) > 0 {
 		e := fmt.Errorf("webhook err: %v", errs)
 		klog.Errorf("callwebhook failed: %v. url: %s", e, u.String())
-		return e
+		retriable := false
+		for _, err := range errs {
+			retriable = retriable || isConnectionError(err)
+		}
+		return retriable, &unavailableError{e}
 	}
 	if response.StatusCode != http.StatusOK {
 		e := fmt.Errorf("http status code: %d, body: %s", response.StatusCode, body)
+		// 404 is not treated as not implemented, because it is also returned if the url is misconfigured
+		if response.StatusCode == http.StatusNotImplemented {
+			// logged by the caller, because it is not an error if the webhook is optional
+			return false, &notImplementedError{e}
+		}
 		klog.Errorf("callwebhook failed: %v. url: %s", e, u.String())
-		return e
+		if response.StatusCode >= http.StatusInternalServerError {
+			return response.StatusCode == http.StatusServiceUnavailable, &unavailableError{e}
+		}
+		return false, e
 	}
-	if err := json.Unmarshal(body, rsp); err != nil {
+	if err := webhooks.DecodeResponse(driver.Spec.ProtocolVersion, webHookName, body, rsp); err != nil {
 		e := fmt.Errorf("decode webhook response err: %v, raw: %s", err, body)
 		klog.Errorf("callwebhook failed: %v. url: %s", e, u.String())
-		return e
+		return false, e
 	}
-	return nil
+	return false, nil
+}
+
+// isConnectionError returns true if the connection to the driver can not be established, e.g. it is refused.
+// A timeout is not a connection error even if it occurs in dialing
+func isConnectionError(err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	opErr, ok := err.(*net.OpError)
+	return ok && opErr.Op == "dial" && !opErr.Timeout()
+}
+
+// notImplementedError means the driver does not implement the webhook
+type notImplementedError struct {
+	error
+}
+
+func isNotImplementedError(err error) bool {
+	_, ok := err.(*notImplementedError)
+	return ok
+}
+
+func isWebhookOptional(driver *lbcfapi.LoadBalancerDriver, webHookName string) bool {
+	for _, h := range driver.Spec.Webhooks {
+		if h.Name == webHookName {
+			return h.Optional && webhooks.OptionalWebhooks.Has(webHookName)
+		}
+	}
+	return false
+}
+
+// setNotImplementedResponse sets rsp to a successful response, it returns false if the webhook can not be optional
+func setNotImplementedResponse(rsp interface{}) bool {
+	msg := "webhook not implemented"
+	switch r := rsp.(type) {
+	case *webhooks.ValidateLoadBalancerResponse:
+		r.Succ = true
+		r.Msg = msg
+	case *webhooks.ValidateBackendResponse:
+		r.Succ = true
+		r.Msg = msg
+	case *webhooks.EnsureLoadBalancerResponse:
+		r.Status = webhooks.StatusSucc
+		r.Msg = msg
+	default:
+		return false
+	}
+	return true
+}
+
+func getWebhookTimeout(driver *lbcfapi.LoadBalancerDriver, webHookName string) time.Duration {
+	for _, h := range driver.Spec.Webhooks {
+		if h.Name == webHookName {
+			return h.Timeout.Duration
+		}
+	}
+	return 0
 }
@@ -21,13 +21,17 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	lbcfapi "tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/metrics"
 	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -252,6 +256,176 @@ func TestWebhookHttpErr(t *testing.T) {
 	}
 }
 
+func TestWebhookOptional(t *testing.T) {
+	invoker := NewWebhookInvoker()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "not implemented", http.StatusNotImplemented)
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	driver := fakeMockDriver(u, 500*time.Millisecond)
+	for i := range driver.Spec.Webhooks {
+		driver.Spec.Webhooks[i].Optional = true
+	}
+
+	if rsp, err := invoker.CallValidateLoadBalancer(driver, &webhooks.ValidateLoadBalancerRequest{}); err != nil {
+		t.Errorf("expect no error, get %v", err)
+	} else if !rsp.Succ {
+		t.Errorf("expect succ")
+	}
+	if rsp, err := invoker.CallEnsureLoadBalancer(driver, &webhooks.EnsureLoadBalancerRequest{}); err != nil {
+		t.Errorf("expect no error, get %v", err)
+	} else if rsp.Status != webhooks.StatusSucc {
+		t.Errorf("expect status %s, get %s", webhooks.StatusSucc, rsp.Status)
+	}
+	// ensureBackend, createLoadBalancer and deleteLoadBalancer can not be optional
+	if _, err := invoker.CallEnsureBackend(driver, &webhooks.BackendOperationRequest{}); err == nil {
+		t.Errorf("expect error")
+	}
+	if _, err := invoker.CallCreateLoadBalancer(driver, &webhooks.CreateLoadBalancerRequest{}); err == nil {
+		t.Errorf("expect error")
+	}
+	if _, err := invoker.CallDeleteLoadBalancer(driver, &webhooks.DeleteLoadBalancerRequest{}); err == nil {
+		t.Errorf("expect error")
+	}
+
+	// 404 is not treated as not implemented
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	u, _ = url.Parse(notFound.URL)
+	driver = fakeMockDriver(u, 500*time.Millisecond)
+	for i := range driver.Spec.Webhooks {
+		driver.Spec.Webhooks[i].Optional = true
+	}
+	if _, err := invoker.CallValidateLoadBalancer(driver, &webhooks.ValidateLoadBalancerRequest{}); err == nil {
+		t.Errorf("expect error for http status 404")
+	}
+}
+
+func TestWebhookProtocolVersion(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = make(map[string]interface{})
+		json.NewDecoder(r.Body).Decode(&received)
+		w.Write([]byte(`{"succ":true}`))
+	}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	driver := fakeMockDriver(u, 10*time.Second)
+	invoker := NewWebhookInvoker()
+
+	if _, err := invoker.CallValidateLoadBalancer(driver, &webhooks.ValidateLoadBalancerRequest{}); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	} else if _, ok := received["protocolVersion"]; ok {
+		t.Fatalf("expect no protocolVersion in %s request, get %v", webhooks.ProtocolV1, received)
+	}
+
+	driver.Spec.ProtocolVersion = webhooks.ProtocolV2
+	if _, err := invoker.CallValidateLoadBalancer(driver, &webhooks.ValidateLoadBalancerRequest{}); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	} else if received["protocolVersion"] != webhooks.ProtocolV2 {
+		t.Fatalf("expect protocolVersion %s, get %v", webhooks.ProtocolV2, received)
+	}
+
+	driver.Spec.ProtocolVersion = "v100"
+	if _, err := invoker.CallValidateLoadBalancer(driver, &webhooks.ValidateLoadBalancerRequest{}); err == nil {
+		t.Fatalf("expect error for unsupported protocol version")
+	}
+}
+
+func TestWebhookConcurrencyLimit(t *testing.T) {
+	invoker := NewWebhookInvokerWithConfig(WebhookInvokerConfig{MaxConcurrentCallsPerDriver: 2})
+
+	var inflight, maxInflight int32
+	slowRun := func(rsp http.ResponseWriter, req *http.Request) {
+		cur := atomic.AddInt32(&inflight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInflight)
+			if cur <= max || atomic.CompareAndSwapInt32(&maxInflight, max, cur) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&inflight, -1)
+		succRun(rsp, req)
+	}
+	server := newMockServer(succValidate, slowRun)
+	u, err := server.start()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	driver := fakeMockDriver(u, 10*time.Second)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := invoker.CallEnsureBackend(driver, &webhooks.BackendOperationRequest{}); err != nil {
+				t.Errorf("CallEnsureBackend failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if maxInflight > 2 {
+		t.Errorf("expect at most 2 in-flight calls, get %d", maxInflight)
+	}
+
+	driverKey := driver.Namespace + "/" + driver.Name
+	invoker.ForgetDriver(driverKey)
+	if _, ok := invoker.(*WebhookInvokerImpl).driverSlots[driverKey]; ok {
+		t.Errorf("expect slots of driver %s dropped", driverKey)
+	}
+}
+
+func TestWebhookResult(t *testing.T) {
+	cases := []struct {
+		name   string
+		rsp    interface{}
+		err    error
+		expect string
+	}{
+		{
+			name:   "error",
+			rsp:    &webhooks.BackendOperationResponse{},
+			err:    fmt.Errorf("fake error"),
+			expect: metrics.WebhookResultError,
+		},
+		{
+			name: "retry-hook-running",
+			rsp: &webhooks.BackendOperationResponse{
+				ResponseForFailRetryHooks: webhooks.ResponseForFailRetryHooks{Status: webhooks.StatusRunning},
+			},
+			expect: webhooks.StatusRunning,
+		},
+		{
+			name: "retry-hook-unknown-status",
+			rsp: &webhooks.CreateLoadBalancerResponse{
+				ResponseForFailRetryHooks: webhooks.ResponseForFailRetryHooks{Status: "unknown"},
+			},
+			expect: metrics.WebhookResultInvalid,
+		},
+		{
+			name: "no-retry-hook-succ",
+			rsp: &webhooks.ValidateLoadBalancerResponse{
+				ResponseForNoRetryHooks: webhooks.ResponseForNoRetryHooks{Succ: true},
+			},
+			expect: webhooks.StatusSucc,
+		},
+		{
+			name:   "no-retry-hook-fail",
+			rsp:    &webhooks.ValidateBackendResponse{},
+			expect: webhooks.StatusFail,
+		},
+	}
+	for _, c := range cases {
+		if get := webhookResult(c.rsp, c.err); get != c.expect {
+			t.Errorf("case %s, expect %s, get %s", c.name, c.expect, get)
+		}
+	}
+}
+
 func succValidate(rsp http.ResponseWriter, req *http.Request) {
 	body := &webhooks.ResponseForNoRetryHooks{
 		Succ: true,
@@ -0,0 +1,207 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package auth signs webhook requests sent by LBCF, and helps drivers to verify them.
+//
+// A driver configured with BearerToken auth receives "Authorization: Bearer <token>",
+// or metadata "authorization" for GRPC drivers.
+// A driver configured with HMAC auth receives headers X-Lbcf-Timestamp and X-Lbcf-Signature,
+// the signature is hex(HMAC-SHA256(key, timestamp + "\n" + method + "\n" + path + "\n" + body)),
+// so that a captured request can not be replayed to another webhook.
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// AuthorizationHeader carries the bearer token
+	AuthorizationHeader = "Authorization"
+
+	// TimestampHeader carries the unix timestamp in seconds when the request is signed
+	TimestampHeader = "X-Lbcf-Timestamp"
+
+	// SignatureHeader carries the HMAC signature of the request
+	SignatureHeader = "X-Lbcf-Signature"
+
+	// DefaultMaxClockSkew is the default max difference between the signing time and the verifying time
+	DefaultMaxClockSkew = 5 * time.Minute
+
+	bearerPrefix = "Bearer "
+)
+
+// BearerToken returns the value of Authorization header
+func BearerToken(token string) string {
+	return bearerPrefix + token
+}
+
+// Sign returns the HMAC signature of the request with method, url path and body signed at timestamp
+func Sign(key []byte, timestamp int64, method string, path string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("\n" + method + "\n" + path + "\n"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyBearerToken returns an error if the request does not carry the expected token
+func VerifyBearerToken(r *http.Request, token string) error {
+	return verifyToken(r.Header.Get(AuthorizationHeader), token)
+}
+
+// VerifyGRPCBearerToken returns an error if the incoming gRPC call does not carry the expected token
+func VerifyGRPCBearerToken(ctx context.Context, token string) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return fmt.Errorf("missing metadata")
+	}
+	values := md.Get(strings.ToLower(AuthorizationHeader))
+	if len(values) == 0 {
+		return fmt.Errorf("missing %s", AuthorizationHeader)
+	}
+	return verifyToken(values[0], token)
+}
+
+// VerifyHMAC returns an error if the signature of r with body is invalid, or the request is signed more than maxClockSkew ago
+func VerifyHMAC(r *http.Request, body []byte, key []byte, maxClockSkew time.Duration) error {
+	timestamp, err := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %v", TimestampHeader, err)
+	}
+	skew := time.Since(time.Unix(timestamp, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > maxClockSkew {
+		return fmt.Errorf("request signed at %d is out of the allowed clock skew %s", timestamp, maxClockSkew.String())
+	}
+	expect := Sign(key, timestamp, r.Method, r.URL.Path, body)
+	if !hmac.Equal([]byte(expect), []byte(r.Header.Get(SignatureHeader))) {
+		return fmt.Errorf("invalid %s", SignatureHeader)
+	}
+	return nil
+}
+
+// BearerTokenHandler wraps next, requests without the expected token are rejected with 401
+func BearerTokenHandler(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := VerifyBearerToken(r, token); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// HMACHandler wraps next, requests with invalid signature are rejected with 401.
+// The request body is restored before calling next.
+func HMACHandler(key []byte, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := VerifyHMAC(r, body, key, DefaultMaxClockSkew); err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// BearerTokenRoundTripper wraps next, requests are sent with the token in the same way as LBCF does.
+// http.DefaultTransport is used if next is nil
+func BearerTokenRoundTripper(token string, next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		r = cloneRequest(r)
+		r.Header.Set(AuthorizationHeader, BearerToken(token))
+		return defaultRoundTripper(next).RoundTrip(r)
+	})
+}
+
+// HMACRoundTripper wraps next, requests are signed with key in the same way as LBCF does.
+// http.DefaultTransport is used if next is nil
+func HMACRoundTripper(key []byte, next http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		var body []byte
+		if r.Body != nil {
+			var err error
+			body, err = ioutil.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				return nil, err
+			}
+		}
+		r = cloneRequest(r)
+		if r.Body != nil {
+			r.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		timestamp := time.Now().Unix()
+		r.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+		r.Header.Set(SignatureHeader, Sign(key, timestamp, r.Method, r.URL.Path, body))
+		return defaultRoundTripper(next).RoundTrip(r)
+	})
+}
+
+type roundTripperFunc func(r *http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func defaultRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		return http.DefaultTransport
+	}
+	return rt
+}
+
+// cloneRequest returns a shallow copy of r with a deep copy of the headers, a RoundTripper must not modify r
+func cloneRequest(r *http.Request) *http.Request {
+	clone := new(http.Request)
+	*clone = *r
+	clone.Header = make(http.Header, len(r.Header))
+	for k, v := range r.Header {
+		clone.Header[k] = append([]string(nil), v...)
+	}
+	return clone
+}
+
+func verifyToken(header string, token string) error {
+	if !strings.HasPrefix(header, bearerPrefix) {
+		return fmt.Errorf("missing bearer token")
+	}
+	if subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(header, bearerPrefix)), []byte(token)) != 1 {
+		return fmt.Errorf("invalid bearer token")
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package auth
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestVerifyBearerToken(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/ensureBackend", nil)
+	if err := VerifyBearerToken(r, "token"); err == nil {
+		t.Fatalf("expect error without token")
+	}
+	r.Header.Set(AuthorizationHeader, BearerToken("wrong"))
+	if err := VerifyBearerToken(r, "token"); err == nil {
+		t.Fatalf("expect error with wrong token")
+	}
+	r.Header.Set(AuthorizationHeader, BearerToken("token"))
+	if err := VerifyBearerToken(r, "token"); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+}
+
+func TestVerifyGRPCBearerToken(t *testing.T) {
+	if err := VerifyGRPCBearerToken(context.Background(), "token"); err == nil {
+		t.Fatalf("expect error without metadata")
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", BearerToken("token")))
+	if err := VerifyGRPCBearerToken(ctx, "token"); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+}
+
+func TestVerifyHMAC(t *testing.T) {
+	key := []byte("key")
+	body := []byte(`{"recordName":"fake"}`)
+	now := time.Now().Unix()
+
+	r := httptest.NewRequest(http.MethodPost, "/ensureBackend", nil)
+	r.Header.Set(TimestampHeader, strconv.FormatInt(now, 10))
+	r.Header.Set(SignatureHeader, Sign(key, now, http.MethodPost, "/ensureBackend", body))
+	if err := VerifyHMAC(r, body, key, DefaultMaxClockSkew); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	replayed := httptest.NewRequest(http.MethodPost, "/deregisterBackend", nil)
+	replayed.Header = r.Header
+	if err := VerifyHMAC(replayed, body, key, DefaultMaxClockSkew); err == nil {
+		t.Fatalf("expect error for request replayed to another path")
+	}
+	replayed = httptest.NewRequest(http.MethodPut, "/ensureBackend", nil)
+	replayed.Header = r.Header
+	if err := VerifyHMAC(replayed, body, key, DefaultMaxClockSkew); err == nil {
+		t.Fatalf("expect error for request replayed with another method")
+	}
+	if err := VerifyHMAC(r, []byte(`{"recordName":"modified"}`), key, DefaultMaxClockSkew); err == nil {
+		t.Fatalf("expect error for modified body")
+	}
+	if err := VerifyHMAC(r, body, []byte("wrong"), DefaultMaxClockSkew); err == nil {
+		t.Fatalf("expect error for wrong key")
+	}
+
+	expired := now - int64((2 * DefaultMaxClockSkew).Seconds())
+	r.Header.Set(TimestampHeader, strconv.FormatInt(expired, 10))
+	r.Header.Set(SignatureHeader, Sign(key, expired, http.MethodPost, "/ensureBackend", body))
+	if err := VerifyHMAC(r, body, key, DefaultMaxClockSkew); err == nil {
+		t.Fatalf("expect error for expired timestamp")
+	}
+}
+
+func TestHMACHandler(t *testing.T) {
+	key := []byte("key")
+	body := `{"recordName":"fake"}`
+	var received string
+	h := HMACHandler(key, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		received = string(b)
+	}))
+
+	now := time.Now().Unix()
+	r := httptest.NewRequest(http.MethodPost, "/ensureBackend", strings.NewReader(body))
+	r.Header.Set(TimestampHeader, strconv.FormatInt(now, 10))
+	r.Header.Set(SignatureHeader, Sign(key, now, http.MethodPost, "/ensureBackend", []byte(body)))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expect 200, get %d", w.Code)
+	} else if received != body {
+		t.Fatalf("expect body restored, get %s", received)
+	}
+
+	r = httptest.NewRequest(http.MethodPost, "/ensureBackend", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("expect 401, get %d", w.Code)
+	}
+}
+
+func TestRoundTripper(t *testing.T) {
+	body := `{"recordName":"fake"}`
+	var received string
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		received = string(b)
+	})
+	cases := []struct {
+		name    string
+		handler http.Handler
+		rt      http.RoundTripper
+		expect  int
+	}{
+		{
+			name:    "bearer-token",
+			handler: BearerTokenHandler("token", echo),
+			rt:      BearerTokenRoundTripper("token", nil),
+			expect:  http.StatusOK,
+		},
+		{
+			name:    "bearer-token-invalid",
+			handler: BearerTokenHandler("token", echo),
+			rt:      BearerTokenRoundTripper("another", nil),
+			expect:  http.StatusUnauthorized,
+		},
+		{
+			name:    "hmac",
+			handler: HMACHandler([]byte("key"), echo),
+			rt:      HMACRoundTripper([]byte("key"), nil),
+			expect:  http.StatusOK,
+		},
+		{
+			name:    "hmac-invalid",
+			handler: HMACHandler([]byte("key"), echo),
+			rt:      HMACRoundTripper([]byte("another"), nil),
+			expect:  http.StatusUnauthorized,
+		},
+	}
+	for _, c := range cases {
+		received = ""
+		server := httptest.NewServer(c.handler)
+		client := &http.Client{Transport: c.rt}
+		rsp, err := client.Post(server.URL+"/ensureBackend", "application/json", strings.NewReader(body))
+		server.Close()
+		if err != nil {
+			t.Fatalf("case %s, unexpected err: %v", c.name, err)
+		}
+		rsp.Body.Close()
+		if rsp.StatusCode != c.expect {
+			t.Fatalf("case %s, expect %d, get %d", c.name, c.expect, rsp.StatusCode)
+		} else if c.expect == http.StatusOK && received != body {
+			t.Fatalf("case %s, expect body %s, get %s", c.name, body, received)
+		}
+	}
+}
@@ -0,0 +1,194 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package driverpb
+
+import (
+	"encoding/json"
+
+	"tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks"
+)
+
+// FromValidateLoadBalancerRequest converts webhooks.ValidateLoadBalancerRequest to ValidateLoadBalancerRequest
+func FromValidateLoadBalancerRequest(in *webhooks.ValidateLoadBalancerRequest) *ValidateLoadBalancerRequest {
+	return &ValidateLoadBalancerRequest{
+		LbSpec:        in.LBSpec,
+		Operation:     string(in.Operation),
+		Attributes:    in.Attributes,
+		OldAttributes: in.OldAttributes,
+	}
+}
+
+// ToValidateLoadBalancerResponse converts ValidateLoadBalancerResponse to webhooks.ValidateLoadBalancerResponse
+func ToValidateLoadBalancerResponse(in *ValidateLoadBalancerResponse) *webhooks.ValidateLoadBalancerResponse {
+	out := &webhooks.ValidateLoadBalancerResponse{}
+	out.Succ = in.Succ
+	out.Msg = in.Msg
+	return out
+}
+
+// FromCreateLoadBalancerRequest converts webhooks.CreateLoadBalancerRequest to CreateLoadBalancerRequest
+func FromCreateLoadBalancerRequest(in *webhooks.CreateLoadBalancerRequest) *CreateLoadBalancerRequest {
+	return &CreateLoadBalancerRequest{
+		RecordId:   in.RecordID,
+		RetryId:    in.RetryID,
+		LbSpec:     in.LBSpec,
+		Attributes: in.Attributes,
+	}
+}
+
+// ToCreateLoadBalancerResponse converts CreateLoadBalancerResponse to webhooks.CreateLoadBalancerResponse
+func ToCreateLoadBalancerResponse(in *CreateLoadBalancerResponse) *webhooks.CreateLoadBalancerResponse {
+	out := &webhooks.CreateLoadBalancerResponse{}
+	out.ResponseForFailRetryHooks = toFailRetryResponse(in.Status, in.Msg, in.MinRetryDelayInSeconds)
+	out.LBInfo = in.LbInfo
+	return out
+}
+
+// FromEnsureLoadBalancerRequest converts webhooks.EnsureLoadBalancerRequest to EnsureLoadBalancerRequest
+func FromEnsureLoadBalancerRequest(in *webhooks.EnsureLoadBalancerRequest) *EnsureLoadBalancerRequest {
+	return &EnsureLoadBalancerRequest{
+		RecordId:   in.RecordID,
+		RetryId:    in.RetryID,
+		LbInfo:     in.LBInfo,
+		Attributes: in.Attributes,
+	}
+}
+
+// ToEnsureLoadBalancerResponse converts EnsureLoadBalancerResponse to webhooks.EnsureLoadBalancerResponse
+func ToEnsureLoadBalancerResponse(in *EnsureLoadBalancerResponse) *webhooks.EnsureLoadBalancerResponse {
+	out := &webhooks.EnsureLoadBalancerResponse{}
+	out.ResponseForFailRetryHooks = toFailRetryResponse(in.Status, in.Msg, in.MinRetryDelayInSeconds)
+	return out
+}
+
+// FromDeleteLoadBalancerRequest converts webhooks.DeleteLoadBalancerRequest to DeleteLoadBalancerRequest
+func FromDeleteLoadBalancerRequest(in *webhooks.DeleteLoadBalancerRequest) *DeleteLoadBalancerRequest {
+	return &DeleteLoadBalancerRequest{
+		RecordId:   in.RecordID,
+		RetryId:    in.RetryID,
+		LbInfo:     in.LBInfo,
+		Attributes: in.Attributes,
+	}
+}
+
+// ToDeleteLoadBalancerResponse converts DeleteLoadBalancerResponse to webhooks.DeleteLoadBalancerResponse
+func ToDeleteLoadBalancerResponse(in *DeleteLoadBalancerResponse) *webhooks.DeleteLoadBalancerResponse {
+	out := &webhooks.DeleteLoadBalancerResponse{}
+	out.ResponseForFailRetryHooks = toFailRetryResponse(in.Status, in.Msg, in.MinRetryDelayInSeconds)
+	return out
+}
+
+// FromValidateBackendRequest converts webhooks.ValidateBackendRequest to ValidateBackendRequest
+func FromValidateBackendRequest(in *webhooks.ValidateBackendRequest) *ValidateBackendRequest {
+	return &ValidateBackendRequest{
+		BackendType:   in.BackendType,
+		LbInfo:        in.LBInfo,
+		Operation:     string(in.Operation),
+		Parameters:    in.Parameters,
+		OldParameters: in.OldParameters,
+	}
+}
+
+// ToValidateBackendResponse converts ValidateBackendResponse to webhooks.ValidateBackendResponse
+func ToValidateBackendResponse(in *ValidateBackendResponse) *webhooks.ValidateBackendResponse {
+	out := &webhooks.ValidateBackendResponse{}
+	out.Succ = in.Succ
+	out.Msg = in.Msg
+	return out
+}
+
+// FromGenerateBackendAddrRequest converts webhooks.GenerateBackendAddrRequest to GenerateBackendAddrRequest
+func FromGenerateBackendAddrRequest(in *webhooks.GenerateBackendAddrRequest) (*GenerateBackendAddrRequest, error) {
+	out := &GenerateBackendAddrRequest{
+		RecordId:     in.RecordID,
+		RetryId:      in.RetryID,
+		LbInfo:       in.LBInfo,
+		LbAttributes: in.LBAttributes,
+		Parameters:   in.Parameters,
+	}
+	if in.PodBackend != nil {
+		pod, err := json.Marshal(&in.PodBackend.Pod)
+		if err != nil {
+			return nil, err
+		}
+		out.PodBackend = &PodBackend{
+			Pod: pod,
+			Port: &PortSelector{
+				PortNumber: in.PodBackend.Port.PortNumber,
+				Protocol:   in.PodBackend.Port.Protocol,
+			},
+		}
+	}
+	if in.ServiceBackend != nil {
+		svc, err := json.Marshal(&in.ServiceBackend.Service)
+		if err != nil {
+			return nil, err
+		}
+		out.ServiceBackend = &ServiceBackend{
+			Service: svc,
+			Port: &PortSelector{
+				PortNumber: in.ServiceBackend.Port.PortNumber,
+				Protocol:   in.ServiceBackend.Port.Protocol,
+			},
+			NodeName: in.ServiceBackend.NodeName,
+		}
+		for _, addr := range in.ServiceBackend.NodeAddresses {
+			out.ServiceBackend.NodeAddresses = append(out.ServiceBackend.NodeAddresses, &NodeAddress{
+				Type:    string(addr.Type),
+				Address: addr.Address,
+			})
+		}
+	}
+	return out, nil
+}
+
+// ToGenerateBackendAddrResponse converts GenerateBackendAddrResponse to webhooks.GenerateBackendAddrResponse
+func ToGenerateBackendAddrResponse(in *GenerateBackendAddrResponse) *webhooks.GenerateBackendAddrResponse {
+	out := &webhooks.GenerateBackendAddrResponse{}
+	out.ResponseForFailRetryHooks = toFailRetryResponse(in.Status, in.Msg, in.MinRetryDelayInSeconds)
+	out.BackendAddr = in.BackendAddr
+	return out
+}
+
+// FromBackendOperationRequest converts webhooks.BackendOperationRequest to BackendOperationRequest
+func FromBackendOperationRequest(in *webhooks.BackendOperationRequest) *BackendOperationRequest {
+	return &BackendOperationRequest{
+		RecordId:     in.RecordID,
+		RetryId:      in.RetryID,
+		LbInfo:       in.LBInfo,
+		BackendAddr:  in.BackendAddr,
+		Parameters:   in.Parameters,
+		InjectedInfo: in.InjectedInfo,
+	}
+}
+
+// ToBackendOperationResponse converts BackendOperationResponse to webhooks.BackendOperationResponse
+func ToBackendOperationResponse(in *BackendOperationResponse) *webhooks.BackendOperationResponse {
+	out := &webhooks.BackendOperationResponse{}
+	out.ResponseForFailRetryHooks = toFailRetryResponse(in.Status, in.Msg, in.MinRetryDelayInSeconds)
+	out.InjectedInfo = in.InjectedInfo
+	return out
+}
+
+func toFailRetryResponse(status string, msg string, minRetryDelayInSeconds int32) webhooks.ResponseForFailRetryHooks {
+	return webhooks.ResponseForFailRetryHooks{
+		Status:                 status,
+		Msg:                    msg,
+		MinRetryDelayInSeconds: minRetryDelayInSeconds,
+	}
+}
@@ -0,0 +1,29 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package driverpb contains the protobuf messages and gRPC service of drivers whose driverType is GRPC.
+//
+// driver.pb.go is generated from driver.proto by protoc-gen-go of github.com/golang/protobuf pinned in go.mod,
+// run `make gen.proto` or `go generate` in this directory after driver.proto is modified, protoc must be installed.
+package driverpb
+
+//go:generate make -C ../../../.. gen.proto
+
+const (
+	// ServiceName is the full name of gRPC service Driver
+	ServiceName = "lbcf.driver.v1beta1.Driver"
+)
@@ -0,0 +1,303 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+package webhooks
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+	webhooksv1 "tkestack.io/lb-controlling-framework/pkg/lbcfcontroller/webhooks/v1"
+)
+
+const (
+	// ProtocolV1 is the protocol of drivers that do not declare protocolVersion, requests carry no version
+	ProtocolV1 = "v1"
+
+	// ProtocolV2 is the same as ProtocolV1 except that protocolVersion is carried in all requests
+	ProtocolV2 = "v2"
+
+	// DefaultProtocolVersion is used if protocolVersion is not specified in LoadBalancerDriverSpec
+	DefaultProtocolVersion = ProtocolV1
+
+	// LatestProtocolVersion is the protocol version of the request and response types defined in this package
+	LatestProtocolVersion = ProtocolV2
+
+	// ProtocolVersionMetadataKey is the GRPC metadata key that carries the protocol version since ProtocolV2
+	ProtocolVersionMetadataKey = "lbcf-protocol-version"
+)
+
+// protocolConverter converts requests and responses between LatestProtocolVersion and the wire format of a specific protocol version
+type protocolConverter struct {
+	// convertRequest returns a new request in the wire format, req is not modified
+	convertRequest func(req interface{}) (interface{}, error)
+
+	// decodeResponse decodes a response in the wire format into rsp
+	decodeResponse func(data []byte, rsp interface{}) error
+}
+
+var protocolConverters = map[string]protocolConverter{
+	ProtocolV1: {
+		convertRequest: convertRequestToV1,
+		decodeResponse: decodeResponseFromV1,
+	},
+	ProtocolV2: {
+		convertRequest: convertRequestToV2,
+		decodeResponse: json.Unmarshal,
+	},
+}
+
+// SupportedProtocolVersions returns all protocol versions that can be declared in LoadBalancerDriverSpec
+func SupportedProtocolVersions() []string {
+	var versions []string
+	for v := range protocolConverters {
+		versions = append(versions, v)
+	}
+	sort.Strings(versions)
+	return versions
+}
+
+// IsProtocolVersionSupported returns true if version is supported, an empty version means DefaultProtocolVersion
+func IsProtocolVersionSupported(version string) bool {
+	if version == "" {
+		return true
+	}
+	_, ok := protocolConverters[version]
+	return ok
+}
+
+func getProtocolConverter(version string) (protocolConverter, error) {
+	if version == "" {
+		version = DefaultProtocolVersion
+	}
+	converter, ok := protocolConverters[version]
+	if !ok {
+		return protocolConverter{}, fmt.Errorf("unsupported protocol version %q", version)
+	}
+	return converter, nil
+}
+
+// ConvertRequest converts req, which is of LatestProtocolVersion, to a new request in the wire format of version.
+// req is not modified. An empty version means DefaultProtocolVersion
+func ConvertRequest(version string, webHookName string, req interface{}) (interface{}, error) {
+	converter, err := getProtocolConverter(version)
+	if err != nil {
+		return nil, err
+	}
+	out, err := converter.convertRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("convert request of webhook %s to protocol %s failed: %v", webHookName, version, err)
+	}
+	return out, nil
+}
+
+// DecodeResponse decodes data, which is a response in the wire format of version, into rsp of LatestProtocolVersion.
+// An empty version means DefaultProtocolVersion
+func DecodeResponse(version string, webHookName string, data []byte, rsp interface{}) error {
+	converter, err := getProtocolConverter(version)
+	if err != nil {
+		return err
+	}
+	return converter.decodeResponse(data, rsp)
+}
+
+func convertRequestToV2(req interface{}) (interface{}, error) {
+	switch in := req.(type) {
+	case *ValidateLoadBalancerRequest:
+		out := *in
+		out.ProtocolVersion = ProtocolV2
+		return &out, nil
+	case *CreateLoadBalancerRequest:
+		out := *in
+		out.ProtocolVersion = ProtocolV2
+		return &out, nil
+	case *EnsureLoadBalancerRequest:
+		out := *in
+		out.ProtocolVersion = ProtocolV2
+		return &out, nil
+	case *DeleteLoadBalancerRequest:
+		out := *in
+		out.ProtocolVersion = ProtocolV2
+		return &out, nil
+	case *ValidateBackendRequest:
+		out := *in
+		out.ProtocolVersion = ProtocolV2
+		return &out, nil
+	case *GenerateBackendAddrRequest:
+		out := *in
+		out.ProtocolVersion = ProtocolV2
+		return &out, nil
+	case *BackendOperationRequest:
+		out := *in
+		out.ProtocolVersion = ProtocolV2
+		return &out, nil
+	}
+	return nil, fmt.Errorf("unknown request type %T", req)
+}
+
+func convertRequestToV1(req interface{}) (interface{}, error) {
+	switch in := req.(type) {
+	case *ValidateLoadBalancerRequest:
+		return &webhooksv1.ValidateLoadBalancerRequest{
+			LBSpec:        in.LBSpec,
+			Operation:     webhooksv1.OperationType(in.Operation),
+			Attributes:    in.Attributes,
+			OldAttributes: in.OldAttributes,
+		}, nil
+	case *CreateLoadBalancerRequest:
+		return &webhooksv1.CreateLoadBalancerRequest{
+			RequestForRetryHooks: retryRequestToV1(in.RequestForRetryHooks),
+			LBSpec:               in.LBSpec,
+			Attributes:           in.Attributes,
+		}, nil
+	case *EnsureLoadBalancerRequest:
+		return &webhooksv1.EnsureLoadBalancerRequest{
+			RequestForRetryHooks: retryRequestToV1(in.RequestForRetryHooks),
+			LBInfo:               in.LBInfo,
+			Attributes:           in.Attributes,
+		}, nil
+	case *DeleteLoadBalancerRequest:
+		return &webhooksv1.DeleteLoadBalancerRequest{
+			RequestForRetryHooks: retryRequestToV1(in.RequestForRetryHooks),
+			LBInfo:               in.LBInfo,
+			Attributes:           in.Attributes,
+		}, nil
+	case *ValidateBackendRequest:
+		return &webhooksv1.ValidateBackendRequest{
+			BackendType:   in.BackendType,
+			LBInfo:        in.LBInfo,
+			Operation:     webhooksv1.OperationType(in.Operation),
+			Parameters:    in.Parameters,
+			OldParameters: in.OldParameters,
+		}, nil
+	case *GenerateBackendAddrRequest:
+		out := &webhooksv1.GenerateBackendAddrRequest{
+			RequestForRetryHooks: retryRequestToV1(in.RequestForRetryHooks),
+			LBInfo:               in.LBInfo,
+			LBAttributes:         in.LBAttributes,
+			Parameters:           in.Parameters,
+		}
+		if in.PodBackend != nil {
+			out.PodBackend = &webhooksv1.PodBackendInGenerateAddrRequest{
+				Pod:  in.PodBackend.Pod,
+				Port: portSelectorToV1(in.PodBackend.Port),
+			}
+		}
+		if in.ServiceBackend != nil {
+			out.ServiceBackend = &webhooksv1.ServiceBackendInGenerateAddrRequest{
+				Service:       in.ServiceBackend.Service,
+				Port:          portSelectorToV1(in.ServiceBackend.Port),
+				NodeName:      in.ServiceBackend.NodeName,
+				NodeAddresses: in.ServiceBackend.NodeAddresses,
+			}
+		}
+		return out, nil
+	case *BackendOperationRequest:
+		return &webhooksv1.BackendOperationRequest{
+			RequestForRetryHooks: retryRequestToV1(in.RequestForRetryHooks),
+			LBInfo:               in.LBInfo,
+			BackendAddr:          in.BackendAddr,
+			Parameters:           in.Parameters,
+			InjectedInfo:         in.InjectedInfo,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown request type %T", req)
+}
+
+func retryRequestToV1(in RequestForRetryHooks) webhooksv1.RequestForRetryHooks {
+	return webhooksv1.RequestForRetryHooks{
+		RecordID: in.RecordID,
+		RetryID:  in.RetryID,
+	}
+}
+
+func portSelectorToV1(in v1beta1.PortSelector) webhooksv1.PortSelector {
+	return webhooksv1.PortSelector{
+		PortNumber: in.PortNumber,
+		Protocol:   in.Protocol,
+	}
+}
+
+func decodeResponseFromV1(data []byte, rsp interface{}) error {
+	switch out := rsp.(type) {
+	case *ValidateLoadBalancerResponse:
+		in := &webhooksv1.ValidateLoadBalancerResponse{}
+		if err := json.Unmarshal(data, in); err != nil {
+			return err
+		}
+		out.ResponseForNoRetryHooks = noRetryResponseFromV1(in.ResponseForNoRetryHooks)
+	case *CreateLoadBalancerResponse:
+		in := &webhooksv1.CreateLoadBalancerResponse{}
+		if err := json.Unmarshal(data, in); err != nil {
+			return err
+		}
+		out.ResponseForFailRetryHooks = failRetryResponseFromV1(in.ResponseForFailRetryHooks)
+		out.LBInfo = in.LBInfo
+	case *EnsureLoadBalancerResponse:
+		in := &webhooksv1.EnsureLoadBalancerResponse{}
+		if err := json.Unmarshal(data, in); err != nil {
+			return err
+		}
+		out.ResponseForFailRetryHooks = failRetryResponseFromV1(in.ResponseForFailRetryHooks)
+	case *DeleteLoadBalancerResponse:
+		in := &webhooksv1.DeleteLoadBalancerResponse{}
+		if err := json.Unmarshal(data, in); err != nil {
+			return err
+		}
+		out.ResponseForFailRetryHooks = failRetryResponseFromV1(in.ResponseForFailRetryHooks)
+	case *ValidateBackendResponse:
+		in := &webhooksv1.ValidateBackendResponse{}
+		if err := json.Unmarshal(data, in); err != nil {
+			return err
+		}
+		out.ResponseForNoRetryHooks = noRetryResponseFromV1(in.ResponseForNoRetryHooks)
+	case *GenerateBackendAddrResponse:
+		in := &webhooksv1.GenerateBackendAddrResponse{}
+		if err := json.Unmarshal(data, in); err != nil {
+			return err
+		}
+		out.ResponseForFailRetryHooks = failRetryResponseFromV1(in.ResponseForFailRetryHooks)
+		out.BackendAddr = in.BackendAddr
+	case *BackendOperationResponse:
+		in := &webhooksv1.BackendOperationResponse{}
+		if err := json.Unmarshal(data, in); err != nil {
+			return err
+		}
+		out.ResponseForFailRetryHooks = failRetryResponseFromV1(in.ResponseForFailRetryHooks)
+		out.InjectedInfo = in.InjectedInfo
+	default:
+		return fmt.Errorf("unknown response type %T", rsp)
+	}
+	return nil
+}
+
+func noRetryResponseFromV1(in webhooksv1.ResponseForNoRetryHooks) ResponseForNoRetryHooks {
+	return ResponseForNoRetryHooks{
+		Succ: in.Succ,
+		Msg:  in.Msg,
+	}
+}
+
+func failRetryResponseFromV1(in webhooksv1.ResponseForFailRetryHooks) ResponseForFailRetryHooks {
+	return ResponseForFailRetryHooks{
+		Status:                 in.Status,
+		Msg:                    in.Msg,
+		MinRetryDelayInSeconds: in.MinRetryDelayInSeconds,
+	}
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright 2019 THL A29 Limited, a Tencent company.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package webhooks
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"tkestack.io/lb-controlling-framework/pkg/apis/lbcf.tkestack.io/v1beta1"
+)
+
+func TestConvertRequest(t *testing.T) {
+	cases := []struct {
+		name          string
+		version       string
+		expectVersion string
+		expectErr     bool
+	}{
+		{
+			name:          "default",
+			version:       "",
+			expectVersion: "",
+		},
+		{
+			name:          "v1",
+			version:       ProtocolV1,
+			expectVersion: "",
+		},
+		{
+			name:          "v2",
+			version:       ProtocolV2,
+			expectVersion: ProtocolV2,
+		},
+		{
+			name:      "unsupported",
+			version:   "v100",
+			expectErr: true,
+		},
+	}
+	for _, c := range cases {
+		req := &BackendOperationRequest{
+			RequestForRetryHooks: RequestForRetryHooks{RecordID: "record", RetryID: "retry"},
+			BackendAddr:          "1.1.1.1:80",
+		}
+		converted, err := ConvertRequest(c.version, EnsureBackend, req)
+		if c.expectErr {
+			if err == nil {
+				t.Errorf("case %s, expect error", c.name)
+			}
+			if IsProtocolVersionSupported(c.version) {
+				t.Errorf("case %s, expect unsupported version", c.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("case %s, expect no error, get %v", c.name, err)
+			continue
+		}
+		if req.ProtocolVersion != "" {
+			t.Errorf("case %s, expect request of caller not modified, get version %q", c.name, req.ProtocolVersion)
+		}
+		raw, _ := json.Marshal(converted)
+		decoded := &BackendOperationRequest{}
+		json.Unmarshal(raw, decoded)
+		if decoded.ProtocolVersion != c.expectVersion {
+			t.Errorf("case %s, expect version %q, get %s", c.name, c.expectVersion, raw)
+		}
+		if c.expectVersion == "" && strings.Contains(string(raw), "protocolVersion") {
+			t.Errorf("case %s, expect no protocolVersion in request, get %s", c.name, raw)
+		}
+		if decoded.RecordID != req.RecordID || decoded.RetryID != req.RetryID || decoded.BackendAddr != req.BackendAddr {
+			t.Errorf("case %s, expect %#v, get %s", c.name, req, raw)
+		}
+	}
+}
+
+func TestConvertRequestV1(t *testing.T) {
+	requests := []interface{}{
+		&ValidateLoadBalancerRequest{LBSpec: map[string]string{"k": "v"}, Operation: OperationUpdate},
+		&CreateLoadBalancerRequest{RequestForRetryHooks: RequestForRetryHooks{RecordID: "record"}},
+		&EnsureLoadBalancerRequest{LBInfo: map[string]string{"k": "v"}},
+		&DeleteLoadBalancerRequest{LBInfo: map[string]string{"k": "v"}},
+		&ValidateBackendRequest{BackendType: "Pod", OldParameters: map[string]string{"k": "v"}},
+		&GenerateBackendAddrRequest{LBAttributes: map[string]string{"k": "v"}},
+		&BackendOperationRequest{InjectedInfo: map[string]string{"k": "v"}},
+	}
+	for _, req := range requests {
+		converted, err := ConvertRequest(ProtocolV1, "", req)
+		if err != nil {
+			t.Fatalf("convert %T, expect no error, get %v", req, err)
+		}
+		expect, _ := json.Marshal(req)
+		get, _ := json.Marshal(converted)
+		if string(expect) != string(get) {
+			t.Errorf("convert %T, expect %s, get %s", req, expect, get)
+		}
+	}
+	if _, err := ConvertRequest(ProtocolV1, "", &struct{}{}); err == nil {
+		t.Errorf("expect error for unknown request type")
+	}
+}
+
+func TestConvertGenerateBackendAddrRequestV1(t *testing.T) {
+	req := &GenerateBackendAddrRequest{
+		PodBackend: &PodBackendInGenerateAddrRequest{
+			Port: v1beta1.PortSelector{PortNumber: 80, PortName: "http", Protocol: "TCP"},
+		},
+		ServiceBackend: &ServiceBackendInGenerateAddrRequest{
+			Port: v1beta1.PortSelector{PortNumber: 0},
+		},
+	}
+	converted, err := ConvertRequest(ProtocolV1, GenerateBackendAddr, req)
+	if err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	raw, _ := json.Marshal(converted)
+	get := struct {
+		PodBackend struct {
+			Port json.RawMessage `json:"port"`
+		} `json:"podBackend"`
+		ServiceBackend struct {
+			Port json.RawMessage `json:"port"`
+		} `json:"serviceBackend"`
+	}{}
+	if err := json.Unmarshal(raw, &get); err != nil {
+		t.Fatalf("expect no error, get %v", err)
+	}
+	if expect := `{"portNumber":80,"protocol":"TCP"}`; string(get.PodBackend.Port) != expect {
+		t.Errorf("expect pod port %s, get %s", expect, get.PodBackend.Port)
+	}
+	if expect := `{"portNumber":0}`; string(get.ServiceBackend.Port) != expect {
+		t.Errorf("expect service port %s, get %s", expect, get.ServiceBackend.Port)
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	for _, version := range []string{"", ProtocolV1, ProtocolV2} {
+		rsp := &BackendOperationResponse{}
+		raw := `{"status":"Running","msg":"msg","minRetryDelayInSeconds":10,"injectedInfo":{"k":"v"}}`
+		if err := DecodeResponse(version, EnsureBackend, []byte(raw), rsp); err != nil {
+			t.Fatalf("version %q, expect no error, get %v", version, err)
+		}
+		if rsp.Status != StatusRunning || rsp.Msg != "msg" || rsp.MinRetryDelayInSeconds != 10 || rsp.InjectedInfo["k"] != "v" {
+			t.Errorf("version %q, get %#v", version, rsp)
+		}
+
+		validate := &ValidateBackendResponse{}
+		if err := DecodeResponse(version, ValidateBackend, []byte(`{"succ":true,"msg":"ok"}`), validate); err != nil {
+			t.Fatalf("version %q, expect no error, get %v", version, err)
+		}
+		if !validate.Succ || validate.Msg != "ok" {
+			t.Errorf("version %q, get %#v", version, validate)
+		}
+	}
+	if err := DecodeResponse("v100", EnsureBackend, []byte(`{}`), &BackendOperationResponse{}); err == nil {
+		t.Errorf("expect error for unsupported protocol version")
+	}
+}
@@ -0,0 +1,170 @@
+/*
+ * Tencent is pleased to support the open source community by making TKEStack available.
+ *
+ * Copyright (C) 2012-2019 Tencent. All Rights Reserved.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License"); you may not use
+ * this file except in compliance with the License. You may obtain a copy of the
+ * License at
+ *
+ * https://opensource.org/licenses/Apache-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS, WITHOUT
+ * WARRANTIES OF ANY KIND, either express or implied.  See the License for the
+ * specific language governing permissions and limitations under the License.
+ */
+
+// Package v1 contains the wire format of webhook protocol v1.
+//
+// Types in this package are frozen, they must not be changed once released,
+// requests and responses of later protocol versions are defined in package webhooks
+// and converted from/to types in this package by webhooks.ConvertRequest and webhooks.DecodeResponse.
+package v1
+
+import (
+	apicore "k8s.io/api/core/v1"
+)
+
+// RequestForRetryHooks is the common request for webhooks that can be retried, including:
+//
+// createLoadBalancer, ensureLoadBalancer, deleteLoadBalancer, generateBackendAddr, ensureBackend, deregisterBackend, drainBackend
+type RequestForRetryHooks struct {
+	RecordID string `json:"recordID"`
+	RetryID  string `json:"retryID"`
+}
+
+// ResponseForFailRetryHooks is the common response for webhooks that can be retried, including:
+//
+// createLoadBalancer, ensureLoadBalancer, deleteLoadBalancer, generateBackendAddr, ensureBackend, deregisterBackend, drainBackend
+type ResponseForFailRetryHooks struct {
+	Status                 string `json:"status"`
+	Msg                    string `json:"msg"`
+	MinRetryDelayInSeconds int32  `json:"minRetryDelayInSeconds"`
+}
+
+// ResponseForNoRetryHooks is the common response for webhooks that can NOT be retried, including:
+//
+// validateLoadBalancer, validateBackend
+type ResponseForNoRetryHooks struct {
+	Succ bool   `json:"succ"`
+	Msg  string `json:"msg"`
+}
+
+// OperationType is used to distinguish why a webhook is called
+type OperationType string
+
+// ValidateLoadBalancerRequest is the request for webhook validateLoadBalancer
+type ValidateLoadBalancerRequest struct {
+	LBSpec        map[string]string `json:"lbSpec"`
+	Operation     OperationType     `json:"operation"`
+	Attributes    map[string]string `json:"attributes"`
+	OldAttributes map[string]string `json:"oldAttributes,omitempty"`
+}
+
+// ValidateLoadBalancerResponse is the response for webhook validateLoadBalancer
+type ValidateLoadBalancerResponse struct {
+	ResponseForNoRetryHooks
+}
+
+// CreateLoadBalancerRequest is the request for webhook createLoadBalancer
+type CreateLoadBalancerRequest struct {
+	RequestForRetryHooks
+	LBSpec     map[string]string `json:"lbSpec"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// CreateLoadBalancerResponse is the response for webhook createLoadBalancer
+type CreateLoadBalancerResponse struct {
+	ResponseForFailRetryHooks
+	LBInfo map[string]string `json:"lbInfo"`
+}
+
+// EnsureLoadBalancerRequest is the request for webhook ensureLoadBalancer
+type EnsureLoadBalancerRequest struct {
+	RequestForRetryHooks
+	LBInfo     map[string]string `json:"lbInfo"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// EnsureLoadBalancerResponse is the response for webhook ensureLoadBalancer
+type EnsureLoadBalancerResponse struct {
+	ResponseForFailRetryHooks
+}
+
+// DeleteLoadBalancerRequest is the request for webhook deleteLoadBalancer
+type DeleteLoadBalancerRequest struct {
+	RequestForRetryHooks
+	LBInfo     map[string]string `json:"lbInfo"`
+	Attributes map[string]string `json:"attributes"`
+}
+
+// DeleteLoadBalancerResponse is the response for webhook deleteLoadBalancer
+type DeleteLoadBalancerResponse struct {
+	ResponseForFailRetryHooks
+}
+
+// ValidateBackendRequest is the request for webhook validateBackend
+type ValidateBackendRequest struct {
+	BackendType   string            `json:"backendType"`
+	LBInfo        map[string]string `json:"lbInfo"`
+	Operation     OperationType     `json:"operation"`
+	Parameters    map[string]string `json:"parameters"`
+	OldParameters map[string]string `json:"OldParameters,omitempty"`
+}
+
+// ValidateBackendResponse is the response for webhook validateBackend
+type ValidateBackendResponse struct {
+	ResponseForNoRetryHooks
+}
+
+// GenerateBackendAddrRequest is the request for webhook generateBackendAddr
+type GenerateBackendAddrRequest struct {
+	RequestForRetryHooks
+	LBInfo         map[string]string                    `json:"lbInfo"`
+	LBAttributes   map[string]string                    `json:"lbAttributes"`
+	Parameters     map[string]string                    `json:"parameters"`
+	PodBackend     *PodBackendInGenerateAddrRequest     `json:"podBackend"`
+	ServiceBackend *ServiceBackendInGenerateAddrRequest `json:"serviceBackend"`
+}
+
+// PodBackendInGenerateAddrRequest is part of GenerateBackendAddrRequest
+type PodBackendInGenerateAddrRequest struct {
+	Pod  apicore.Pod  `json:"pod"`
+	Port PortSelector `json:"port"`
+}
+
+// ServiceBackendInGenerateAddrRequest is part of GenerateBackendAddrRequest
+type ServiceBackendInGenerateAddrRequest struct {
+	Service       apicore.Service       `json:"service"`
+	Port          PortSelector          `json:"port"`
+	NodeName      string                `json:"nodeName"`
+	NodeAddresses []apicore.NodeAddress `json:"nodeAddresses"`
+}
+
+// PortSelector is the port of PodBackendInGenerateAddrRequest and ServiceBackendInGenerateAddrRequest
+type PortSelector struct {
+	PortNumber int32  `json:"portNumber"`
+	Protocol   string `json:"protocol,omitempty"`
+}
+
+// GenerateBackendAddrResponse is the response for webhook generateBackendAddr
+type GenerateBackendAddrResponse struct {
+	ResponseForFailRetryHooks
+	BackendAddr string `json:"backendAddr"`
+}
+
+// BackendOperationRequest is the request for webhook ensureBackend, deregisterBackend and drainBackend
+type BackendOperationRequest struct {
+	RequestForRetryHooks
+	LBInfo       map[string]string `json:"lbInfo"`
+	BackendAddr  string            `json:"backendAddr"`
+	Parameters   map[string]string `json:"parameters"`
+	InjectedInfo map[string]string `json:"injectedInfo"`
+}
+
+// BackendOperationResponse is the response for webhook ensureBackend, deregisterBackend and drainBackend
+type BackendOperationResponse struct {
+	ResponseForFailRetryHooks
+	InjectedInfo map[string]string `json:"injectedInfo"`
+}
@@ -48,6 +48,9 @@ const (
 
 	// DeregBackend is the name and URL path of webhook deregisterBackend
 	DeregBackend = "deregisterBackend"
+
+	// DrainBackend is the name and URL path of webhook drainBackend
+	DrainBackend = "drainBackend"
 )
 
 // KnownWebhooks is a set contains all supported webhooks
@@ -60,11 +63,50 @@ var KnownWebhooks = sets.NewString(
 	GenerateBackendAddr,
 	EnsureBackend,
 	DeregBackend,
+	DrainBackend,
+)
+
+// OptionalWebhooks is a set contains webhooks that can be marked optional in LoadBalancerDriverSpec.
+// createLoadBalancer and deleteLoadBalancer are not optional, otherwise a misrouted call would be treated as
+// success and the load balancer in cloud would be orphaned
+var OptionalWebhooks = sets.NewString(
+	ValidateLoadBalancer,
+	EnsureLoadBalancer,
+	ValidateBackend,
+)
+
+// OptInWebhooks is a set contains webhooks that are called only if they are configured in LoadBalancerDriverSpec,
+// they are neither required nor added by default
+var OptInWebhooks = sets.NewString(
+	DrainBackend,
+)
+
+// AdmissionWebhooks is a set contains webhooks called by the admission webhook server while a request
+// from the user is waiting, they are not limited by maxConcurrentCalls and rateLimit, since a throttled call
+// can not be retried later and the request would be rejected
+var AdmissionWebhooks = sets.NewString(
+	ValidateLoadBalancer,
+	ValidateBackend,
 )
 
+// ExecExitCodeNotImplemented is the exit code of Exec drivers for webhooks they do not implement,
+// it is equivalent to http status 501 of Webhook drivers
+const ExecExitCodeNotImplemented = 3
+
+// RequestMeta is the common request for all webhooks
+type RequestMeta struct {
+	// ProtocolVersion is the protocol version used by the driver, it is omitted in protocol v1
+	ProtocolVersion string `json:"protocolVersion,omitempty"`
+}
+
+// SetProtocolVersion sets the protocol version of the request
+func (m *RequestMeta) SetProtocolVersion(version string) {
+	m.ProtocolVersion = version
+}
+
 // RequestForRetryHooks is the common request for webhooks that can be retried, including:
 //
-// createLoadBalancer, ensureLoadBalancer, deleteLoadBalancer, generateBackendAddr, ensureBackend, deregisterBackend
+// createLoadBalancer, ensureLoadBalancer, deleteLoadBalancer, generateBackendAddr, ensureBackend, deregisterBackend, drainBackend
 type RequestForRetryHooks struct {
 	RecordID string `json:"recordID"`
 	RetryID  string `json:"retryID"`
@@ -72,13 +114,18 @@ type RequestForRetryHooks struct {
 
 // ResponseForFailRetryHooks is the common response for webhooks that can be retried, including:
 //
-// createLoadBalancer, ensureLoadBalancer, deleteLoadBalancer, generateBackendAddr, ensureBackend, deregisterBackend
+// createLoadBalancer, ensureLoadBalancer, deleteLoadBalancer, generateBackendAddr, ensureBackend, deregisterBackend, drainBackend
 type ResponseForFailRetryHooks struct {
 	Status                 string `json:"status"`
 	Msg                    string `json:"msg"`
 	MinRetryDelayInSeconds int32  `json:"minRetryDelayInSeconds"`
 }
 
+// GetStatus returns the status in response
+func (r *ResponseForFailRetryHooks) GetStatus() string {
+	return r.Status
+}
+
 // ResponseForNoRetryHooks is the common response for webhooks that can NOT be retried, including:
 //
 // validateLoadBalancer, validateBackend
@@ -87,6 +134,19 @@ type ResponseForNoRetryHooks struct {
 	Msg  string `json:"msg"`
 }
 
+// GetStatus returns StatusSucc if Succ is true, otherwise StatusFail
+func (r *ResponseForNoRetryHooks) GetStatus() string {
+	if r.Succ {
+		return StatusSucc
+	}
+	return StatusFail
+}
+
+// StatusGetter is implemented by all webhook responses
+type StatusGetter interface {
+	GetStatus() string
+}
+
 const (
 	// StatusSucc indicates webhook succeeded
 	StatusSucc = "Succ"
@@ -111,6 +171,7 @@ const (
 
 // ValidateLoadBalancerRequest is the request for webhook validateLoadBalancer
 type ValidateLoadBalancerRequest struct {
+	RequestMeta
 	LBSpec        map[string]string `json:"lbSpec"`
 	Operation     OperationType     `json:"operation"`
 	Attributes    map[string]string `json:"attributes"`
@@ -124,6 +185,7 @@ type ValidateLoadBalancerResponse struct {
 
 // CreateLoadBalancerRequest is the request for webhook createLoadBalancer
 type CreateLoadBalancerRequest struct {
+	RequestMeta
 	RequestForRetryHooks
 	LBSpec     map[string]string `json:"lbSpec"`
 	Attributes map[string]string `json:"attributes"`
@@ -137,6 +199,7 @@ type CreateLoadBalancerResponse struct {
 
 // EnsureLoadBalancerRequest is the request for webhook ensureLoadBalancer
 type EnsureLoadBalancerRequest struct {
+	RequestMeta
 	RequestForRetryHooks
 	LBInfo     map[string]string `json:"lbInfo"`
 	Attributes map[string]string `json:"attributes"`
@@ -149,6 +212,7 @@ type EnsureLoadBalancerResponse struct {
 
 // DeleteLoadBalancerRequest is the request for webhook deleteLoadBalancer
 type DeleteLoadBalancerRequest struct {
+	RequestMeta
 	RequestForRetryHooks
 	LBInfo     map[string]string `json:"lbInfo"`
 	Attributes map[string]string `json:"attributes"`
@@ -161,6 +225,7 @@ type DeleteLoadBalancerResponse struct {
 
 // ValidateBackendRequest is the request for webhook validateBackend
 type ValidateBackendRequest struct {
+	RequestMeta
 	BackendType   string            `json:"backendType"`
 	LBInfo        map[string]string `json:"lbInfo"`
 	Operation     OperationType     `json:"operation"`
@@ -175,6 +240,7 @@ type ValidateBackendResponse struct {
 
 // GenerateBackendAddrRequest is the request for webhook generateBackendAddr
 type GenerateBackendAddrRequest struct {
+	RequestMeta
 	RequestForRetryHooks
 	LBInfo         map[string]string                    `json:"lbInfo"`
 	LBAttributes   map[string]string                    `json:"lbAttributes"`
@@ -203,8 +269,9 @@ type GenerateBackendAddrResponse struct {
 	BackendAddr string `json:"backendAddr"`
 }
 
-// BackendOperationRequest is the request for webhook ensureBackend and deregisterBackend
+// BackendOperationRequest is the request for webhook ensureBackend, deregisterBackend and drainBackend
 type BackendOperationRequest struct {
+	RequestMeta
 	RequestForRetryHooks
 	LBInfo       map[string]string `json:"lbInfo"`
 	BackendAddr  string            `json:"backendAddr"`
@@ -212,7 +279,7 @@ type BackendOperationRequest struct {
 	InjectedInfo map[string]string `json:"injectedInfo"`
 }
 
-// BackendOperationResponse is the response for webhook ensureBackend and deregisterBackend
+// BackendOperationResponse is the response for webhook ensureBackend, deregisterBackend and drainBackend
 type BackendOperationResponse struct {
 	ResponseForFailRetryHooks
 	InjectedInfo map[string]string `json:"injectedInfo"`